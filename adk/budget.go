@@ -0,0 +1,283 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// RunBudget limits the resources consumed by a run of the Runner, set by RunnerConfig.Budget.
+// The usage is tracked across all the agents of the run, including the agents run in parallel and by agent tools,
+// from the token usage in the ResponseMeta of the messages generated by ChatModelAgents and the tool calls they make.
+// The budget is checked before each chat model call and each batch of tool calls,
+// so a call in flight when the budget is used up completes, and the next one is refused.
+// A limit <= 0 means no limit.
+type RunBudget struct {
+	MaxPromptTokens     int
+	MaxCompletionTokens int
+	MaxTotalTokens      int
+	MaxToolCalls        int
+	// MaxDuration is the limit of the wall-clock time of the run, excluding the time between an interrupt and the resume.
+	MaxDuration time.Duration
+
+	// InterruptOnExceeded interrupts the run when the budget is exceeded, instead of ending it with a BudgetExceededError,
+	// so that a human can extend the budget and resume the run, by passing the new budget as the resume data of the interrupt, e.g.
+	//
+	//	runner.ResumeWithParams(ctx, checkPointID, &adk.ResumeParams{
+	//		Targets: map[string]any{interruptID: &adk.RunBudget{MaxTotalTokens: 200000, InterruptOnExceeded: true}},
+	//	})
+	//
+	// The info of the interrupt is the *BudgetExceededError. Requires RunnerConfig.CheckPointStore to resume.
+	InterruptOnExceeded bool
+}
+
+// BudgetLimit names a limit of RunBudget.
+type BudgetLimit string
+
+const (
+	BudgetLimitPromptTokens     BudgetLimit = "prompt_tokens"
+	BudgetLimitCompletionTokens BudgetLimit = "completion_tokens"
+	BudgetLimitTotalTokens      BudgetLimit = "total_tokens"
+	BudgetLimitToolCalls        BudgetLimit = "tool_calls"
+	BudgetLimitDuration         BudgetLimit = "duration"
+)
+
+// AgentUsage is the tokens and tool calls consumed.
+type AgentUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	ToolCalls        int
+}
+
+func (u *AgentUsage) add(usage *schema.TokenUsage, toolCalls int) {
+	if usage != nil {
+		u.PromptTokens += usage.PromptTokens
+		u.CompletionTokens += usage.CompletionTokens
+		u.TotalTokens += usage.TotalTokens
+	}
+	u.ToolCalls += toolCalls
+}
+
+// RunUsage is the usage of a run of the Runner.
+type RunUsage struct {
+	Total AgentUsage
+	// ByAgent is the usage by the names of the agents consuming it.
+	ByAgent map[string]*AgentUsage
+	// Elapsed is the wall-clock time of the run, excluding the time between an interrupt and the resume.
+	Elapsed time.Duration
+}
+
+// BudgetExceededError ends the run when the RunBudget is exceeded, sent as the Err of the event of the agent refused by the budget,
+// which may be wrapped by the agents and tools running the agent, use errors.As to extract it, e.g.
+//
+//	var budgetErr *adk.BudgetExceededError
+//	if errors.As(event.Err, &budgetErr) {
+//		fmt.Printf("budget of %s exceeded, usage: %+v\n", budgetErr.Limit, budgetErr.Usage.Total)
+//	}
+type BudgetExceededError struct {
+	Limit BudgetLimit
+	// AgentName is the name of the agent refused by the budget.
+	AgentName string
+	Usage     *RunUsage
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("run budget exceeded: limit of %s, agent: %s", e.Limit, e.AgentName)
+}
+
+// WithRunUsage collects the usage of the run into usage when the run ends, i.e. when the iterator returned by the Runner is exhausted.
+// The usage is collected even if RunnerConfig.Budget isn't set.
+func WithRunUsage(usage *RunUsage) AgentRunOption {
+	return WrapImplSpecificOptFn(func(o *options) {
+		o.runUsage = usage
+	})
+}
+
+type budgetTracker struct {
+	mu      sync.Mutex
+	budget  *RunBudget
+	usage   RunUsage
+	started time.Time
+}
+
+type budgetTrackerKey struct{}
+
+func getBudgetTracker(ctx context.Context) *budgetTracker {
+	t, _ := ctx.Value(budgetTrackerKey{}).(*budgetTracker)
+	return t
+}
+
+// withBudgetTracker starts tracking the usage of the run if a budget is set or the usage is collected,
+// restored from the budget and usage saved in the checkpoint when resuming.
+// The runs of the nested Runners, e.g. those of agent tools, share the tracker of the root run.
+func withBudgetTracker(ctx context.Context, budget *RunBudget, o *options, saved *serialization) context.Context {
+	if getBudgetTracker(ctx) != nil {
+		return ctx
+	}
+	if saved != nil && saved.Budget != nil {
+		budget = saved.Budget
+	}
+	if budget == nil && o.runUsage == nil {
+		return ctx
+	}
+
+	t := &budgetTracker{budget: budget, started: time.Now()}
+	if saved != nil && saved.Usage != nil {
+		t.usage = *copyRunUsage(saved.Usage)
+	}
+	if t.usage.ByAgent == nil {
+		t.usage.ByAgent = make(map[string]*AgentUsage)
+	}
+	return context.WithValue(ctx, budgetTrackerKey{}, t)
+}
+
+func copyRunUsage(u *RunUsage) *RunUsage {
+	ret := &RunUsage{Total: u.Total, Elapsed: u.Elapsed, ByAgent: make(map[string]*AgentUsage, len(u.ByAgent))}
+	for name, au := range u.ByAgent {
+		cp := *au
+		ret.ByAgent[name] = &cp
+	}
+	return ret
+}
+
+func (t *budgetTracker) snapshot() (*RunBudget, *RunUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := copyRunUsage(&t.usage)
+	u.Elapsed += time.Since(t.started)
+	return t.budget, u
+}
+
+func (t *budgetTracker) record(agentName string, usage *schema.TokenUsage, toolCalls int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.Total.add(usage, toolCalls)
+	au, ok := t.usage.ByAgent[agentName]
+	if !ok {
+		au = &AgentUsage{}
+		t.usage.ByAgent[agentName] = au
+	}
+	au.add(usage, toolCalls)
+}
+
+// exceeded returns the limit exceeded before a chat model call, or before toolCalls tool calls if toolCalls > 0.
+func (t *budgetTracker) exceeded(toolCalls int) BudgetLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, u := t.budget, &t.usage.Total
+	if b == nil {
+		return ""
+	}
+	if b.MaxDuration > 0 && t.usage.Elapsed+time.Since(t.started) >= b.MaxDuration {
+		return BudgetLimitDuration
+	}
+	if toolCalls > 0 {
+		if b.MaxToolCalls > 0 && u.ToolCalls+toolCalls > b.MaxToolCalls {
+			return BudgetLimitToolCalls
+		}
+		return ""
+	}
+	switch {
+	case b.MaxPromptTokens > 0 && u.PromptTokens >= b.MaxPromptTokens:
+		return BudgetLimitPromptTokens
+	case b.MaxCompletionTokens > 0 && u.CompletionTokens >= b.MaxCompletionTokens:
+		return BudgetLimitCompletionTokens
+	case b.MaxTotalTokens > 0 && u.TotalTokens >= b.MaxTotalTokens:
+		return BudgetLimitTotalTokens
+	}
+	return ""
+}
+
+// check extends the budget by the resume data targeting the interrupt of the budget if any,
+// then returns the error ending the run, or the interrupt carrying state, if the budget is exceeded.
+// The state of the interrupt is the error itself if state is nil.
+func (t *budgetTracker) check(ctx context.Context, agentName string, toolCalls int, state any) error {
+	if isResumeTarget, hasData, budget := compose.GetResumeContext[*RunBudget](ctx); isResumeTarget && hasData && budget != nil {
+		t.mu.Lock()
+		t.budget = budget
+		t.mu.Unlock()
+	}
+
+	limit := t.exceeded(toolCalls)
+	if limit == "" {
+		return nil
+	}
+	budget, usage := t.snapshot()
+	err := &BudgetExceededError{Limit: limit, AgentName: agentName, Usage: usage}
+	if budget.InterruptOnExceeded {
+		if state == nil {
+			state = err
+		}
+		return compose.StatefulInterrupt(ctx, err, state)
+	}
+	return err
+}
+
+// checkModelBudget is called by the state pre handler of the chat model of a ChatModelAgent,
+// returning the input of the chat model, which is restored from the interrupt state when rerunning after the budget interrupts.
+func checkModelBudget(ctx context.Context, agentName string, input []Message) ([]Message, error) {
+	t := getBudgetTracker(ctx)
+	if t == nil {
+		return input, nil
+	}
+	if wasInterrupted, hasState, state := compose.GetInterruptState[[]Message](ctx); wasInterrupted && hasState {
+		input = state
+	}
+	if err := t.check(ctx, agentName, 0, input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// checkToolBudget is called by the state pre handler of the tools node of a ChatModelAgent before the tool calls in msg run,
+// counting them if they're allowed.
+func checkToolBudget(ctx context.Context, agentName string, msg Message) error {
+	t := getBudgetTracker(ctx)
+	if t == nil || len(msg.ToolCalls) == 0 {
+		return nil
+	}
+	// rerunning after the tools interrupt, the tool calls have been counted
+	if wasInterrupted, byBudget, _ := compose.GetInterruptState[*BudgetExceededError](ctx); wasInterrupted && !byBudget {
+		return nil
+	}
+	if err := t.check(ctx, agentName, len(msg.ToolCalls), nil); err != nil {
+		return err
+	}
+	t.record(agentName, nil, len(msg.ToolCalls))
+	return nil
+}
+
+// recordModelUsage is called by the state post handler of the chat model of a ChatModelAgent.
+func recordModelUsage(ctx context.Context, agentName string, msg Message) {
+	t := getBudgetTracker(ctx)
+	if t == nil || msg == nil || msg.ResponseMeta == nil {
+		return
+	}
+	t.record(agentName, msg.ResponseMeta.Usage, 0)
+}
+
+func init() {
+	schema.RegisterName[*BudgetExceededError]("_eino_adk_budget_exceeded_error")
+	schema.RegisterName[*ChatModelAgentState]("_eino_adk_chat_model_agent_state")
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestRunBudget(t *testing.T) {
+	ctx := context.Background()
+
+	withUsage := func(msg *schema.Message, tokens int) *schema.Message {
+		msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{
+			PromptTokens: tokens - 1, CompletionTokens: 1, TotalTokens: tokens}}
+		return msg
+	}
+	newAgents := func() Agent {
+		inner, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+			Name:        "inner",
+			Description: "inner agent",
+			Model:       &myModel{messages: []*schema.Message{withUsage(schema.AssistantMessage("inner done", nil), 5)}},
+		})
+		require.NoError(t, err)
+		outer, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+			Name:        "outer",
+			Description: "outer agent",
+			Model: &myModel{messages: []*schema.Message{
+				withUsage(schema.AssistantMessage("", []schema.ToolCall{{ID: "1",
+					Function: schema.FunctionCall{Name: "inner", Arguments: `{"request":"hi"}`}}}), 10),
+				withUsage(schema.AssistantMessage("outer done", nil), 10),
+			}},
+			ToolsConfig: ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{
+				Tools: []tool.BaseTool{NewAgentTool(ctx, inner)},
+			}},
+		})
+		require.NoError(t, err)
+		return outer
+	}
+	drain := func(iter *AsyncIterator[*AgentEvent]) (last *AgentEvent) {
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return last
+			}
+			last = event
+		}
+	}
+
+	t.Run("usage across agent tools", func(t *testing.T) {
+		runner := NewRunner(ctx, RunnerConfig{Agent: newAgents(), Budget: &RunBudget{MaxTotalTokens: 100}})
+		usage := &RunUsage{}
+		last := drain(runner.Query(ctx, "hello", WithRunUsage(usage)))
+		require.NoError(t, last.Err)
+		assert.Equal(t, "outer done", last.Output.MessageOutput.Message.Content)
+		assert.Equal(t, AgentUsage{PromptTokens: 22, CompletionTokens: 3, TotalTokens: 25, ToolCalls: 1}, usage.Total)
+		assert.Equal(t, map[string]*AgentUsage{
+			"outer": {PromptTokens: 18, CompletionTokens: 2, TotalTokens: 20, ToolCalls: 1},
+			"inner": {PromptTokens: 4, CompletionTokens: 1, TotalTokens: 5},
+		}, usage.ByAgent)
+		assert.Greater(t, usage.Elapsed, time.Duration(0))
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		runner := NewRunner(ctx, RunnerConfig{Agent: newAgents(), Budget: &RunBudget{MaxTotalTokens: 10}})
+		last := drain(runner.Query(ctx, "hello"))
+		var budgetErr *BudgetExceededError
+		require.True(t, errors.As(last.Err, &budgetErr))
+		assert.Equal(t, BudgetLimitTotalTokens, budgetErr.Limit)
+		assert.Equal(t, "inner", budgetErr.AgentName)
+		assert.Equal(t, 10, budgetErr.Usage.Total.TotalTokens)
+
+		runner = NewRunner(ctx, RunnerConfig{Agent: newAgents(), Budget: &RunBudget{MaxDuration: time.Nanosecond}})
+		last = drain(runner.Query(ctx, "hello"))
+		require.True(t, errors.As(last.Err, &budgetErr))
+		assert.Equal(t, BudgetLimitDuration, budgetErr.Limit)
+		assert.Equal(t, "outer", budgetErr.AgentName)
+	})
+
+	t.Run("interrupt and extend", func(t *testing.T) {
+		runner := NewRunner(ctx, RunnerConfig{
+			Agent:           newAgents(),
+			Budget:          &RunBudget{MaxTotalTokens: 15, InterruptOnExceeded: true},
+			CheckPointStore: newMyStore(),
+		})
+		last := drain(runner.Query(ctx, "hello", WithCheckPointID("budget")))
+		require.NoError(t, last.Err)
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		require.Len(t, last.Action.Interrupted.InterruptContexts, 1)
+		intCtx := last.Action.Interrupted.InterruptContexts[0]
+		budgetErr, ok := intCtx.Info.(*BudgetExceededError)
+		require.True(t, ok)
+		assert.Equal(t, "outer", budgetErr.AgentName)
+		assert.Equal(t, 15, budgetErr.Usage.Total.TotalTokens)
+
+		// resuming without extending the budget interrupts again
+		iter, err := runner.Resume(ctx, "budget")
+		require.NoError(t, err)
+		last = drain(iter)
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		intCtx = last.Action.Interrupted.InterruptContexts[0]
+
+		usage := &RunUsage{}
+		iter, err = runner.ResumeWithParams(ctx, "budget", &ResumeParams{
+			Targets: map[string]any{intCtx.ID: &RunBudget{MaxTotalTokens: 100}},
+		}, WithRunUsage(usage))
+		require.NoError(t, err)
+		last = drain(iter)
+		require.NoError(t, last.Err)
+		assert.Equal(t, "outer done", last.Output.MessageOutput.Message.Content)
+		assert.Equal(t, 25, usage.Total.TotalTokens)
+	})
+}
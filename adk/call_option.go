@@ -20,7 +20,10 @@ type options struct {
 	sharedParentSession  bool
 	sessionValues        map[string]any
 	checkPointID         *string
+	checkPointHistory    bool
 	skipTransferMessages bool
+	runUsage             *RunUsage
+	conversationID       string
 }
 
 // AgentRunOption is the call option for adk Agent.
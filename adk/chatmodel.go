@@ -167,6 +167,10 @@ type AgentMiddleware struct {
 	// WrapToolCall wraps tool calls with custom middleware logic.
 	// Each middleware contains Invokable and/or Streamable functions for tool calls.
 	WrapToolCall compose.ToolMiddleware
+
+	// WrapModel wraps the chat model of the agent, e.g. to check or rewrite its output before it's emitted as an event.
+	// The wrapper of the first middleware is the outermost one.
+	WrapModel func(model.ToolCallingChatModel) model.ToolCallingChatModel
 }
 
 type ChatModelAgentConfig struct {
@@ -268,6 +272,7 @@ func NewChatModelAgent(_ context.Context, config *ChatModelAgentConfig) (*ChatMo
 	sb := &strings.Builder{}
 	sb.WriteString(config.Instruction)
 	tc := config.ToolsConfig
+	chatModel := config.Model
 	for _, m := range config.Middlewares {
 		sb.WriteString("\n")
 		sb.WriteString(m.AdditionalInstruction)
@@ -283,12 +288,17 @@ func NewChatModelAgent(_ context.Context, config *ChatModelAgentConfig) (*ChatMo
 			afterChatModels = append(afterChatModels, m.AfterChatModel)
 		}
 	}
+	for i := len(config.Middlewares) - 1; i >= 0; i-- {
+		if config.Middlewares[i].WrapModel != nil {
+			chatModel = config.Middlewares[i].WrapModel(chatModel)
+		}
+	}
 
 	return &ChatModelAgent{
 		name:             config.Name,
 		description:      config.Description,
 		instruction:      sb.String(),
-		model:            config.Model,
+		model:            chatModel,
 		toolsConfig:      tc,
 		genModelInput:    genInput,
 		exit:             config.Exit,
@@ -766,6 +776,10 @@ func (a *ChatModelAgent) buildRunFunc(ctx context.Context) runFunc {
 					AppendChatModel(
 						chatModel,
 						compose.WithStatePreHandler(func(ctx context.Context, in []*schema.Message, state *ChatModelAgentState) ([]*schema.Message, error) {
+							in, err := checkModelBudget(ctx, a.name, in)
+							if err != nil {
+								return nil, err
+							}
 							state.Messages = in
 							for _, bc := range a.beforeChatModels {
 								err := bc(ctx, state)
@@ -776,6 +790,7 @@ func (a *ChatModelAgent) buildRunFunc(ctx context.Context) runFunc {
 							return state.Messages, nil
 						}),
 						compose.WithStatePostHandler(func(ctx context.Context, in *schema.Message, state *ChatModelAgentState) (*schema.Message, error) {
+							recordModelUsage(ctx, a.name, in)
 							state.Messages = append(state.Messages, in)
 							for _, ac := range a.afterChatModels {
 								err := ac(ctx, state)
@@ -22,8 +22,10 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/cloudwego/eino/internal/core"
+	iserialization "github.com/cloudwego/eino/internal/serialization"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -174,12 +176,34 @@ func FromInterruptContexts(contexts []*InterruptCtx) *InterruptSignal {
 }
 
 // WithCheckPointID sets the checkpoint ID used for interruption persistence.
+// When passed to Runner.Resume, it sets the checkpoint ID to write to instead of the one resumed from.
 func WithCheckPointID(id string) AgentRunOption {
 	return WrapImplSpecificOptFn(func(t *options) {
 		t.checkPointID = &id
 	})
 }
 
+// CheckPointVersion describes a historical version of a checkpoint, see WithCheckPointHistory.
+type CheckPointVersion = core.CheckPointVersion
+
+// WithCheckPointHistory makes the Runner write a new version of the checkpoint on every interrupt,
+// in addition to overwriting the checkpoint of the checkpoint ID.
+// The versions can be listed by Runner.GetCheckPointHistory, and resumed from by passing CheckPointVersion.CheckPointID to Runner.Resume.
+// Versions are read-only, so resuming from a version requires WithCheckPointID to write to another checkpoint ID, which forks a new branch.
+func WithCheckPointHistory() AgentRunOption {
+	return WrapImplSpecificOptFn(func(t *options) {
+		t.checkPointHistory = true
+	})
+}
+
+// GetCheckPointHistory returns the versions of the checkpoint written with WithCheckPointHistory, ordered by version.
+func (r *Runner) GetCheckPointHistory(ctx context.Context, checkPointID string) ([]*CheckPointVersion, error) {
+	if r.store == nil {
+		return nil, fmt.Errorf("failed to get checkpoint history: store is nil")
+	}
+	return core.GetCheckPointHistory(ctx, r.store, checkPointID)
+}
+
 func init() {
 	schema.RegisterName[*serialization]("_eino_adk_serialization")
 	schema.RegisterName[*WorkflowInterruptInfo]("_eino_adk_workflow_interrupt_info")
@@ -195,24 +219,31 @@ type serialization struct {
 	EnableStreaming     bool
 	InterruptID2Address map[string]Address
 	InterruptID2State   map[string]core.InterruptState
+	// InterruptID2Point and InterruptID2ResumeData are only used by GetPendingInterrupts and EditPendingInterrupts
+	InterruptID2Point      map[string]*core.InterruptPoint
+	InterruptID2ResumeData map[string]any
+	// Budget and Usage are the RunBudget and the RunUsage of the run when interrupted, if the usage is tracked
+	Budget *RunBudget
+	Usage  *RunUsage
+	// ConversationID and ConversationInputStart are the conversation of the run if its session is stored, see SessionStore
+	ConversationID         string
+	ConversationInputStart int
 }
 
-func (r *Runner) loadCheckPoint(ctx context.Context, checkpointID string) (
+func (r *Runner) loadCheckPoint(ctx context.Context, checkpointID string, o *options) (
 	context.Context, *runContext, *ResumeInfo, error) {
-	data, existed, err := r.store.Get(ctx, checkpointID)
+	s, err := r.getCheckPoint(ctx, checkpointID)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get checkpoint from store: %w", err)
-	}
-	if !existed {
-		return nil, nil, nil, fmt.Errorf("checkpoint[%s] not exist", checkpointID)
-	}
-
-	s := &serialization{}
-	err = gob.NewDecoder(bytes.NewReader(data)).Decode(s)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+		return nil, nil, nil, err
 	}
+	ctx = core.PopulateResumeData(ctx, s.InterruptID2ResumeData)
 	ctx = core.PopulateInterruptState(ctx, s.InterruptID2Address, s.InterruptID2State)
+	ctx = withBudgetTracker(ctx, r.budget, o, s)
+	if s.ConversationID != "" {
+		ctx = withConversation(ctx, &conversation{id: s.ConversationID, inputStart: s.ConversationInputStart})
+	} else {
+		ctx = withConversation(ctx, nil)
+	}
 
 	return ctx, s.RunCtx, &ResumeInfo{
 		EnableStreaming: s.EnableStreaming,
@@ -225,23 +256,59 @@ func (r *Runner) saveCheckPoint(
 	key string,
 	info *InterruptInfo,
 	is *core.InterruptSignal,
+	version *CheckPointVersion,
 ) error {
 	runCtx := getRunCtx(ctx)
 
 	id2Addr, id2State := core.SignalToPersistenceMaps(is)
 
-	buf := &bytes.Buffer{}
-	err := gob.NewEncoder(buf).Encode(&serialization{
+	s := &serialization{
 		RunCtx:              runCtx,
 		Info:                info,
 		InterruptID2Address: id2Addr,
 		InterruptID2State:   id2State,
+		InterruptID2Point:   core.SignalToInterruptPoints(is, canEncode),
 		EnableStreaming:     r.enableStreaming,
-	})
+	}
+	if t := getBudgetTracker(ctx); t != nil {
+		s.Budget, s.Usage = t.snapshot()
+	}
+	if conv := getConversation(ctx); conv != nil {
+		s.ConversationID, s.ConversationInputStart = conv.id, conv.inputStart
+	}
+
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(s)
 	if err != nil {
 		return fmt.Errorf("failed to encode checkpoint: %w", err)
 	}
-	return r.store.Set(ctx, key, buf.Bytes())
+	if err = r.store.Set(ctx, key, buf.Bytes()); err != nil {
+		return err
+	}
+	if version != nil {
+		v := *version
+		return core.AppendCheckPointVersion(ctx, r.store, key, buf.Bytes(), &v)
+	}
+	return nil
+}
+
+func decodeCheckPoint(data []byte) (*serialization, error) {
+	s := &serialization{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	// upgrade the values of old versions, see schema.RegisterMigration
+	err = iserialization.Migrate(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate checkpoint: %w", err)
+	}
+	return s, nil
+}
+
+// canEncode returns whether the value held by an interface can be encoded into the checkpoint.
+func canEncode(v any) bool {
+	return gob.NewEncoder(io.Discard).Encode(&struct{ V any }{V: v}) == nil
 }
 
 const bridgeCheckpointID = "adk_react_mock_key"
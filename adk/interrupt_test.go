@@ -1786,3 +1786,169 @@ func consumeUntilInterrupt(iter *AsyncIterator[*AgentEvent]) (normalEvents []*Ag
 	}
 	return
 }
+
+func TestCheckPointHistory(t *testing.T) {
+	interruptFn := func(ctx context.Context) *AsyncIterator[*AgentEvent] {
+		iter, generator := NewAsyncIteratorPair[*AgentEvent]()
+		generator.Send(Interrupt(ctx, "confirm"))
+		generator.Close()
+		return iter
+	}
+	agent := &myAgent{
+		runFn: func(ctx context.Context, input *AgentInput, options ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			return interruptFn(ctx)
+		},
+		resumeFn: func(ctx context.Context, info *ResumeInfo, opts ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			return interruptFn(ctx)
+		},
+	}
+	ctx := context.Background()
+	runner := NewRunner(ctx, RunnerConfig{Agent: agent, CheckPointStore: newMyStore()})
+	drain := func(iter *AsyncIterator[*AgentEvent]) {
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				break
+			}
+			assert.NoError(t, event.Err)
+		}
+	}
+
+	drain(runner.Query(ctx, "hello", WithCheckPointID("1"), WithCheckPointHistory()))
+	iter, err := runner.Resume(ctx, "1", WithCheckPointHistory())
+	assert.NoError(t, err)
+	drain(iter)
+
+	history, err := runner.GetCheckPointHistory(ctx, "1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, compose.CheckPointVersionID("1", 1), history[0].CheckPointID)
+	assert.True(t, history[1].Interrupted)
+
+	_, err = runner.Resume(ctx, history[0].CheckPointID)
+	assert.Error(t, err)
+
+	iter, err = runner.Resume(ctx, history[0].CheckPointID, WithCheckPointID("fork"), WithCheckPointHistory())
+	assert.NoError(t, err)
+	drain(iter)
+	history, err = runner.GetCheckPointHistory(ctx, "fork")
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, compose.CheckPointVersionID("1", 1), history[0].ForkedFrom)
+	}
+	history, err = runner.GetCheckPointHistory(ctx, "1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	// IDs of users looking like versions are plain checkpoint IDs
+	drain(runner.Query(ctx, "hello", WithCheckPointID("x@v1")))
+	_, err = runner.Resume(ctx, "x@v1")
+	assert.NoError(t, err)
+}
+
+type adkMigrationStateV1 struct {
+	Approved string
+}
+
+type adkMigrationStateV2 struct {
+	Approved bool
+}
+
+func init() {
+	schema.RegisterNameWithVersion[*adkMigrationStateV2]("_adk_test_migration_state", 2)
+	schema.RegisterMigration[*adkMigrationStateV1, *adkMigrationStateV2]("_adk_test_migration_state", 1,
+		func(s *adkMigrationStateV1) (*adkMigrationStateV2, error) {
+			return &adkMigrationStateV2{Approved: s.Approved == "yes"}, nil
+		})
+}
+
+func TestCheckPointMigration(t *testing.T) {
+	var resumedState any
+	agent := &myAgent{
+		runFn: func(ctx context.Context, input *AgentInput, options ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			iter, generator := NewAsyncIteratorPair[*AgentEvent]()
+			generator.Send(StatefulInterrupt(ctx, "confirm", &adkMigrationStateV1{Approved: "yes"}))
+			generator.Close()
+			return iter
+		},
+		resumeFn: func(ctx context.Context, info *ResumeInfo, opts ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			resumedState = info.InterruptState
+			iter, generator := NewAsyncIteratorPair[*AgentEvent]()
+			generator.Close()
+			return iter
+		},
+	}
+	ctx := context.Background()
+	runner := NewRunner(ctx, RunnerConfig{Agent: agent, CheckPointStore: newMyStore()})
+	iter := runner.Query(ctx, "hello", WithCheckPointID("1"))
+	for _, ok := iter.Next(); ok; _, ok = iter.Next() {
+	}
+
+	iter, err := runner.Resume(ctx, "1")
+	assert.NoError(t, err)
+	for _, ok := iter.Next(); ok; _, ok = iter.Next() {
+	}
+	assert.Equal(t, &adkMigrationStateV2{Approved: true}, resumedState)
+}
+
+func TestPendingInterrupts(t *testing.T) {
+	var resumeInfo *ResumeInfo
+	agent := &myAgent{
+		runFn: func(ctx context.Context, input *AgentInput, options ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			iter, generator := NewAsyncIteratorPair[*AgentEvent]()
+			generator.Send(StatefulInterrupt(ctx, "confirm", "draft"))
+			generator.Close()
+			return iter
+		},
+		resumeFn: func(ctx context.Context, info *ResumeInfo, opts ...AgentRunOption) *AsyncIterator[*AgentEvent] {
+			resumeInfo = info
+			iter, generator := NewAsyncIteratorPair[*AgentEvent]()
+			generator.Close()
+			return iter
+		},
+	}
+	ctx := context.Background()
+	runner := NewRunner(ctx, RunnerConfig{Agent: agent, CheckPointStore: newMyStore()})
+	var interrupted *InterruptInfo
+	iter := runner.Query(ctx, "hello", WithCheckPointID("1"))
+	for event, ok := iter.Next(); ok; event, ok = iter.Next() {
+		if event.Action != nil && event.Action.Interrupted != nil {
+			interrupted = event.Action.Interrupted
+		}
+	}
+	assert.NotNil(t, interrupted)
+
+	pending, err := runner.GetPendingInterrupts(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, interrupted.InterruptContexts, pending.InterruptContexts)
+	if !assert.Len(t, pending.InterruptContexts, 1) {
+		return
+	}
+	id := pending.InterruptContexts[0].ID
+	assert.Equal(t, "confirm", pending.InterruptContexts[0].Info)
+	assert.Equal(t, map[string]any{id: "draft"}, pending.States)
+
+	err = runner.EditPendingInterrupts(ctx, "1", &InterruptEdit{ResumeData: map[string]any{"unknown": "approved"}})
+	assert.Error(t, err)
+	err = runner.EditPendingInterrupts(ctx, "1", &InterruptEdit{
+		States:     map[string]any{id: "final"},
+		ResumeData: map[string]any{id: "approved"},
+	})
+	assert.NoError(t, err)
+	pending, err = runner.GetPendingInterrupts(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{id: "approved"}, pending.ResumeData)
+
+	iter, err = runner.Resume(ctx, "1")
+	assert.NoError(t, err)
+	for _, ok := iter.Next(); ok; _, ok = iter.Next() {
+	}
+	if assert.NotNil(t, resumeInfo) {
+		assert.Equal(t, "final", resumeInfo.InterruptState)
+		assert.True(t, resumeInfo.IsResumeTarget)
+		assert.Equal(t, "approved", resumeInfo.ResumeData)
+	}
+
+	_, err = runner.GetPendingInterrupts(ctx, "2")
+	assert.Error(t, err)
+}
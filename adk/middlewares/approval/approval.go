@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package approval provides a middleware asking a human to approve sensitive tool calls before they run.
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Config configures the approval middleware.
+type Config struct {
+	// ToolNames are the names of the tools whose calls need approval.
+	// optional, at least one of ToolNames and NeedApproval is required
+	ToolNames []string
+
+	// NeedApproval decides whether a tool call needs approval by the name and the arguments of the tool,
+	// in addition to the tools in ToolNames.
+	// optional, at least one of ToolNames and NeedApproval is required
+	NeedApproval func(ctx context.Context, toolName, argumentsInJSON string) bool
+
+	// RejectedResult generates the result of a rejected tool call, which is sent to the model in place of the tool's.
+	// optional, "tool call rejected by the user: {reason}" by default
+	RejectedResult func(ctx context.Context, info *Info, reason string) string
+}
+
+// Info is the info of the interrupt raised before a tool call that needs approval.
+type Info struct {
+	ToolName        string
+	CallID          string
+	ArgumentsInJSON string
+}
+
+func (i *Info) String() string {
+	return fmt.Sprintf("tool call '%s' (%s) with arguments %s needs approval", i.ToolName, i.CallID, i.ArgumentsInJSON)
+}
+
+// Decision is the resume data of the interrupt of a tool call, passed by ResumeParams.Targets keyed by the interrupt ID:
+//
+//	runner.ResumeWithParams(ctx, checkPointID, &adk.ResumeParams{
+//		Targets: map[string]any{interruptID: approval.Reject("not allowed to delete production data")},
+//	})
+//
+// Targeting the interrupt without data approves the tool call as is.
+// When resuming without targeting the interrupt, e.g. by Runner.Resume, the tool call is interrupted again.
+type Decision struct {
+	Approved bool
+	// Reason is the reason of the rejection, sent to the model as the tool result.
+	Reason string
+	// EditedArgumentsInJSON replaces the arguments of the approved tool call if not empty.
+	EditedArgumentsInJSON string
+}
+
+// Approve approves the tool call as is.
+func Approve() *Decision {
+	return &Decision{Approved: true}
+}
+
+// ApproveWithEdit approves the tool call with the arguments edited.
+func ApproveWithEdit(argumentsInJSON string) *Decision {
+	return &Decision{Approved: true, EditedArgumentsInJSON: argumentsInJSON}
+}
+
+// Reject rejects the tool call, the reason is sent to the model as the tool result.
+func Reject(reason string) *Decision {
+	return &Decision{Reason: reason}
+}
+
+func init() {
+	schema.RegisterName[*Info]("_eino_adk_approval_info")
+	schema.RegisterName[*Decision]("_eino_adk_approval_decision")
+	schema.RegisterName[*approvedState]("_eino_adk_approval_approved_state")
+}
+
+// New creates a middleware that interrupts before the tool calls needing approval by tool.StatefulInterrupt,
+// with the *Info of the tool call as both the info and the state of the interrupt.
+// On resume, the tool call runs, with its arguments edited if required, or is rejected, the reason of which is the tool result.
+// The tool itself can still interrupt after it's approved, and is resumed as usual with the approved, maybe edited, arguments.
+// The approved tool call runs at the address of the tool call appended by a tool segment of ID "approved".
+// Requires the Runner to have a CheckPointStore to resume.
+func New(_ context.Context, config *Config) (adk.AgentMiddleware, error) {
+	if config == nil || (len(config.ToolNames) == 0 && config.NeedApproval == nil) {
+		return adk.AgentMiddleware{}, errors.New("approval middleware requires ToolNames or NeedApproval")
+	}
+
+	g := &gate{
+		toolNames:      make(map[string]bool, len(config.ToolNames)),
+		needApproval:   config.NeedApproval,
+		rejectedResult: config.RejectedResult,
+	}
+	for _, name := range config.ToolNames {
+		g.toolNames[name] = true
+	}
+	if g.rejectedResult == nil {
+		g.rejectedResult = func(_ context.Context, _ *Info, reason string) string {
+			return "tool call rejected by the user: " + reason
+		}
+	}
+
+	return adk.AgentMiddleware{
+		WrapToolCall: compose.ToolMiddleware{
+			Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+				return gated(g, next, func(rejected string) *compose.ToolOutput {
+					return &compose.ToolOutput{Result: rejected}
+				})
+			},
+			Streamable: func(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+				return gated(g, next, func(rejected string) *compose.StreamToolOutput {
+					return &compose.StreamToolOutput{Result: schema.StreamReaderFromArray([]string{rejected})}
+				})
+			},
+			EnhancedInvokable: func(next compose.EnhancedInvokableToolEndpoint) compose.EnhancedInvokableToolEndpoint {
+				return gated(g, next, func(rejected string) *compose.EnhancedInvokableToolOutput {
+					return &compose.EnhancedInvokableToolOutput{Result: textResult(rejected)}
+				})
+			},
+			EnhancedStreamable: func(next compose.EnhancedStreamableToolEndpoint) compose.EnhancedStreamableToolEndpoint {
+				return gated(g, next, func(rejected string) *compose.EnhancedStreamableToolOutput {
+					return &compose.EnhancedStreamableToolOutput{
+						Result: schema.StreamReaderFromArray([]*schema.ToolResult{textResult(rejected)}),
+					}
+				})
+			},
+		},
+	}, nil
+}
+
+type gate struct {
+	toolNames      map[string]bool
+	needApproval   func(ctx context.Context, toolName, argumentsInJSON string) bool
+	rejectedResult func(ctx context.Context, info *Info, reason string) string
+}
+
+// approvedState is the interrupt state of an approved tool call interrupted by the tool itself,
+// so that the approval and the edited arguments still apply when the tool is resumed.
+type approvedState struct {
+	ArgumentsInJSON string
+}
+
+// approvedSegmentID is the ID of the address segment the approved tool call runs at,
+// which keeps the interrupt state of the tool apart from the approvedState at the address of the tool call.
+const approvedSegmentID = "approved"
+
+// gated wraps the tool endpoint to run the tool call once it's approved, or to return the rejected result.
+func gated[O any](g *gate, next func(context.Context, *compose.ToolInput) (O, error), rejectedOutput func(string) O) func(
+	context.Context, *compose.ToolInput) (O, error) {
+	return func(ctx context.Context, input *compose.ToolInput) (O, error) {
+		var zero O
+		input, rejected, approved, err := g.check(ctx, input)
+		if err != nil {
+			return zero, err
+		}
+		if rejected != "" {
+			return rejectedOutput(rejected), nil
+		}
+		if !approved {
+			return next(ctx, input)
+		}
+
+		out, err := next(compose.AppendAddressSegment(ctx, compose.AddressSegmentTool, approvedSegmentID), input)
+		if err != nil && isInterrupt(err) {
+			if cErr := tool.CompositeInterrupt(ctx, nil, &approvedState{ArgumentsInJSON: input.Arguments}, err); isInterrupt(cErr) {
+				return zero, cErr
+			}
+		}
+		return out, err
+	}
+}
+
+// check returns the input of the tool call and whether it's approved, or the result of the rejected one,
+// or the interrupt asking for approval.
+func (g *gate) check(ctx context.Context, input *compose.ToolInput) (*compose.ToolInput, string, bool, error) {
+	if _, wasApproved, state := tool.GetInterruptState[*approvedState](ctx); wasApproved {
+		// interrupted by the tool itself after the approval
+		return withArguments(input, state.ArgumentsInJSON), "", true, nil
+	}
+	wasInterrupted, byApproval, info := tool.GetInterruptState[*Info](ctx)
+	if wasInterrupted && !byApproval {
+		// interrupted by a tool that doesn't need approval
+		return input, "", false, nil
+	}
+	if !wasInterrupted {
+		if !g.toolNames[input.Name] && (g.needApproval == nil || !g.needApproval(ctx, input.Name, input.Arguments)) {
+			return input, "", false, nil
+		}
+		info = &Info{ToolName: input.Name, CallID: input.CallID, ArgumentsInJSON: input.Arguments}
+		return nil, "", false, tool.StatefulInterrupt(ctx, info, info)
+	}
+
+	isResumeTarget, hasData, decision := tool.GetResumeContext[*Decision](ctx)
+	if !isResumeTarget {
+		return nil, "", false, tool.StatefulInterrupt(ctx, info, info)
+	}
+	if !hasData || decision == nil {
+		decision = Approve()
+	}
+	if !decision.Approved {
+		return nil, g.rejectedResult(ctx, info, decision.Reason), false, nil
+	}
+	return withArguments(input, decision.EditedArgumentsInJSON), "", true, nil
+}
+
+func withArguments(input *compose.ToolInput, argumentsInJSON string) *compose.ToolInput {
+	if argumentsInJSON == "" || argumentsInJSON == input.Arguments {
+		return input
+	}
+	edited := *input
+	edited.Arguments = argumentsInJSON
+	return &edited
+}
+
+func isInterrupt(err error) bool {
+	if _, ok := compose.IsInterruptRerunError(err); ok {
+		return true
+	}
+	_, ok := compose.ExtractInterruptInfo(err)
+	return ok
+}
+
+func textResult(text string) *schema.ToolResult {
+	return &schema.ToolResult{Parts: []schema.ToolOutputPart{{Type: schema.ToolPartTypeText, Text: text}}}
+}
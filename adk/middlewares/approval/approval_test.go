@@ -0,0 +1,273 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package approval
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+type scriptedModel struct {
+	responses []*schema.Message
+	inputs    [][]*schema.Message
+}
+
+func (m *scriptedModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.inputs = append(m.inputs, input)
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	return resp, nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	resp, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{resp}), nil
+}
+
+func (m *scriptedModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+type inMemoryStore map[string][]byte
+
+func (s inMemoryStore) Get(_ context.Context, id string) ([]byte, bool, error) {
+	data, ok := s[id]
+	return data, ok, nil
+}
+
+func (s inMemoryStore) Set(_ context.Context, id string, data []byte) error {
+	s[id] = data
+	return nil
+}
+
+type pathArgs struct {
+	Path string `json:"path"`
+}
+
+func TestApproval(t *testing.T) {
+	ctx := context.Background()
+
+	drain := func(iter *adk.AsyncIterator[*adk.AgentEvent]) (last *adk.AgentEvent) {
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return last
+			}
+			last = event
+		}
+	}
+
+	// newRunner runs an agent calling delete_file then list_files, of which only delete_file needs approval
+	newRunner := func(t *testing.T, deleted *[]string) (*adk.Runner, *scriptedModel) {
+		deleteTool, err := utils.InferTool("delete_file", "delete a file", func(_ context.Context, in *pathArgs) (string, error) {
+			*deleted = append(*deleted, in.Path)
+			return "deleted " + in.Path, nil
+		})
+		require.NoError(t, err)
+		listTool, err := utils.InferTool("list_files", "list files", func(_ context.Context, in *pathArgs) (string, error) {
+			return "a.txt", nil
+		})
+		require.NoError(t, err)
+
+		m := &scriptedModel{responses: []*schema.Message{
+			schema.AssistantMessage("", []schema.ToolCall{
+				{ID: "1", Function: schema.FunctionCall{Name: "delete_file", Arguments: `{"path":"/data/a.txt"}`}},
+				{ID: "2", Function: schema.FunctionCall{Name: "list_files", Arguments: `{"path":"/data"}`}},
+			}),
+			schema.AssistantMessage("done", nil),
+		}}
+		mw, err := New(ctx, &Config{ToolNames: []string{"delete_file"}})
+		require.NoError(t, err)
+		a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+			Name:        "assistant",
+			Description: "assistant",
+			Model:       m,
+			ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{deleteTool, listTool}}},
+			Middlewares: []adk.AgentMiddleware{mw},
+		})
+		require.NoError(t, err)
+		return adk.NewRunner(ctx, adk.RunnerConfig{Agent: a, CheckPointStore: inMemoryStore{}}), m
+	}
+
+	interrupt := func(t *testing.T, runner *adk.Runner) *adk.InterruptCtx {
+		last := drain(runner.Query(ctx, "delete a.txt", adk.WithCheckPointID("cp")))
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		require.Len(t, last.Action.Interrupted.InterruptContexts, 1)
+		intCtx := last.Action.Interrupted.InterruptContexts[0]
+		assert.Equal(t, &Info{ToolName: "delete_file", CallID: "1", ArgumentsInJSON: `{"path":"/data/a.txt"}`}, intCtx.Info)
+		return intCtx
+	}
+
+	toolResults := func(input []*schema.Message) map[string]string {
+		ret := make(map[string]string)
+		for _, msg := range input {
+			if msg.Role == schema.Tool {
+				ret[msg.ToolCallID] = msg.Content
+			}
+		}
+		return ret
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		var deleted []string
+		runner, m := newRunner(t, &deleted)
+		intCtx := interrupt(t, runner)
+		assert.Empty(t, deleted)
+
+		iter, err := runner.ResumeWithParams(ctx, "cp", &adk.ResumeParams{Targets: map[string]any{intCtx.ID: Approve()}})
+		require.NoError(t, err)
+		last := drain(iter)
+		require.NoError(t, last.Err)
+		assert.Equal(t, "done", last.Output.MessageOutput.Message.Content)
+		assert.Equal(t, []string{"/data/a.txt"}, deleted)
+		assert.Equal(t, map[string]string{"1": "deleted /data/a.txt", "2": "a.txt"}, toolResults(m.inputs[1]))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		var deleted []string
+		runner, m := newRunner(t, &deleted)
+		intCtx := interrupt(t, runner)
+
+		iter, err := runner.ResumeWithParams(ctx, "cp", &adk.ResumeParams{
+			Targets: map[string]any{intCtx.ID: Reject("keep the data")},
+		})
+		require.NoError(t, err)
+		last := drain(iter)
+		require.NoError(t, last.Err)
+		assert.Empty(t, deleted)
+		assert.Equal(t, map[string]string{"1": "tool call rejected by the user: keep the data", "2": "a.txt"}, toolResults(m.inputs[1]))
+	})
+
+	t.Run("edit arguments", func(t *testing.T) {
+		var deleted []string
+		runner, _ := newRunner(t, &deleted)
+		intCtx := interrupt(t, runner)
+
+		iter, err := runner.ResumeWithParams(ctx, "cp", &adk.ResumeParams{
+			Targets: map[string]any{intCtx.ID: ApproveWithEdit(`{"path":"/tmp/a.txt"}`)},
+		})
+		require.NoError(t, err)
+		last := drain(iter)
+		require.NoError(t, last.Err)
+		assert.Equal(t, []string{"/tmp/a.txt"}, deleted)
+	})
+
+	t.Run("edited arguments kept after the tool interrupts", func(t *testing.T) {
+		var calls, deleted []string
+		deleteTool, err := utils.InferTool("delete_file", "delete a file", func(ctx context.Context, in *pathArgs) (string, error) {
+			calls = append(calls, in.Path)
+			if wasInterrupted, _, _ := tool.GetInterruptState[any](ctx); !wasInterrupted {
+				return "", tool.Interrupt(ctx, "confirm again")
+			}
+			deleted = append(deleted, in.Path)
+			return "deleted " + in.Path, nil
+		})
+		require.NoError(t, err)
+		m := &scriptedModel{responses: []*schema.Message{
+			schema.AssistantMessage("", []schema.ToolCall{
+				{ID: "1", Function: schema.FunctionCall{Name: "delete_file", Arguments: `{"path":"/data/a.txt"}`}},
+			}),
+			schema.AssistantMessage("done", nil),
+		}}
+		mw, err := New(ctx, &Config{ToolNames: []string{"delete_file"}})
+		require.NoError(t, err)
+		a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+			Name:        "assistant",
+			Description: "assistant",
+			Model:       m,
+			ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{deleteTool}}},
+			Middlewares: []adk.AgentMiddleware{mw},
+		})
+		require.NoError(t, err)
+		runner := adk.NewRunner(ctx, adk.RunnerConfig{Agent: a, CheckPointStore: inMemoryStore{}})
+		intCtx := interrupt(t, runner)
+
+		iter, err := runner.ResumeWithParams(ctx, "cp", &adk.ResumeParams{
+			Targets: map[string]any{intCtx.ID: ApproveWithEdit(`{"path":"/tmp/a.txt"}`)},
+		})
+		require.NoError(t, err)
+		last := drain(iter)
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		require.Len(t, last.Action.Interrupted.InterruptContexts, 1)
+		toolIntCtx := last.Action.Interrupted.InterruptContexts[0]
+		assert.Equal(t, "confirm again", toolIntCtx.Info)
+
+		iter, err = runner.ResumeWithParams(ctx, "cp", &adk.ResumeParams{Targets: map[string]any{toolIntCtx.ID: nil}})
+		require.NoError(t, err)
+		last = drain(iter)
+		require.NoError(t, last.Err)
+		assert.Equal(t, "done", last.Output.MessageOutput.Message.Content)
+		assert.Equal(t, []string{"/tmp/a.txt", "/tmp/a.txt"}, calls)
+		assert.Equal(t, []string{"/tmp/a.txt"}, deleted)
+		assert.Equal(t, map[string]string{"1": "deleted /tmp/a.txt"}, toolResults(m.inputs[1]))
+	})
+
+	t.Run("interrupt again if not targeted", func(t *testing.T) {
+		var deleted []string
+		runner, _ := newRunner(t, &deleted)
+		interrupt(t, runner)
+
+		iter, err := runner.Resume(ctx, "cp")
+		require.NoError(t, err)
+		last := drain(iter)
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		assert.Equal(t, "delete_file", last.Action.Interrupted.InterruptContexts[0].Info.(*Info).ToolName)
+		assert.Empty(t, deleted)
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		mw, err := New(ctx, &Config{
+			NeedApproval: func(_ context.Context, _, argumentsInJSON string) bool {
+				return strings.Contains(argumentsInJSON, "/data")
+			},
+			RejectedResult: func(_ context.Context, info *Info, reason string) string {
+				return info.ToolName + ": " + reason
+			},
+		})
+		require.NoError(t, err)
+
+		called := false
+		endpoint := mw.WrapToolCall.Invokable(func(context.Context, *compose.ToolInput) (*compose.ToolOutput, error) {
+			called = true
+			return &compose.ToolOutput{Result: "ok"}, nil
+		})
+		out, err := endpoint(ctx, &compose.ToolInput{Name: "list_files", Arguments: `{"path":"/tmp"}`})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", out.Result)
+		assert.True(t, called)
+
+		_, err = New(ctx, &Config{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package guardrail provides a middleware enforcing content policies on the input and the output of the chat model
+// by pluggable validators.
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/internal/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Stage is where the content is validated.
+type Stage string
+
+const (
+	// StageInput is the user input sent to the chat model.
+	StageInput Stage = "input"
+	// StageOutput is the output generated by the chat model.
+	StageOutput Stage = "output"
+)
+
+// Action is the action to take on the validated content.
+type Action string
+
+const (
+	// ActionAllow allows the content as is, same as a nil Verdict.
+	ActionAllow Action = "allow"
+	// ActionBlock blocks the content.
+	// A blocked input fails the run with a *BlockedError, and a blocked output is replaced with Config.BlockedMessage.
+	ActionBlock Action = "block"
+	// ActionRedact replaces the content with Verdict.RedactedContent, which is validated by the following validators.
+	ActionRedact Action = "redact"
+	// ActionRetry asks the chat model to rewrite its output, telling it the reason.
+	// The output is blocked if it still violates the policy after Config.MaxRetries retries.
+	// It's the same as ActionBlock for the input.
+	ActionRetry Action = "retry"
+)
+
+// Verdict is the result of a validation.
+type Verdict struct {
+	Action Action
+	// Reason is why the content is not allowed, e.g. the policy it violates.
+	Reason string
+	// RedactedContent is the content replacing the validated one, required by ActionRedact.
+	RedactedContent string
+}
+
+// Validator validates the text content of a message, returning a nil Verdict to allow it.
+type Validator interface {
+	Validate(ctx context.Context, stage Stage, content string) (*Verdict, error)
+}
+
+// ValidatorFunc is a function implementing Validator.
+type ValidatorFunc func(ctx context.Context, stage Stage, content string) (*Verdict, error)
+
+func (f ValidatorFunc) Validate(ctx context.Context, stage Stage, content string) (*Verdict, error) {
+	return f(ctx, stage, content)
+}
+
+// BlockedError is the error of a run whose input is blocked,
+// or, in incremental streaming, whose output is found to violate the policy.
+type BlockedError struct {
+	Stage  Stage
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("guardrail blocked the %s: %s", e.Stage, e.Reason)
+}
+
+// Config configures the guardrail middleware.
+type Config struct {
+	// InputValidators validate the user input, i.e. the user messages following the last assistant message,
+	// before the chat model is called, in order.
+	// optional, at least one of InputValidators and OutputValidators is required
+	InputValidators []Validator
+
+	// OutputValidators validate the text content of each message generated by the chat model, in order.
+	// optional, at least one of InputValidators and OutputValidators is required
+	OutputValidators []Validator
+
+	// MaxRetries is the max number of times the chat model is asked to rewrite its output for ActionRetry.
+	// optional, 1 by default
+	MaxRetries int
+
+	// RetryPrompt generates the user message asking the chat model to rewrite its output.
+	// optional, a prompt telling the reason of the verdict by default
+	RetryPrompt func(ctx context.Context, verdict *Verdict) string
+
+	// BlockedMessage is the content of the assistant message replacing a blocked output.
+	// optional, "Sorry, I can't help with that." by default
+	BlockedMessage string
+
+	// IncrementalStreaming validates the streaming output incrementally, each time a chunk arrives,
+	// instead of buffering the whole output before validating it.
+	// The chunks are forwarded once the output so far is allowed, so the output is no longer redacted, retried or replaced.
+	// Instead, the stream ends with a *BlockedError when the output isn't allowed,
+	// after forwarding part of the violating content probably.
+	// Validators are called for every chunk, so it suits cheap ones, e.g. those created by NewRegexValidator.
+	// optional, false by default
+	IncrementalStreaming bool
+}
+
+// New creates a middleware validating the input of the chat model in BeforeChatModel and its output by WrapModel.
+// Put it first in ChatModelAgentConfig.Middlewares, so that the input is validated
+// before the BeforeChatModel of the other middlewares, and redacted in the agent state.
+// The output is validated before AfterChatModel and before it's emitted as an event, so a violating output is never seen.
+// The callbacks of the chat model are called with the validated output, not those of each generation.
+func New(_ context.Context, config *Config) (adk.AgentMiddleware, error) {
+	if config == nil || (len(config.InputValidators) == 0 && len(config.OutputValidators) == 0) {
+		return adk.AgentMiddleware{}, errors.New("guardrail middleware requires InputValidators or OutputValidators")
+	}
+
+	g := &guard{
+		inputValidators:      config.InputValidators,
+		outputValidators:     config.OutputValidators,
+		maxRetries:           config.MaxRetries,
+		retryPrompt:          config.RetryPrompt,
+		blockedMessage:       config.BlockedMessage,
+		incrementalStreaming: config.IncrementalStreaming,
+	}
+	if g.maxRetries <= 0 {
+		g.maxRetries = 1
+	}
+	if g.retryPrompt == nil {
+		g.retryPrompt = defaultRetryPrompt
+	}
+	if g.blockedMessage == "" {
+		g.blockedMessage = "Sorry, I can't help with that."
+	}
+
+	mw := adk.AgentMiddleware{}
+	if len(g.inputValidators) > 0 {
+		mw.BeforeChatModel = g.checkInput
+	}
+	if len(g.outputValidators) > 0 {
+		mw.WrapModel = g.wrapModel
+	}
+	return mw, nil
+}
+
+type guard struct {
+	inputValidators      []Validator
+	outputValidators     []Validator
+	maxRetries           int
+	retryPrompt          func(ctx context.Context, verdict *Verdict) string
+	blockedMessage       string
+	incrementalStreaming bool
+}
+
+func defaultRetryPrompt(_ context.Context, verdict *Verdict) string {
+	return fmt.Sprintf("Your previous response violates the content policy: %s\n"+
+		"Rewrite your response so that it complies with the policy.", verdict.Reason)
+}
+
+func (g *guard) checkInput(ctx context.Context, state *adk.ChatModelAgentState) error {
+	ctx = withoutCallbacks(ctx)
+	for i := len(state.Messages) - 1; i >= 0 && state.Messages[i].Role != schema.Assistant; i-- {
+		msg := state.Messages[i]
+		if msg.Role != schema.User {
+			continue
+		}
+		content, verdict, err := validate(ctx, g.inputValidators, StageInput, msg.Content)
+		if err != nil {
+			return err
+		}
+		if verdict != nil && verdict.Action != ActionRedact {
+			return &BlockedError{Stage: StageInput, Reason: verdict.Reason}
+		}
+		if content != msg.Content {
+			redacted := *msg
+			redacted.Content = content
+			state.Messages[i] = &redacted
+		}
+	}
+	return nil
+}
+
+// validate runs the validators in order, returning the content redacted by them,
+// and the verdict blocking the content, or the last redacting one.
+func validate(ctx context.Context, validators []Validator, stage Stage, content string) (string, *Verdict, error) {
+	var last *Verdict
+	for _, v := range validators {
+		verdict, err := v.Validate(ctx, stage, content)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to validate %s: %w", stage, err)
+		}
+		if verdict == nil || verdict.Action == ActionAllow || verdict.Action == "" {
+			continue
+		}
+		if verdict.Action != ActionRedact {
+			return content, verdict, nil
+		}
+		content, last = verdict.RedactedContent, verdict
+	}
+	return content, last, nil
+}
+
+// withoutCallbacks removes all the callback handlers, including the global ones,
+// so that the chat models called by the guardrail, e.g. the wrapped one or a judge, don't emit agent events,
+// and the handlers see only the validated output reported by the guardrail, not each generation.
+func withoutCallbacks(ctx context.Context) context.Context {
+	return callbacks.WithoutHandlers(ctx)
+}
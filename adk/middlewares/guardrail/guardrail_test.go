@@ -0,0 +1,306 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	icallbacks "github.com/cloudwego/eino/internal/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// scriptedModel returns the responses in order, streaming them word by word.
+type scriptedModel struct {
+	responses []*schema.Message
+	inputs    [][]*schema.Message
+}
+
+func (m *scriptedModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.inputs = append(m.inputs, input)
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	return resp, nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	resp, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []*schema.Message
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		chunks = append(chunks, schema.AssistantMessage(word, nil))
+	}
+	return schema.StreamReaderFromArray(chunks), nil
+}
+
+func (m *scriptedModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// callbackModel calls the callbacks itself like the chat models of providers.
+type callbackModel struct {
+	*scriptedModel
+}
+
+func (m *callbackModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	ctx = callbacks.EnsureRunInfo(ctx, "Scripted", components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input})
+	out, err := m.scriptedModel.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	callbacks.OnEnd(ctx, &model.CallbackOutput{Message: out})
+	return out, nil
+}
+
+func (m *callbackModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	ctx = callbacks.EnsureRunInfo(ctx, "Scripted", components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input})
+	sr, err := m.scriptedModel.Stream(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cbSR := schema.StreamReaderWithConvert(sr, func(msg *schema.Message) (callbacks.CallbackOutput, error) {
+		return &model.CallbackOutput{Message: msg}, nil
+	})
+	_, cbSR = callbacks.OnEndWithStreamOutput(ctx, cbSR)
+	return schema.StreamReaderWithConvert(cbSR, func(o callbacks.CallbackOutput) (*schema.Message, error) {
+		return model.ConvCallbackOutput(o).Message, nil
+	}), nil
+}
+
+func (m *callbackModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func (m *callbackModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func TestGuardrail(t *testing.T) {
+	ctx := context.Background()
+
+	pii, err := NewRegexValidator(&RegexConfig{Patterns: PIIPatterns(), Action: ActionRedact})
+	require.NoError(t, err)
+	denyBlock, err := NewDenyListValidator([]string{"password"}, ActionBlock)
+	require.NoError(t, err)
+	denyRetry, err := NewDenyListValidator([]string{"password"}, ActionRetry)
+	require.NoError(t, err)
+
+	// run runs an agent guarded by config, returning the messages of the events and the error of the run
+	run := func(t *testing.T, config *Config, m model.ToolCallingChatModel, streaming bool, query string) ([]string, error) {
+		mw, err := New(ctx, config)
+		require.NoError(t, err)
+		a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+			Name:        "assistant",
+			Description: "assistant",
+			Model:       m,
+			Middlewares: []adk.AgentMiddleware{mw},
+		})
+		require.NoError(t, err)
+
+		iter := adk.NewRunner(ctx, adk.RunnerConfig{Agent: a, EnableStreaming: streaming}).Query(ctx, query)
+		var outputs []string
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return outputs, nil
+			}
+			if event.Err != nil {
+				return outputs, event.Err
+			}
+			if event.Output == nil || event.Output.MessageOutput == nil {
+				continue
+			}
+			msg, err := event.Output.MessageOutput.GetMessage()
+			if err != nil {
+				return outputs, err
+			}
+			outputs = append(outputs, msg.Content)
+		}
+	}
+
+	t.Run("redact input", func(t *testing.T) {
+		m := &scriptedModel{responses: []*schema.Message{schema.AssistantMessage("noted", nil)}}
+		outputs, err := run(t, &Config{InputValidators: []Validator{pii}}, m, false, "mail me at alice@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"noted"}, outputs)
+		input := m.inputs[0]
+		assert.Equal(t, "mail me at [REDACTED]", input[len(input)-1].Content)
+	})
+
+	t.Run("block input", func(t *testing.T) {
+		m := &scriptedModel{}
+		_, err := run(t, &Config{InputValidators: []Validator{denyBlock}}, m, false, "what's the admin password?")
+		var blockedErr *BlockedError
+		require.True(t, errors.As(err, &blockedErr))
+		assert.Equal(t, StageInput, blockedErr.Stage)
+		assert.Empty(t, m.inputs)
+	})
+
+	for _, streaming := range []bool{false, true} {
+		name := map[bool]string{false: "generate", true: "buffered stream"}[streaming]
+		t.Run(name, func(t *testing.T) {
+			t.Run("rewrite and retry output", func(t *testing.T) {
+				m := &scriptedModel{responses: []*schema.Message{
+					schema.AssistantMessage("the password is 123", nil),
+					schema.AssistantMessage("I can't share it", nil),
+				}}
+				outputs, err := run(t, &Config{OutputValidators: []Validator{denyRetry}}, m, streaming, "password?")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"I can't share it"}, outputs)
+				require.Len(t, m.inputs, 2)
+				retryInput := m.inputs[1]
+				require.Len(t, retryInput, len(m.inputs[0])+2)
+				assert.Equal(t, "the password is 123", retryInput[len(retryInput)-2].Content)
+				assert.Contains(t, retryInput[len(retryInput)-1].Content, "violates the content policy")
+			})
+
+			t.Run("global handlers see the validated output only", func(t *testing.T) {
+				var outputs []string
+				handler := callbacks.NewHandlerBuilder().
+					OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+						if info.Component == components.ComponentOfChatModel {
+							outputs = append(outputs, "start")
+						}
+						return ctx
+					}).
+					OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+						if info.Component == components.ComponentOfChatModel {
+							outputs = append(outputs, model.ConvCallbackOutput(output).Message.Content)
+						}
+						return ctx
+					}).
+					OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo,
+						output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+						defer output.Close()
+						if info.Component != components.ComponentOfChatModel {
+							return ctx
+						}
+						var chunks []*schema.Message
+						for {
+							chunk, err := output.Recv()
+							if err != nil {
+								break
+							}
+							chunks = append(chunks, model.ConvCallbackOutput(chunk).Message)
+						}
+						msg, err := schema.ConcatMessages(chunks)
+						assert.NoError(t, err)
+						outputs = append(outputs, msg.Content)
+						return ctx
+					}).Build()
+				globalHandlers := icallbacks.GlobalHandlers
+				icallbacks.GlobalHandlers = []callbacks.Handler{handler}
+				defer func() { icallbacks.GlobalHandlers = globalHandlers }()
+
+				m := &scriptedModel{responses: []*schema.Message{
+					schema.AssistantMessage("the password is 123", nil),
+					schema.AssistantMessage("I can't share it", nil),
+				}}
+				_, err := run(t, &Config{OutputValidators: []Validator{denyRetry}}, &callbackModel{m}, streaming, "password?")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"start", "I can't share it"}, outputs)
+				assert.Len(t, m.inputs, 2)
+			})
+
+			t.Run("block output after retries", func(t *testing.T) {
+				m := &scriptedModel{responses: []*schema.Message{
+					schema.AssistantMessage("the password is 123", nil),
+					schema.AssistantMessage("fine, the password is 123", nil),
+				}}
+				outputs, err := run(t, &Config{OutputValidators: []Validator{denyRetry}, BlockedMessage: "blocked"}, m, streaming, "password?")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"blocked"}, outputs)
+			})
+
+			t.Run("redact output", func(t *testing.T) {
+				m := &scriptedModel{responses: []*schema.Message{schema.AssistantMessage("call 123-45-6789 now", nil)}}
+				outputs, err := run(t, &Config{OutputValidators: []Validator{pii}}, m, streaming, "ssn?")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"call [REDACTED] now"}, outputs)
+			})
+		})
+	}
+
+	t.Run("incremental stream", func(t *testing.T) {
+		m := &scriptedModel{responses: []*schema.Message{schema.AssistantMessage("hello there", nil)}}
+		outputs, err := run(t, &Config{OutputValidators: []Validator{denyBlock}, IncrementalStreaming: true}, m, true, "hi")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hello there"}, outputs)
+
+		m = &scriptedModel{responses: []*schema.Message{schema.AssistantMessage("the password is 123", nil)}}
+		_, err = run(t, &Config{OutputValidators: []Validator{denyBlock}, IncrementalStreaming: true}, m, true, "password?")
+		var blockedErr *BlockedError
+		require.True(t, errors.As(err, &blockedErr))
+		assert.Equal(t, StageOutput, blockedErr.Stage)
+	})
+
+	t.Run("deny list", func(t *testing.T) {
+		v, err := NewDenyListValidator([]string{"pass", "c++", "#secret"}, ActionBlock)
+		require.NoError(t, err)
+		for content, blocked := range map[string]bool{
+			"enter the PASS now":  true,
+			"the password is 123": false,
+			"written in C++ only": true,
+			"c++11 is old":        true,
+			"abc++ isn't a thing": false,
+			"tagged #secret.":     true,
+			"tagged #secrets":     false,
+		} {
+			verdict, err := v.Validate(ctx, StageInput, content)
+			require.NoError(t, err)
+			assert.Equal(t, blocked, verdict != nil, content)
+		}
+
+		_, err = NewDenyListValidator([]string{""}, ActionBlock)
+		assert.Error(t, err)
+	})
+
+	t.Run("judge", func(t *testing.T) {
+		judgeModel := &scriptedModel{responses: []*schema.Message{
+			schema.AssistantMessage("ALLOW", nil),
+			schema.AssistantMessage("Violation: medical advice", nil),
+		}}
+		judge, err := NewJudgeValidator(&JudgeConfig{Model: judgeModel, Policy: "no medical advice"})
+		require.NoError(t, err)
+
+		verdict, err := judge.Validate(ctx, StageOutput, "hello")
+		require.NoError(t, err)
+		assert.Nil(t, verdict)
+		verdict, err = judge.Validate(ctx, StageOutput, "take two pills")
+		require.NoError(t, err)
+		assert.Equal(t, &Verdict{Action: ActionBlock, Reason: "medical advice"}, verdict)
+		assert.Contains(t, judgeModel.inputs[1][0].Content, "no medical advice")
+
+		_, err = NewJudgeValidator(&JudgeConfig{Model: judgeModel, Policy: "p", Action: ActionRedact})
+		assert.Error(t, err)
+		_, err = New(ctx, &Config{})
+		assert.Error(t, err)
+	})
+}
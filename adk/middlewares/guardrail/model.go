@@ -0,0 +1,292 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// guardedModel validates the output of the wrapped chat model before returning it.
+// It calls the callbacks itself with the validated output, and calls the wrapped one without any callback handlers.
+type guardedModel struct {
+	g     *guard
+	inner model.ToolCallingChatModel
+}
+
+func (g *guard) wrapModel(inner model.ToolCallingChatModel) model.ToolCallingChatModel {
+	return &guardedModel{g: g, inner: inner}
+}
+
+func (m *guardedModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	inner, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &guardedModel{g: m.g, inner: inner}, nil
+}
+
+func (m *guardedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	ctx = callbacks.EnsureRunInfo(ctx, m.GetType(), components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input})
+
+	out, err := m.generate(ctx, input, opts)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+
+	callbacks.OnEnd(ctx, &model.CallbackOutput{Message: out})
+	return out, nil
+}
+
+func (m *guardedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	ctx = callbacks.EnsureRunInfo(ctx, m.GetType(), components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input})
+
+	var sr *schema.StreamReader[*schema.Message]
+	var err error
+	if m.g.incrementalStreaming {
+		sr, err = m.streamIncrementally(ctx, input, opts)
+	} else {
+		sr, err = m.stream(ctx, input, opts)
+	}
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+
+	cbSR := schema.StreamReaderWithConvert(sr, func(msg *schema.Message) (*model.CallbackOutput, error) {
+		return &model.CallbackOutput{Message: msg}, nil
+	})
+	_, cbSR = callbacks.OnEndWithStreamOutput(ctx, cbSR)
+	return schema.StreamReaderWithConvert(cbSR, func(o *model.CallbackOutput) (*schema.Message, error) {
+		return o.Message, nil
+	}), nil
+}
+
+func (m *guardedModel) generate(ctx context.Context, input []*schema.Message, opts []model.Option) (*schema.Message, error) {
+	innerCtx := withoutCallbacks(ctx)
+	var usage *schema.TokenUsage
+	for attempt := 0; ; attempt++ {
+		out, err := m.inner.Generate(innerCtx, input, opts...)
+		if err != nil {
+			return nil, err
+		}
+		usage = addUsage(usage, out)
+
+		checked, retryInput, err := m.check(innerCtx, input, out, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if retryInput != nil {
+			input = retryInput
+			continue
+		}
+		if attempt > 0 {
+			checked = withUsage(checked, usage)
+		}
+		return checked, nil
+	}
+}
+
+// stream buffers the whole output before validating it,
+// returning the chunks as they are if the output is allowed in the first generation.
+func (m *guardedModel) stream(ctx context.Context, input []*schema.Message, opts []model.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	innerCtx := withoutCallbacks(ctx)
+	var usage *schema.TokenUsage
+	for attempt := 0; ; attempt++ {
+		sr, err := m.inner.Stream(innerCtx, input, opts...)
+		if err != nil {
+			return nil, err
+		}
+		chunks, err := readAll(sr)
+		if err != nil {
+			return nil, err
+		}
+		out, err := schema.ConcatMessages(chunks)
+		if err != nil {
+			return nil, err
+		}
+		usage = addUsage(usage, out)
+
+		checked, retryInput, err := m.check(innerCtx, input, out, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if retryInput != nil {
+			input = retryInput
+			continue
+		}
+		if attempt == 0 && checked == out {
+			return schema.StreamReaderFromArray(chunks), nil
+		}
+		if attempt > 0 {
+			checked = withUsage(checked, usage)
+		}
+		return schema.StreamReaderFromArray([]*schema.Message{checked}), nil
+	}
+}
+
+// check validates the output, returning the output to return, which is out itself if it's allowed as is,
+// or the input of the next generation if the chat model is asked to rewrite it.
+func (m *guardedModel) check(ctx context.Context, input []*schema.Message, out *schema.Message, attempt int) (
+	*schema.Message, []*schema.Message, error) {
+	content, verdict, err := validate(ctx, m.g.outputValidators, StageOutput, out.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verdict == nil || verdict.Action == ActionRedact {
+		if content == out.Content {
+			return out, nil, nil
+		}
+		redacted := *out
+		redacted.Content = content
+		return &redacted, nil, nil
+	}
+
+	if verdict.Action == ActionRetry && attempt < m.g.maxRetries {
+		retryInput := make([]*schema.Message, 0, len(input)+2)
+		retryInput = append(retryInput, input...)
+		// the tool calls of the rejected output are dropped, which would require their results otherwise
+		retryInput = append(retryInput, schema.AssistantMessage(out.Content, nil), schema.UserMessage(m.g.retryPrompt(ctx, verdict)))
+		return nil, retryInput, nil
+	}
+
+	blocked := schema.AssistantMessage(m.g.blockedMessage, nil)
+	if out.ResponseMeta != nil {
+		blocked.ResponseMeta = &schema.ResponseMeta{Usage: out.ResponseMeta.Usage}
+	}
+	return blocked, nil, nil
+}
+
+// streamIncrementally validates the output so far each time a chunk arrives, forwarding the chunk if it's allowed,
+// or ending the stream with a *BlockedError otherwise.
+func (m *guardedModel) streamIncrementally(ctx context.Context, input []*schema.Message, opts []model.Option) (
+	*schema.StreamReader[*schema.Message], error) {
+	innerCtx := withoutCallbacks(ctx)
+	sr, err := m.inner.Stream(innerCtx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				sw.Send(nil, safe.NewPanicErr(p, debug.Stack()))
+			}
+			sr.Close()
+			sw.Close()
+		}()
+
+		sb := &strings.Builder{}
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+
+			if chunk.Content != "" {
+				sb.WriteString(chunk.Content)
+				_, verdict, err := validate(innerCtx, m.g.outputValidators, StageOutput, sb.String())
+				if err != nil {
+					sw.Send(nil, err)
+					return
+				}
+				if verdict != nil {
+					sw.Send(nil, &BlockedError{Stage: StageOutput, Reason: verdict.Reason})
+					return
+				}
+			}
+			if closed := sw.Send(chunk, nil); closed {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (m *guardedModel) GetType() string {
+	if typ, ok := components.GetType(m.inner); ok {
+		return typ
+	}
+	return generic.ParseTypeName(reflect.ValueOf(m.inner))
+}
+
+func (m *guardedModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func readAll(sr *schema.StreamReader[*schema.Message]) ([]*schema.Message, error) {
+	defer sr.Close()
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+// addUsage adds the token usage of msg to usage, which sums up those of all the generations.
+func addUsage(usage *schema.TokenUsage, msg *schema.Message) *schema.TokenUsage {
+	if msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return usage
+	}
+	if usage == nil {
+		usage = &schema.TokenUsage{}
+	}
+	usage.PromptTokens += msg.ResponseMeta.Usage.PromptTokens
+	usage.CompletionTokens += msg.ResponseMeta.Usage.CompletionTokens
+	usage.TotalTokens += msg.ResponseMeta.Usage.TotalTokens
+	return usage
+}
+
+func withUsage(msg *schema.Message, usage *schema.TokenUsage) *schema.Message {
+	if usage == nil {
+		return msg
+	}
+	ret := *msg
+	meta := schema.ResponseMeta{}
+	if msg.ResponseMeta != nil {
+		meta = *msg.ResponseMeta
+	}
+	meta.Usage = usage
+	ret.ResponseMeta = &meta
+	return &ret
+}
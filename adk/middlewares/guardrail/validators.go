@@ -0,0 +1,181 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// PIIPatterns returns the patterns of common personally identifiable information,
+// i.e. email addresses, credit card numbers and US social security numbers, to be used by NewRegexValidator.
+func PIIPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	}
+}
+
+// RegexConfig configures the validator created by NewRegexValidator.
+type RegexConfig struct {
+	// Patterns are the patterns the content must not match.
+	// required
+	Patterns []*regexp.Regexp
+
+	// Action is the action to take when the content matches any of Patterns.
+	// optional, ActionBlock by default
+	Action Action
+
+	// Replacement replaces the matches for ActionRedact.
+	// optional, "[REDACTED]" by default
+	Replacement string
+}
+
+// NewRegexValidator creates a validator checking whether the content matches any of the patterns,
+// e.g. PIIPatterns to redact personally identifiable information.
+func NewRegexValidator(config *RegexConfig) (Validator, error) {
+	if config == nil || len(config.Patterns) == 0 {
+		return nil, errors.New("regex validator requires patterns")
+	}
+	action := config.Action
+	if action == "" {
+		action = ActionBlock
+	}
+	replacement := config.Replacement
+	if replacement == "" {
+		replacement = "[REDACTED]"
+	}
+
+	return ValidatorFunc(func(_ context.Context, _ Stage, content string) (*Verdict, error) {
+		var matched []string
+		redacted := content
+		for _, p := range config.Patterns {
+			if !p.MatchString(redacted) {
+				continue
+			}
+			matched = append(matched, p.String())
+			redacted = p.ReplaceAllLiteralString(redacted, replacement)
+		}
+		if len(matched) == 0 {
+			return nil, nil
+		}
+		return &Verdict{
+			Action:          action,
+			Reason:          fmt.Sprintf("the content matches the forbidden patterns %q", matched),
+			RedactedContent: redacted,
+		}, nil
+	}), nil
+}
+
+// NewDenyListValidator creates a validator checking whether the content contains any of the words, case-insensitively.
+// A word matches only as a whole, e.g. "pass" doesn't match "password", except at its edges of non-word characters,
+// e.g. "c++" matches "c++11".
+func NewDenyListValidator(words []string, action Action) (Validator, error) {
+	if len(words) == 0 {
+		return nil, errors.New("deny list validator requires words")
+	}
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w == "" {
+			return nil, errors.New("deny list validator requires non-empty words")
+		}
+		// \b never matches between two non-word characters, e.g. after "c++" followed by a space
+		q := regexp.QuoteMeta(w)
+		if isWordChar(w[0]) {
+			q = `\b` + q
+		}
+		if isWordChar(w[len(w)-1]) {
+			q += `\b`
+		}
+		quoted = append(quoted, q)
+	}
+	p, err := regexp.Compile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile deny list: %w", err)
+	}
+	return NewRegexValidator(&RegexConfig{Patterns: []*regexp.Regexp{p}, Action: action})
+}
+
+// isWordChar reports whether c is a word character of \b, i.e. an ASCII letter, digit or underscore.
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+const judgePrompt = `You are a content moderator checking whether the %s of an AI assistant complies with the content policy below.
+
+Content policy:
+%s
+
+Reply "ALLOW" if the content complies with the policy, or "VIOLATION: " followed by the reason if it doesn't. Reply nothing else.`
+
+var violationPattern = regexp.MustCompile(`(?is)violation:(.*)`)
+
+// JudgeConfig configures the validator created by NewJudgeValidator.
+type JudgeConfig struct {
+	// Model judges whether the content complies with Policy.
+	// required
+	Model model.BaseChatModel
+
+	// Policy describes the content policy in natural language.
+	// required
+	Policy string
+
+	// Action is the action to take when the content violates Policy, ActionBlock or ActionRetry.
+	// optional, ActionBlock by default
+	Action Action
+}
+
+// NewJudgeValidator creates a validator asking another chat model whether the content complies with the policy.
+func NewJudgeValidator(config *JudgeConfig) (Validator, error) {
+	if config == nil || config.Model == nil || config.Policy == "" {
+		return nil, errors.New("judge validator requires a model and a policy")
+	}
+	action := config.Action
+	if action == "" {
+		action = ActionBlock
+	}
+	if action != ActionBlock && action != ActionRetry {
+		return nil, fmt.Errorf("judge validator doesn't support action %s", action)
+	}
+
+	return ValidatorFunc(func(ctx context.Context, stage Stage, content string) (*Verdict, error) {
+		resp, err := config.Model.Generate(ctx, []*schema.Message{
+			schema.SystemMessage(fmt.Sprintf(judgePrompt, stage, config.Policy)),
+			schema.UserMessage(content),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to judge content: %w", err)
+		}
+
+		answer := strings.TrimSpace(resp.Content)
+		if strings.HasPrefix(strings.ToUpper(answer), "ALLOW") {
+			return nil, nil
+		}
+		reason := answer
+		if m := violationPattern.FindStringSubmatch(answer); m != nil {
+			reason = strings.TrimSpace(m[1])
+		}
+		return &Verdict{Action: action, Reason: reason}, nil
+	}), nil
+}
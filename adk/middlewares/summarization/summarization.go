@@ -0,0 +1,244 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package summarization provides a middleware compressing the older turns of the conversation into a summary.
+package summarization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	defaultSummaryPrompt = `You are summarizing the earlier part of a conversation between a user and an AI assistant, which will be replaced by your summary.
+Keep the facts, decisions, user preferences, open questions, and the results of tool calls that may be needed later.
+Be concise, and write the summary only, without any preface.`
+	defaultSummaryPrefix = "Summary of the earlier conversation:\n"
+)
+
+// Config configures the summarization middleware.
+type Config struct {
+	// Model generates the summary of the older turns.
+	// required
+	Model model.BaseChatModel
+
+	// TokenThreshold is the threshold of the total token count of the messages sent to the chat model.
+	// When it's exceeded, the turns before the most recent KeepRecentTurns ones are replaced with a summary.
+	// optional, 80000 by default
+	TokenThreshold int
+
+	// KeepRecentTurns is the number of the most recent turns to keep intact, where a turn starts with a user message.
+	// If there are too few turns to summarize, e.g. a single user turn with a long tool loop,
+	// the user messages are kept, and the assistant messages with their tool results are summarized
+	// except the most recent KeepRecentTurns ones.
+	// optional, 2 by default
+	KeepRecentTurns int
+
+	// TokenCounter is a custom function to estimate token count for a message.
+	// optional, uses the default counter (character count / 4) if nil
+	TokenCounter func(msg *schema.Message) int
+
+	// SummaryPrompt is the system prompt instructing Model to summarize the turns, which are sent as a user message.
+	// optional, a general-purpose prompt by default
+	SummaryPrompt string
+
+	// SummaryPrefix is prepended to the summary in the user message replacing the turns.
+	// optional, "Summary of the earlier conversation:\n" by default
+	SummaryPrefix string
+}
+
+// NewSummarization creates a middleware that compresses the older turns of the conversation into a summary
+// before the chat model call, when the messages exceed the token threshold.
+// The leading system messages, i.e. the system prompt, and the most recent turns are kept intact.
+// A tool call and its results are always either both summarized or both kept.
+func NewSummarization(_ context.Context, config *Config) (adk.AgentMiddleware, error) {
+	if config == nil || config.Model == nil {
+		return adk.AgentMiddleware{}, errors.New("summarization middleware requires a model")
+	}
+
+	s := &summarizer{
+		model:           config.Model,
+		tokenThreshold:  config.TokenThreshold,
+		keepRecentTurns: config.KeepRecentTurns,
+		counter:         config.TokenCounter,
+		prompt:          config.SummaryPrompt,
+		prefix:          config.SummaryPrefix,
+	}
+	if s.tokenThreshold <= 0 {
+		s.tokenThreshold = 80000
+	}
+	if s.keepRecentTurns <= 0 {
+		s.keepRecentTurns = 2
+	}
+	if s.counter == nil {
+		s.counter = defaultTokenCounter
+	}
+	if s.prompt == "" {
+		s.prompt = defaultSummaryPrompt
+	}
+	if s.prefix == "" {
+		s.prefix = defaultSummaryPrefix
+	}
+
+	return adk.AgentMiddleware{
+		BeforeChatModel: s.summarize,
+	}, nil
+}
+
+type summarizer struct {
+	model           model.BaseChatModel
+	tokenThreshold  int
+	keepRecentTurns int
+	counter         func(msg *schema.Message) int
+	prompt          string
+	prefix          string
+}
+
+func (s *summarizer) summarize(ctx context.Context, state *adk.ChatModelAgentState) error {
+	total := 0
+	for _, msg := range state.Messages {
+		total += s.counter(msg)
+	}
+	if total <= s.tokenThreshold {
+		return nil
+	}
+
+	start, end := splitTurns(state.Messages, s.keepRecentTurns)
+	if start >= end {
+		// not enough turns to summarize
+		return nil
+	}
+
+	resp, err := s.model.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(s.prompt),
+		schema.UserMessage(formatTranscript(state.Messages[start:end])),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+
+	messages := make([]adk.Message, 0, start+1+len(state.Messages)-end)
+	messages = append(messages, state.Messages[:start]...)
+	messages = append(messages, schema.UserMessage(s.prefix+resp.Content))
+	messages = append(messages, state.Messages[end:]...)
+	state.Messages = messages
+	return nil
+}
+
+// splitTurns returns the range of the messages to summarize,
+// which starts after the leading system messages and ends before the most recent keepRecentTurns turns.
+// If there are too few turns, see splitGroups.
+func splitTurns(messages []adk.Message, keepRecentTurns int) (start, end int) {
+	for start < len(messages) && messages[start].Role == schema.System {
+		start++
+	}
+
+	end = len(messages)
+	kept := 0
+	for i := len(messages) - 1; i >= start && kept < keepRecentTurns; i-- {
+		if messages[i].Role == schema.User {
+			kept++
+			end = i
+		}
+	}
+	if kept < keepRecentTurns || end <= start {
+		start, end = splitGroups(messages, start, keepRecentTurns)
+	}
+
+	// keep the tool calls whose results are kept, e.g. when a user message is inserted between them,
+	// so that no tool message is orphaned
+	for {
+		callAt := make(map[string]int)
+		for i := start; i < end; i++ {
+			for _, tc := range messages[i].ToolCalls {
+				callAt[tc.ID] = i
+			}
+		}
+		moved := false
+		for _, msg := range messages[end:] {
+			if i, ok := callAt[msg.ToolCallID]; ok && msg.Role == schema.Tool {
+				end, moved = i, true
+				break
+			}
+		}
+		if !moved {
+			return start, end
+		}
+	}
+}
+
+// splitGroups returns the range of the messages to summarize when there are too few turns,
+// e.g. a single user turn with a long tool loop, which is cut between the groups of an assistant message and its tool results instead.
+// The range follows the last user message, which is kept intact,
+// and ends before the most recent keepRecentGroups groups.
+func splitGroups(messages []adk.Message, start, keepRecentGroups int) (int, int) {
+	for i := len(messages) - 1; i >= start; i-- {
+		if messages[i].Role == schema.User {
+			start = i + 1
+			break
+		}
+	}
+	// the results of the tool calls before the user message are kept along with their calls
+	for start < len(messages) && messages[start].Role == schema.Tool {
+		start++
+	}
+
+	end := len(messages)
+	kept := 0
+	for i := len(messages) - 1; i >= start && kept < keepRecentGroups; i-- {
+		if messages[i].Role == schema.Assistant {
+			kept++
+			end = i
+		}
+	}
+	if kept < keepRecentGroups {
+		return start, start
+	}
+	return start, end
+}
+
+func formatTranscript(messages []adk.Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case schema.Tool:
+			sb.WriteString(fmt.Sprintf("[tool `%s` returned]: %s\n", msg.ToolName, msg.Content))
+		default:
+			if msg.Content != "" {
+				sb.WriteString(fmt.Sprintf("[%s]: %s\n", msg.Role, msg.Content))
+			}
+			for _, tc := range msg.ToolCalls {
+				sb.WriteString(fmt.Sprintf("[%s called tool `%s`]: %s\n", msg.Role, tc.Function.Name, tc.Function.Arguments))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// defaultTokenCounter estimates token count using character count / 4
+func defaultTokenCounter(msg *schema.Message) int {
+	count := len(msg.Content)
+	for _, tc := range msg.ToolCalls {
+		count += len(tc.Function.Arguments)
+	}
+	return (count + 3) / 4
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/cloudwego/eino/adk"
+	mockModel "github.com/cloudwego/eino/internal/mock/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestSummarization(t *testing.T) {
+	ctx := context.Background()
+
+	toolCall := func(id string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"x"}`}}})
+	}
+	history := func() []adk.Message {
+		return []adk.Message{
+			schema.SystemMessage("you are helpful"),
+			schema.UserMessage("u1"),
+			toolCall("1"),
+			schema.ToolMessage("r1", "1", schema.WithToolName("search")),
+			schema.AssistantMessage("a1", nil),
+			schema.UserMessage("u2"),
+			schema.AssistantMessage("a2", nil),
+			schema.UserMessage("u3"),
+			toolCall("3"),
+			schema.ToolMessage("r3", "3", schema.WithToolName("search")),
+		}
+	}
+	countOne := func(*schema.Message) int { return 1 }
+
+	t.Run("summarize older turns", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockToolCallingChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input []*schema.Message, _ ...any) (*schema.Message, error) {
+				require.Len(t, input, 2)
+				assert.Equal(t, "[user]: u1\n[assistant called tool `search`]: {\"q\":\"x\"}\n[tool `search` returned]: r1\n"+
+					"[assistant]: a1\n[user]: u2\n[assistant]: a2\n", input[1].Content)
+				return schema.AssistantMessage("the user asked u1 and u2", nil), nil
+			}).Times(1)
+
+		mw, err := NewSummarization(ctx, &Config{Model: cm, TokenThreshold: 5, KeepRecentTurns: 1, TokenCounter: countOne})
+		require.NoError(t, err)
+		msgs := history()
+		state := &adk.ChatModelAgentState{Messages: msgs}
+		require.NoError(t, mw.BeforeChatModel(ctx, state))
+		assert.Equal(t, []adk.Message{
+			msgs[0],
+			schema.UserMessage("Summary of the earlier conversation:\nthe user asked u1 and u2"),
+			msgs[7], msgs[8], msgs[9],
+		}, state.Messages)
+	})
+
+	t.Run("under threshold or not enough turns", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockToolCallingChatModel(ctrl)
+
+		mw, err := NewSummarization(ctx, &Config{Model: cm, TokenThreshold: 100, TokenCounter: countOne})
+		require.NoError(t, err)
+		state := &adk.ChatModelAgentState{Messages: history()}
+		require.NoError(t, mw.BeforeChatModel(ctx, state))
+		assert.Equal(t, history(), state.Messages)
+
+		mw, err = NewSummarization(ctx, &Config{Model: cm, TokenThreshold: 1, KeepRecentTurns: 3, TokenCounter: countOne})
+		require.NoError(t, err)
+		require.NoError(t, mw.BeforeChatModel(ctx, state))
+		assert.Equal(t, history(), state.Messages)
+	})
+
+	t.Run("tool calls are never split from their results", func(t *testing.T) {
+		msgs := []adk.Message{
+			schema.UserMessage("u1"),
+			toolCall("1"),
+			schema.UserMessage("u2"),
+			schema.ToolMessage("r1", "1"),
+		}
+		start, end := splitTurns(msgs, 1)
+		assert.Equal(t, 0, start)
+		assert.Equal(t, 1, end)
+	})
+
+	t.Run("single user turn with a long tool loop", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockToolCallingChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input []*schema.Message, _ ...any) (*schema.Message, error) {
+				assert.Equal(t, "[assistant called tool `search`]: {\"q\":\"x\"}\n[tool `search` returned]: r1\n"+
+					"[assistant called tool `search`]: {\"q\":\"x\"}\n[tool `search` returned]: r2\n", input[1].Content)
+				return schema.AssistantMessage("searched twice", nil), nil
+			}).Times(1)
+
+		mw, err := NewSummarization(ctx, &Config{Model: cm, TokenThreshold: 5, KeepRecentTurns: 2, TokenCounter: countOne})
+		require.NoError(t, err)
+		msgs := []adk.Message{
+			schema.SystemMessage("you are helpful"),
+			schema.UserMessage("u1"),
+			toolCall("1"),
+			schema.ToolMessage("r1", "1", schema.WithToolName("search")),
+			toolCall("2"),
+			schema.ToolMessage("r2", "2", schema.WithToolName("search")),
+			toolCall("3"),
+			schema.ToolMessage("r3", "3", schema.WithToolName("search")),
+			toolCall("4"),
+			schema.ToolMessage("r4", "4", schema.WithToolName("search")),
+		}
+		state := &adk.ChatModelAgentState{Messages: msgs}
+		require.NoError(t, mw.BeforeChatModel(ctx, state))
+		assert.Equal(t, []adk.Message{
+			msgs[0], msgs[1],
+			schema.UserMessage("Summary of the earlier conversation:\nsearched twice"),
+			msgs[6], msgs[7], msgs[8], msgs[9],
+		}, state.Messages)
+
+		// a group whose results are split by a user message stays with its results
+		start, end := splitTurns([]adk.Message{
+			schema.UserMessage("u1"),
+			toolCall("1"),
+			toolCall("2"),
+			schema.ToolMessage("r2", "2"),
+			toolCall("3"),
+			schema.ToolMessage("r1", "1"),
+			schema.ToolMessage("r3", "3"),
+		}, 1)
+		assert.Equal(t, 1, start)
+		assert.Equal(t, 1, end)
+	})
+
+	t.Run("model error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cm := mockModel.NewMockToolCallingChatModel(ctrl)
+		cm.EXPECT().Generate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("unavailable"))
+
+		mw, err := NewSummarization(ctx, &Config{Model: cm, TokenThreshold: 1, KeepRecentTurns: 1, TokenCounter: countOne})
+		require.NoError(t, err)
+		state := &adk.ChatModelAgentState{Messages: history()}
+		assert.Error(t, mw.BeforeChatModel(ctx, state))
+		assert.Equal(t, history(), state.Messages)
+
+		_, err = NewSummarization(ctx, &Config{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/cloudwego/eino/internal/core"
+)
+
+// PendingInterrupts describes what an interrupted run stored in a checkpoint is waiting on.
+type PendingInterrupts = core.PendingInterrupts
+
+// InterruptEdit describes the changes to the pending interrupts of a checkpoint, see Runner.EditPendingInterrupts.
+type InterruptEdit = core.InterruptEdit
+
+// GetPendingInterrupts loads the checkpoint written by an interrupted run, and returns its pending interrupts,
+// the same InterruptContexts as the ones of the interrupt event, along with the saved states, without running anything.
+func (r *Runner) GetPendingInterrupts(ctx context.Context, checkPointID string) (*PendingInterrupts, error) {
+	s, err := r.getCheckPoint(ctx, checkPointID)
+	if err != nil {
+		return nil, err
+	}
+	return core.ToPendingInterrupts(checkPointID, s.InterruptID2Address, s.InterruptID2State,
+		s.InterruptID2Point, s.InterruptID2ResumeData, allowedAddressSegmentTypes), nil
+}
+
+// EditPendingInterrupts replaces the saved states of the interrupt points and stores the resume targets,
+// then writes the checkpoint back, without running anything.
+// The stored resume targets take effect on the next Resume of the checkpoint ID, e.g.
+//
+//	err := runner.EditPendingInterrupts(ctx, "session-1", &adk.InterruptEdit{
+//		ResumeData: map[string]any{interruptID: &ApprovalResult{Approved: true}},
+//	})
+//	// later, possibly in another process
+//	iter, err := runner.Resume(ctx, "session-1")
+func (r *Runner) EditPendingInterrupts(ctx context.Context, checkPointID string, edit *InterruptEdit) error {
+	s, err := r.getCheckPoint(ctx, checkPointID)
+	if err != nil {
+		return err
+	}
+	if s.InterruptID2State == nil {
+		s.InterruptID2State = make(map[string]core.InterruptState)
+	}
+	s.InterruptID2ResumeData, err = core.ApplyInterruptEdit(edit, s.InterruptID2Address, s.InterruptID2State, s.InterruptID2ResumeData)
+	if err != nil {
+		return fmt.Errorf("failed to edit checkpoint[%s]: %w", checkPointID, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = gob.NewEncoder(buf).Encode(s); err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	return r.store.Set(ctx, checkPointID, buf.Bytes())
+}
+
+func (r *Runner) getCheckPoint(ctx context.Context, checkPointID string) (*serialization, error) {
+	if r.store == nil {
+		return nil, fmt.Errorf("failed to get checkpoint: store is nil")
+	}
+	data, existed, err := r.store.Get(ctx, checkPointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint from store: %w", err)
+	}
+	if !existed {
+		return nil, fmt.Errorf("checkpoint[%s] not exist", checkPointID)
+	}
+	return decodeCheckPoint(data)
+}
@@ -272,6 +272,10 @@ func newReact(ctx context.Context, config *reactConfig) (reactGraph, error) {
 	}
 
 	modelPreHandle := func(ctx context.Context, input []Message, st *State) ([]Message, error) {
+		input, err := checkModelBudget(ctx, config.agentName, input)
+		if err != nil {
+			return nil, err
+		}
 		if st.RemainingIterations <= 0 {
 			return nil, ErrExceedMaxIterations
 		}
@@ -289,6 +293,7 @@ func newReact(ctx context.Context, config *reactConfig) (reactGraph, error) {
 		return st.Messages, nil
 	}
 	modelPostHandle := func(ctx context.Context, input Message, st *State) (Message, error) {
+		recordModelUsage(ctx, config.agentName, input)
 		s := &ChatModelAgentState{Messages: append(st.Messages, input)}
 		for _, a := range config.afterChatModel {
 			err = a(ctx, s)
@@ -304,6 +309,9 @@ func newReact(ctx context.Context, config *reactConfig) (reactGraph, error) {
 
 	toolPreHandle := func(ctx context.Context, input Message, st *State) (Message, error) {
 		input = st.Messages[len(st.Messages)-1]
+		if err := checkToolBudget(ctx, config.agentName, input); err != nil {
+			return nil, err
+		}
 		if len(config.toolsReturnDirectly) > 0 {
 			for i := range input.ToolCalls {
 				toolName := input.ToolCalls[i].Function.Name
@@ -37,6 +37,10 @@ type Runner struct {
 	// store is the checkpoint store used to persist agent state upon interruption.
 	// If nil, checkpointing is disabled.
 	store CheckPointStore
+	// budget limits the resources consumed by each run, nil for no limit.
+	budget *RunBudget
+	// sessionStore persists the history and the session values of the conversations.
+	sessionStore SessionStore
 }
 
 type CheckPointStore = core.CheckPointStore
@@ -46,6 +50,14 @@ type RunnerConfig struct {
 	EnableStreaming bool
 
 	CheckPointStore CheckPointStore
+
+	// Budget limits the tokens, tool calls and wall-clock time consumed by each run, see RunBudget.
+	// Optional. Without it, a run is only limited by the MaxIterations of its agents.
+	Budget *RunBudget
+
+	// SessionStore persists the history and the session values of the conversations across runs,
+	// for the runs with WithConversationID. Optional.
+	SessionStore SessionStore
 }
 
 // ResumeParams contains all parameters needed to resume an execution.
@@ -65,6 +77,8 @@ func NewRunner(_ context.Context, conf RunnerConfig) *Runner {
 		enableStreaming: conf.EnableStreaming,
 		a:               conf.Agent,
 		store:           conf.CheckPointStore,
+		budget:          conf.Budget,
+		sessionStore:    conf.SessionStore,
 	}
 }
 
@@ -72,6 +86,8 @@ func NewRunner(_ context.Context, conf RunnerConfig) *Runner {
 // It returns an iterator that yields agent events as they occur.
 // If the Runner was configured with a CheckPointStore, it will automatically save the agent's state
 // upon interruption.
+// If the Runner was configured with a SessionStore and the run has WithConversationID, the history of the conversation
+// is prepended to messages, and the messages of the run are appended to the history when it completes.
 func (r *Runner) Run(ctx context.Context, messages []Message,
 	opts ...AgentRunOption) *AsyncIterator[*AgentEvent] {
 	o := getCommonOptions(nil, opts...)
@@ -85,16 +101,24 @@ func (r *Runner) Run(ctx context.Context, messages []Message,
 
 	ctx = ctxWithNewRunCtx(ctx, input, o.sharedParentSession)
 
+	var err error
+	ctx, input.Messages, err = r.loadConversation(ctx, o.conversationID, messages)
+	if err != nil {
+		return genErrorIter(err)
+	}
+
 	AddSessionValues(ctx, o.sessionValues)
 
+	ctx = withBudgetTracker(ctx, r.budget, o, nil)
+
 	iter := fa.Run(ctx, input, opts...)
-	if r.store == nil {
+	if r.store == nil && o.runUsage == nil && getConversation(ctx) == nil {
 		return iter
 	}
 
 	niter, gen := NewAsyncIteratorPair[*AgentEvent]()
 
-	go r.handleIter(ctx, iter, gen, o.checkPointID)
+	go r.handleIter(ctx, iter, gen, o.checkPointID, newCheckPointVersion(o, ""), o.runUsage)
 	return niter
 }
 
@@ -140,18 +164,27 @@ func (r *Runner) ResumeWithParams(ctx context.Context, checkPointID string, para
 }
 
 // resume is the internal implementation for both Resume and ResumeWithParams.
+// The checkpoint is written back to checkPointID, or to the one set by WithCheckPointID if any.
 func (r *Runner) resume(ctx context.Context, checkPointID string, resumeData map[string]any,
 	opts ...AgentRunOption) (*AsyncIterator[*AgentEvent], error) {
 	if r.store == nil {
 		return nil, fmt.Errorf("failed to resume: store is nil")
 	}
 
-	ctx, runCtx, resumeInfo, err := r.loadCheckPoint(ctx, checkPointID)
+	o := getCommonOptions(nil, opts...)
+	writeToCheckPointID := checkPointID
+	if o.checkPointID != nil {
+		writeToCheckPointID = *o.checkPointID
+	}
+	if core.IsCheckPointVersionID(writeToCheckPointID) {
+		return nil, fmt.Errorf("checkpoint version[%s] is read-only, use WithCheckPointID to fork from it", writeToCheckPointID)
+	}
+
+	ctx, runCtx, resumeInfo, err := r.loadCheckPoint(ctx, checkPointID, o)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load from checkpoint: %w", err)
 	}
 
-	o := getCommonOptions(nil, opts...)
 	if o.sharedParentSession {
 		parentSession := getSession(ctx)
 		if parentSession != nil {
@@ -182,12 +215,24 @@ func (r *Runner) resume(ctx context.Context, checkPointID string, resumeData map
 
 	niter, gen := NewAsyncIteratorPair[*AgentEvent]()
 
-	go r.handleIter(ctx, aIter, gen, &checkPointID)
+	var forkedFrom string
+	if writeToCheckPointID != checkPointID {
+		forkedFrom = checkPointID
+	}
+	go r.handleIter(ctx, aIter, gen, &writeToCheckPointID, newCheckPointVersion(o, forkedFrom), o.runUsage)
 	return niter, nil
 }
 
+// newCheckPointVersion returns the template of the checkpoint versions to write, nil if history isn't enabled.
+func newCheckPointVersion(o *options, forkedFrom string) *CheckPointVersion {
+	if !o.checkPointHistory {
+		return nil
+	}
+	return &CheckPointVersion{Step: -1, Interrupted: true, ForkedFrom: forkedFrom}
+}
+
 func (r *Runner) handleIter(ctx context.Context, aIter *AsyncIterator[*AgentEvent],
-	gen *AsyncGenerator[*AgentEvent], checkPointID *string) {
+	gen *AsyncGenerator[*AgentEvent], checkPointID *string, version *CheckPointVersion, usage *RunUsage) {
 	defer func() {
 		panicErr := recover()
 		if panicErr != nil {
@@ -195,11 +240,17 @@ func (r *Runner) handleIter(ctx context.Context, aIter *AsyncIterator[*AgentEven
 			gen.Send(&AgentEvent{Err: e})
 		}
 
+		if t := getBudgetTracker(ctx); t != nil && usage != nil {
+			_, u := t.snapshot()
+			*usage = *u
+		}
+
 		gen.Close()
 	}()
 	var (
 		interruptSignal *core.InterruptSignal
 		legacyData      any
+		failed          bool
 	)
 	for {
 		event, ok := aIter.Next()
@@ -207,6 +258,10 @@ func (r *Runner) handleIter(ctx context.Context, aIter *AsyncIterator[*AgentEven
 			break
 		}
 
+		if event.Err != nil {
+			failed = true
+		}
+
 		if event.Action != nil && event.Action.internalInterrupted != nil {
 			if interruptSignal != nil {
 				// even if multiple interrupt happens, they should be merged into one
@@ -230,12 +285,12 @@ func (r *Runner) handleIter(ctx context.Context, aIter *AsyncIterator[*AgentEven
 			}
 			legacyData = event.Action.Interrupted.Data
 
-			if checkPointID != nil {
+			if r.store != nil && checkPointID != nil {
 				// save checkpoint first before sending interrupt event,
 				// so when end-user receives interrupt event, they can resume from this checkpoint
 				err := r.saveCheckPoint(ctx, *checkPointID, &InterruptInfo{
 					Data: legacyData,
-				}, interruptSignal)
+				}, interruptSignal, version)
 				if err != nil {
 					gen.Send(&AgentEvent{Err: fmt.Errorf("failed to save checkpoint: %w", err)})
 				}
@@ -244,4 +299,11 @@ func (r *Runner) handleIter(ctx context.Context, aIter *AsyncIterator[*AgentEven
 
 		gen.Send(event)
 	}
+
+	// the turn completes, unless the run is interrupted or fails
+	if interruptSignal == nil && !failed {
+		if err := r.saveConversation(ctx); err != nil {
+			gen.Send(&AgentEvent{Err: err})
+		}
+	}
 }
@@ -0,0 +1,271 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StoredSession is the history and the session values of a conversation persisted by a SessionStore.
+type StoredSession struct {
+	// Messages are the messages of the turns completed, i.e. the input messages of each turn
+	// followed by the messages generated by the agents run in the turn, excluding those of the agents run by agent tools.
+	Messages []Message
+	// Values are the session values when the last turn completed.
+	Values map[string]any
+}
+
+// SessionStore persists the conversations run by the Runner, keyed by the conversation ID set by WithConversationID.
+// At the start of a run, the Runner loads the stored session, prepending its messages to the input messages and adding its values to the session values.
+// When the run completes, the Runner appends the messages of the turn to the stored session and replaces its values by Append.
+// A run ending with an error doesn't change the stored session, nor does an interrupted one until it's resumed and completes.
+// Runs of the same conversation may complete concurrently, e.g. two turns close together, or a resumed run racing a new turn,
+// in which case each turn is appended in the order the runs complete, and the values are those of the last one.
+type SessionStore interface {
+	Get(ctx context.Context, conversationID string) (*StoredSession, bool, error)
+	// Set replaces the stored session, e.g. to edit or clear the history.
+	Set(ctx context.Context, conversationID string, session *StoredSession) error
+	// Append appends the messages to the stored session, creating it if not existed, and replaces its values.
+	// It must be atomic, so that the messages appended concurrently are all kept.
+	Append(ctx context.Context, conversationID string, messages []Message, values map[string]any) error
+}
+
+// WithConversationID sets the ID of the conversation of the run, whose history and session values are loaded from
+// and saved to RunnerConfig.SessionStore. It's not needed when resuming, the ID is saved in the checkpoint.
+func WithConversationID(id string) AgentRunOption {
+	return WrapImplSpecificOptFn(func(o *options) {
+		o.conversationID = id
+	})
+}
+
+// NewInMemorySessionStore returns a SessionStore keeping the conversations in memory.
+func NewInMemorySessionStore() SessionStore {
+	return &inMemorySessionStore{sessions: make(map[string]*StoredSession)}
+}
+
+type inMemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*StoredSession
+}
+
+func (s *inMemorySessionStore) Get(_ context.Context, conversationID string) (*StoredSession, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[conversationID]
+	if !ok {
+		return nil, false, nil
+	}
+	return copyStoredSession(session), true, nil
+}
+
+func (s *inMemorySessionStore) Set(_ context.Context, conversationID string, session *StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[conversationID] = copyStoredSession(session)
+	return nil
+}
+
+func (s *inMemorySessionStore) Append(_ context.Context, conversationID string, messages []Message, values map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[conversationID]
+	if !ok {
+		session = &StoredSession{}
+	}
+	s.sessions[conversationID] = copyStoredSession(&StoredSession{
+		Messages: append(session.Messages, messages...),
+		Values:   values,
+	})
+	return nil
+}
+
+func copyStoredSession(s *StoredSession) *StoredSession {
+	ret := &StoredSession{
+		Messages: make([]Message, len(s.Messages)),
+		Values:   make(map[string]any, len(s.Values)),
+	}
+	copy(ret.Messages, s.Messages)
+	for k, v := range s.Values {
+		ret.Values[k] = v
+	}
+	return ret
+}
+
+// NewFileSessionStore returns a SessionStore keeping each conversation in a file under dir, encoded by gob.
+// Like the checkpoints, the types of the session values must be registered by schema.RegisterName.
+// Append is atomic among the runs sharing the returned store only, so dir shouldn't be shared by processes running the same conversations.
+func NewFileSessionStore(dir string) (SessionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session store dir: %w", err)
+	}
+	return &fileSessionStore{dir: dir}, nil
+}
+
+type fileSessionStore struct {
+	dir string
+	mu  sync.Mutex // guards Append against the concurrent writes
+}
+
+func (s *fileSessionStore) path(conversationID string) string {
+	return filepath.Join(s.dir, url.PathEscape(conversationID)+".gob")
+}
+
+func (s *fileSessionStore) Get(_ context.Context, conversationID string) (*StoredSession, bool, error) {
+	data, err := os.ReadFile(s.path(conversationID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read session[%s]: %w", conversationID, err)
+	}
+	session := &StoredSession{}
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(session); err != nil {
+		return nil, false, fmt.Errorf("failed to decode session[%s]: %w", conversationID, err)
+	}
+	return session, true, nil
+}
+
+func (s *fileSessionStore) Set(ctx context.Context, conversationID string, session *StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(conversationID, session)
+}
+
+func (s *fileSessionStore) Append(ctx context.Context, conversationID string, messages []Message, values map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, existed, err := s.Get(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if !existed {
+		session = &StoredSession{}
+	}
+	session.Messages = append(session.Messages, messages...)
+	session.Values = values
+	return s.write(conversationID, session)
+}
+
+func (s *fileSessionStore) write(conversationID string, session *StoredSession) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(session); err != nil {
+		return fmt.Errorf("failed to encode session[%s]: %w", conversationID, err)
+	}
+
+	// write to a temp file then rename it, so that the file is never left partially written
+	f, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session[%s]: %w", conversationID, err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write session[%s]: %w", conversationID, err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write session[%s]: %w", conversationID, err)
+	}
+	if err = os.Rename(f.Name(), s.path(conversationID)); err != nil {
+		return fmt.Errorf("failed to write session[%s]: %w", conversationID, err)
+	}
+	return nil
+}
+
+// conversation is the conversation of a run, whose input messages from inputStart on are those of the turn,
+// following the history loaded from the SessionStore.
+type conversation struct {
+	id         string
+	inputStart int
+}
+
+type conversationKey struct{}
+
+// withConversation is called by every Runner, so that the nested Runners, e.g. those of agent tools, don't inherit the conversation.
+func withConversation(ctx context.Context, conv *conversation) context.Context {
+	return context.WithValue(ctx, conversationKey{}, conv)
+}
+
+func getConversation(ctx context.Context) *conversation {
+	conv, _ := ctx.Value(conversationKey{}).(*conversation)
+	return conv
+}
+
+// loadConversation loads the stored session of the conversation, returning the messages to run, i.e. the history followed by messages.
+func (r *Runner) loadConversation(ctx context.Context, conversationID string, messages []Message) (
+	context.Context, []Message, error) {
+	if r.sessionStore == nil || conversationID == "" {
+		return withConversation(ctx, nil), messages, nil
+	}
+
+	stored, existed, err := r.sessionStore.Get(ctx, conversationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load session of conversation[%s]: %w", conversationID, err)
+	}
+	if !existed {
+		return withConversation(ctx, &conversation{id: conversationID}), messages, nil
+	}
+
+	input := make([]Message, 0, len(stored.Messages)+len(messages))
+	input = append(input, stored.Messages...)
+	input = append(input, messages...)
+	AddSessionValues(ctx, stored.Values)
+	return withConversation(ctx, &conversation{id: conversationID, inputStart: len(stored.Messages)}), input, nil
+}
+
+// saveConversation appends the messages of the completed turn to the stored session of the conversation.
+func (r *Runner) saveConversation(ctx context.Context) error {
+	conv := getConversation(ctx)
+	runCtx := getRunCtx(ctx)
+	if r.sessionStore == nil || conv == nil || runCtx == nil {
+		return nil
+	}
+
+	var messages []Message
+	if runCtx.RootInput != nil && conv.inputStart <= len(runCtx.RootInput.Messages) {
+		messages = append(messages, runCtx.RootInput.Messages[conv.inputStart:]...)
+	}
+	for _, event := range runCtx.Session.getEvents() {
+		msg, err := getMessageFromWrappedEvent(event)
+		if err != nil {
+			var retryErr *WillRetryError
+			if errors.As(err, &retryErr) {
+				log.Printf("failed to get message from event, but will retry: %v", err)
+				continue
+			}
+			return err
+		}
+		if msg != nil {
+			messages = append(messages, msg)
+		}
+	}
+
+	if err := r.sessionStore.Append(ctx, conv.id, messages, runCtx.Session.getValues()); err != nil {
+		return fmt.Errorf("failed to save session of conversation[%s]: %w", conv.id, err)
+	}
+	return nil
+}
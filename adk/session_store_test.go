@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adk
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestSessionStore(t *testing.T) {
+	ctx := context.Background()
+
+	drain := func(iter *AsyncIterator[*AgentEvent]) (last *AgentEvent) {
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return last
+			}
+			last = event
+		}
+	}
+
+	t.Run("multi turn", func(t *testing.T) {
+		fileStore, err := NewFileSessionStore(t.TempDir())
+		require.NoError(t, err)
+
+		for name, store := range map[string]SessionStore{"in memory": NewInMemorySessionStore(), "file": fileStore} {
+			t.Run(name, func(t *testing.T) {
+				var inputs [][]*schema.Message
+				a, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+					Name:        "assistant",
+					Description: "assistant",
+					OutputKey:   "last_answer",
+					Model: &myModel{
+						validator: func(_ int, messages []*schema.Message) bool {
+							inputs = append(inputs, messages)
+							return true
+						},
+						messages: []*schema.Message{schema.AssistantMessage("hello", nil), schema.AssistantMessage("bye", nil)},
+					},
+				})
+				require.NoError(t, err)
+				runner := NewRunner(ctx, RunnerConfig{Agent: a, SessionStore: store})
+
+				last := drain(runner.Query(ctx, "hi", WithConversationID("user/1")))
+				require.NoError(t, last.Err)
+				last = drain(runner.Query(ctx, "see you", WithConversationID("user/1")))
+				require.NoError(t, last.Err)
+
+				require.Len(t, inputs, 2)
+				assert.Equal(t, []*schema.Message{
+					schema.UserMessage("hi"), schema.AssistantMessage("hello", nil), schema.UserMessage("see you"),
+				}, inputs[1])
+
+				stored, existed, err := store.Get(ctx, "user/1")
+				require.NoError(t, err)
+				require.True(t, existed)
+				assert.Equal(t, []Message{
+					schema.UserMessage("hi"), schema.AssistantMessage("hello", nil),
+					schema.UserMessage("see you"), schema.AssistantMessage("bye", nil),
+				}, stored.Messages)
+				assert.Equal(t, map[string]any{"last_answer": "bye"}, stored.Values)
+
+				_, existed, err = store.Get(ctx, "user/2")
+				require.NoError(t, err)
+				assert.False(t, existed)
+			})
+		}
+	})
+
+	t.Run("concurrent appends", func(t *testing.T) {
+		fileStore, err := NewFileSessionStore(t.TempDir())
+		require.NoError(t, err)
+
+		for name, store := range map[string]SessionStore{"in memory": NewInMemorySessionStore(), "file": fileStore} {
+			t.Run(name, func(t *testing.T) {
+				var wg sync.WaitGroup
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						assert.NoError(t, store.Append(ctx, "c", []Message{schema.UserMessage(strconv.Itoa(i))}, map[string]any{"last": i}))
+					}(i)
+				}
+				wg.Wait()
+
+				stored, existed, err := store.Get(ctx, "c")
+				require.NoError(t, err)
+				require.True(t, existed)
+				assert.Len(t, stored.Messages, 20)
+				assert.Len(t, stored.Values, 1)
+			})
+		}
+	})
+
+	t.Run("interrupt and resume", func(t *testing.T) {
+		a, err := NewChatModelAgent(ctx, &ChatModelAgentConfig{
+			Name:        "assistant",
+			Description: "assistant",
+			Model: &myModel{messages: []*schema.Message{
+				schema.AssistantMessage("", []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "tool1"}}}),
+				schema.AssistantMessage("done", nil),
+			}},
+			ToolsConfig: ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{Tools: []tool.BaseTool{&myTool1{}}}},
+		})
+		require.NoError(t, err)
+		store := NewInMemorySessionStore()
+		_ = store.Set(ctx, "c", &StoredSession{Messages: []Message{schema.UserMessage("earlier")}})
+		runner := NewRunner(ctx, RunnerConfig{Agent: a, SessionStore: store, CheckPointStore: newMyStore()})
+
+		last := drain(runner.Query(ctx, "run tool", WithConversationID("c"), WithCheckPointID("cp")))
+		require.NotNil(t, last.Action)
+		require.NotNil(t, last.Action.Interrupted)
+		stored, _, _ := store.Get(ctx, "c")
+		assert.Len(t, stored.Messages, 1)
+
+		iter, err := runner.ResumeWithParams(ctx, "cp", &ResumeParams{
+			Targets: map[string]any{last.Action.Interrupted.InterruptContexts[0].ID: "tool result"},
+		})
+		require.NoError(t, err)
+		last = drain(iter)
+		require.NoError(t, last.Err)
+
+		stored, _, _ = store.Get(ctx, "c")
+		require.Len(t, stored.Messages, 5)
+		assert.Equal(t, "earlier", stored.Messages[0].Content)
+		assert.Equal(t, "run tool", stored.Messages[1].Content)
+		assert.Len(t, stored.Messages[2].ToolCalls, 1)
+		assert.Equal(t, "tool result", stored.Messages[3].Content)
+		assert.Equal(t, "done", stored.Messages[4].Content)
+	})
+}
@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*LoaderCallbackInput]("_eino_loader_callback_input")
+	schema.RegisterName[*LoaderCallbackOutput]("_eino_loader_callback_output")
+}
+
 // LoaderCallbackInput is the input for the loader callback.
 type LoaderCallbackInput struct {
 	// Source is the source of the documents.
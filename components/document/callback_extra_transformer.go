@@ -21,6 +21,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*TransformerCallbackInput]("_eino_transformer_callback_input")
+	schema.RegisterName[*TransformerCallbackOutput]("_eino_transformer_callback_output")
+}
+
 // TransformerCallbackInput is the input for the transformer callback.
 type TransformerCallbackInput struct {
 	// Input is the input documents.
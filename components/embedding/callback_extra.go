@@ -19,8 +19,14 @@ package embedding
 
 import (
 	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_embedding_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_embedding_callback_output")
+}
+
 // TokenUsage is the token usage for the embedding.
 type TokenUsage struct {
 	// PromptTokens is the number of prompt tokens.
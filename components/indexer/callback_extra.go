@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_indexer_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_indexer_callback_output")
+}
+
 // CallbackInput is the input for the indexer callback.
 type CallbackInput struct {
 	// Docs is the documents to be indexed.
@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_model_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_model_callback_output")
+}
+
 // TokenUsage is the token usage for the model.
 type TokenUsage struct {
 	// PromptTokens is the number of prompt tokens, including all the input tokens of this request.
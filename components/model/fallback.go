@@ -0,0 +1,287 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// FallbackModel is a model in the fallback list of a FallbackChatModel.
+type FallbackModel struct {
+	// Model is the chat model to call.
+	Model ToolCallingChatModel
+	// Name identifies the model in callbacks, i.e. RunInfo.Name, the type of the model by default.
+	Name string
+	// IsRetryable decides whether a failure of the model moves on to the next model.
+	// If nil, all errors move on. If it returns false, the error is returned immediately.
+	// A failure after the context is canceled or timed out never moves on.
+	IsRetryable func(ctx context.Context, err error) bool
+	// TransformOptions transforms the options passed to the model, e.g. to replace the model name of another provider.
+	TransformOptions func(ctx context.Context, opts []Option) []Option
+}
+
+// FallbackChatModelConfig is the config of NewFallbackChatModel.
+type FallbackChatModelConfig struct {
+	// Models are tried in order, until one of them succeeds.
+	Models []*FallbackModel
+}
+
+// FallbackError is returned when all the models of a FallbackChatModel fail.
+type FallbackError struct {
+	// Errors are the errors of the models, in the order they are tried.
+	Errors []error
+}
+
+func (e *FallbackError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString("all fallback models failed")
+	for i, err := range e.Errors {
+		sb.WriteString(fmt.Sprintf("\n[%d] %v", i, err))
+	}
+	return sb.String()
+}
+
+// Unwrap returns the error of the last model.
+func (e *FallbackError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+// FallbackChatModel is a ToolCallingChatModel trying an ordered list of models, for failover across model instances or providers.
+// On a retryable failure of a model it moves on to the next one, in stream mode a failure is either
+// the error returned by Stream or the error of receiving the first chunk, the errors after the first chunk are returned as they are.
+//
+// Each tried model is reported to callbacks as a ChatModel run named by FallbackModel.Name,
+// so the failed attempts and the selected model are visible, e.g. by RunInfo.Name in OnEnd.
+type FallbackChatModel struct {
+	models []*fallbackModel
+}
+
+type fallbackModel struct {
+	*FallbackModel
+	typ              string
+	handlesCallbacks bool
+}
+
+func newFallbackModel(fm *FallbackModel) *fallbackModel {
+	typ, ok := components.GetType(fm.Model)
+	if !ok {
+		typ = generic.ParseTypeName(reflect.ValueOf(fm.Model))
+	}
+	return &fallbackModel{
+		FallbackModel:    fm,
+		typ:              typ,
+		handlesCallbacks: components.IsCallbacksEnabled(fm.Model),
+	}
+}
+
+// NewFallbackChatModel creates a FallbackChatModel.
+// e.g.
+//
+//	cm, err := model.NewFallbackChatModel(ctx, &model.FallbackChatModelConfig{
+//		Models: []*model.FallbackModel{
+//			{Model: primary, Name: "primary", IsRetryable: isRateLimited},
+//			{Model: backup, Name: "backup"},
+//		},
+//	})
+func NewFallbackChatModel(_ context.Context, config *FallbackChatModelConfig) (*FallbackChatModel, error) {
+	if config == nil || len(config.Models) == 0 {
+		return nil, errors.New("fallback chat model requires at least one model")
+	}
+	m := &FallbackChatModel{models: make([]*fallbackModel, 0, len(config.Models))}
+	for i, fm := range config.Models {
+		if fm == nil || fm.Model == nil {
+			return nil, fmt.Errorf("fallback model[%d] is nil", i)
+		}
+		m.models = append(m.models, newFallbackModel(fm))
+	}
+	return m, nil
+}
+
+// WithTools binds the tools to every model.
+func (m *FallbackChatModel) WithTools(tools []*schema.ToolInfo) (ToolCallingChatModel, error) {
+	nm := &FallbackChatModel{models: make([]*fallbackModel, 0, len(m.models))}
+	for _, fm := range m.models {
+		model, err := fm.Model.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("bind tools to fallback model[%s] fail: %w", fm.name(), err)
+		}
+		nfm := *fm.FallbackModel
+		nfm.Model = model
+		nm.models = append(nm.models, newFallbackModel(&nfm))
+	}
+	return nm, nil
+}
+
+func (m *FallbackChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...Option) (*schema.Message, error) {
+	var errs []error
+	for _, fm := range m.models {
+		out, err := fm.generate(ctx, input, opts)
+		if err == nil {
+			return out, nil
+		}
+		if ctx.Err() != nil || !fm.isRetryable(ctx, err) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	return nil, &FallbackError{Errors: errs}
+}
+
+func (m *FallbackChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...Option) (*schema.StreamReader[*schema.Message], error) {
+	var errs []error
+	for _, fm := range m.models {
+		out, err := fm.stream(ctx, input, opts)
+		if err == nil {
+			return out, nil
+		}
+		if ctx.Err() != nil || !fm.isRetryable(ctx, err) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	return nil, &FallbackError{Errors: errs}
+}
+
+func (m *FallbackChatModel) GetType() string {
+	return "Fallback"
+}
+
+func (m *FallbackChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func (fm *fallbackModel) name() string {
+	if fm.Name != "" {
+		return fm.Name
+	}
+	return fm.typ
+}
+
+func (fm *fallbackModel) isRetryable(ctx context.Context, err error) bool {
+	return fm.IsRetryable == nil || fm.IsRetryable(ctx, err)
+}
+
+func (fm *fallbackModel) prepare(ctx context.Context, opts []Option) (context.Context, []Option) {
+	if fm.TransformOptions != nil {
+		opts = fm.TransformOptions(ctx, opts)
+	}
+	ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{
+		Name:      fm.name(),
+		Type:      fm.typ,
+		Component: components.ComponentOfChatModel,
+	})
+	return ctx, opts
+}
+
+func (fm *fallbackModel) generate(ctx context.Context, input []*schema.Message, opts []Option) (*schema.Message, error) {
+	ctx, opts = fm.prepare(ctx, opts)
+	if fm.handlesCallbacks {
+		return fm.Model.Generate(ctx, input, opts...)
+	}
+
+	ctx = callbacks.OnStart(ctx, &CallbackInput{Messages: input})
+	out, err := fm.Model.Generate(ctx, input, opts...)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+	callbacks.OnEnd(ctx, &CallbackOutput{Message: out})
+	return out, nil
+}
+
+func (fm *fallbackModel) stream(ctx context.Context, input []*schema.Message, opts []Option) (*schema.StreamReader[*schema.Message], error) {
+	ctx, opts = fm.prepare(ctx, opts)
+	if !fm.handlesCallbacks {
+		ctx = callbacks.OnStart(ctx, &CallbackInput{Messages: input})
+	}
+
+	sr, err := fm.Model.Stream(ctx, input, opts...)
+	if err == nil {
+		sr, err = peekFirstChunk(sr)
+	}
+	if err != nil {
+		if !fm.handlesCallbacks {
+			callbacks.OnError(ctx, err)
+		}
+		return nil, err
+	}
+	if fm.handlesCallbacks {
+		return sr, nil
+	}
+
+	cbSR := schema.StreamReaderWithConvert(sr, func(m *schema.Message) (*CallbackOutput, error) {
+		return &CallbackOutput{Message: m}, nil
+	})
+	_, cbSR = callbacks.OnEndWithStreamOutput(ctx, cbSR)
+	return schema.StreamReaderWithConvert(cbSR, func(o *CallbackOutput) (*schema.Message, error) {
+		return o.Message, nil
+	}), nil
+}
+
+// peekFirstChunk receives the first chunk of the stream, and returns a stream starting with it,
+// or the error of receiving it.
+func peekFirstChunk(sr *schema.StreamReader[*schema.Message]) (*schema.StreamReader[*schema.Message], error) {
+	first, err := sr.Recv()
+	if err == io.EOF {
+		sr.Close()
+		return schema.StreamReaderFromArray([]*schema.Message{}), nil
+	}
+	if err != nil {
+		sr.Close()
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				sw.Send(nil, safe.NewPanicErr(p, debug.Stack()))
+			}
+			sr.Close()
+			sw.Close()
+		}()
+
+		if closed := sw.Send(first, nil); closed {
+			return
+		}
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
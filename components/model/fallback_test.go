@@ -0,0 +1,207 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+type fallbackTestModel struct {
+	reply     string
+	err       error
+	streamErr error // returned when receiving the first chunk
+	tools     []*schema.ToolInfo
+	modelName string
+}
+
+func (m *fallbackTestModel) Generate(_ context.Context, _ []*schema.Message, opts ...Option) (*schema.Message, error) {
+	if o := GetCommonOptions(nil, opts...); o.Model != nil {
+		m.modelName = *o.Model
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(m.reply, nil), nil
+}
+
+func (m *fallbackTestModel) Stream(_ context.Context, _ []*schema.Message, _ ...Option) (*schema.StreamReader[*schema.Message], error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	sr, sw := schema.Pipe[*schema.Message](2)
+	if m.streamErr != nil {
+		sw.Send(nil, m.streamErr)
+	} else {
+		sw.Send(schema.AssistantMessage(m.reply, nil), nil)
+		sw.Send(schema.AssistantMessage("!", nil), nil)
+	}
+	sw.Close()
+	return sr, nil
+}
+
+func (m *fallbackTestModel) WithTools(tools []*schema.ToolInfo) (ToolCallingChatModel, error) {
+	nm := *m
+	nm.tools = tools
+	return &nm, nil
+}
+
+type fallbackCallbackRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *fallbackCallbackRecorder) handler() callbacks.Handler {
+	record := func(info *callbacks.RunInfo, event string) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, info.Name+":"+event)
+	}
+	return callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			record(info, "end")
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			output.Close()
+			record(info, "end")
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			record(info, "error")
+			return ctx
+		}).Build()
+}
+
+func TestFallbackChatModel(t *testing.T) {
+	ctx := context.Background()
+	errUnavailable := errors.New("unavailable")
+
+	t.Run("generate", func(t *testing.T) {
+		backup := &fallbackTestModel{reply: "backup"}
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{err: errUnavailable}, Name: "primary"},
+			{Model: backup, Name: "backup", TransformOptions: func(ctx context.Context, opts []Option) []Option {
+				return append(opts, WithModel("backup-model"))
+			}},
+		}})
+		assert.NoError(t, err)
+
+		rec := &fallbackCallbackRecorder{}
+		out, err := cm.Generate(callbacks.InitCallbacks(ctx, &callbacks.RunInfo{}, rec.handler()), nil, WithModel("primary-model"))
+		assert.NoError(t, err)
+		assert.Equal(t, "backup", out.Content)
+		assert.Equal(t, "backup-model", backup.modelName)
+		assert.Equal(t, []string{"primary:error", "backup:end"}, rec.events)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		errBadRequest := errors.New("bad request")
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{err: errBadRequest}, IsRetryable: func(ctx context.Context, err error) bool {
+				return !errors.Is(err, errBadRequest)
+			}},
+			{Model: &fallbackTestModel{reply: "backup"}},
+		}})
+		assert.NoError(t, err)
+		_, err = cm.Generate(ctx, nil)
+		assert.Equal(t, errBadRequest, err)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{err: context.Canceled}, Name: "primary"},
+			{Model: &fallbackTestModel{reply: "backup"}, Name: "backup"},
+		}})
+		assert.NoError(t, err)
+
+		rec := &fallbackCallbackRecorder{}
+		cctx = callbacks.InitCallbacks(cctx, &callbacks.RunInfo{}, rec.handler())
+		_, err = cm.Generate(cctx, nil)
+		assert.Equal(t, context.Canceled, err)
+		_, err = cm.Stream(cctx, nil)
+		assert.Equal(t, context.Canceled, err)
+		// the backup is never tried
+		assert.Equal(t, []string{"primary:error", "primary:error"}, rec.events)
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{err: errUnavailable}},
+			{Model: &fallbackTestModel{streamErr: errUnavailable}},
+		}})
+		assert.NoError(t, err)
+		_, err = cm.Stream(ctx, nil)
+		var fallbackErr *FallbackError
+		assert.True(t, errors.As(err, &fallbackErr))
+		assert.Len(t, fallbackErr.Errors, 2)
+		assert.True(t, errors.Is(err, errUnavailable))
+	})
+
+	t.Run("stream first chunk failure", func(t *testing.T) {
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{streamErr: errUnavailable}, Name: "primary"},
+			{Model: &fallbackTestModel{reply: "backup"}, Name: "backup"},
+		}})
+		assert.NoError(t, err)
+
+		rec := &fallbackCallbackRecorder{}
+		sr, err := cm.Stream(callbacks.InitCallbacks(ctx, &callbacks.RunInfo{}, rec.handler()), nil)
+		assert.NoError(t, err)
+		var chunks []string
+		for {
+			chunk, rErr := sr.Recv()
+			if rErr != nil {
+				break
+			}
+			chunks = append(chunks, chunk.Content)
+		}
+		assert.Equal(t, []string{"backup", "!"}, chunks)
+		assert.Equal(t, []string{"primary:error", "backup:end"}, rec.events)
+	})
+
+	t.Run("with tools", func(t *testing.T) {
+		cm, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{Models: []*FallbackModel{
+			{Model: &fallbackTestModel{}},
+			{Model: &fallbackTestModel{}},
+		}})
+		assert.NoError(t, err)
+		tools := []*schema.ToolInfo{{Name: "search"}}
+		withTools, err := cm.WithTools(tools)
+		assert.NoError(t, err)
+		for _, fm := range withTools.(*FallbackChatModel).models {
+			assert.Equal(t, tools, fm.Model.(*fallbackTestModel).tools)
+		}
+		for _, fm := range cm.models {
+			assert.Nil(t, fm.Model.(*fallbackTestModel).tools)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewFallbackChatModel(ctx, &FallbackChatModelConfig{})
+		assert.Error(t, err)
+	})
+}
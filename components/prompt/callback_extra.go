@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_prompt_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_prompt_callback_output")
+}
+
 // CallbackInput is the input for the callback.
 type CallbackInput struct {
 	// Variables is the variables for the callback.
@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_retriever_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_retriever_callback_output")
+}
+
 // CallbackInput is the input for the retriever callback.
 type CallbackInput struct {
 	// Query is the query for the retriever.
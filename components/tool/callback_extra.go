@@ -22,6 +22,11 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+func init() {
+	schema.RegisterName[*CallbackInput]("_eino_tool_callback_input")
+	schema.RegisterName[*CallbackOutput]("_eino_tool_callback_output")
+}
+
 // CallbackInput is the input for the tool callback.
 type CallbackInput struct {
 	// ArgumentsInJSON is the arguments in json format for the tool.
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/internal/serialization"
+	"github.com/cloudwego/eino/schema"
+)
+
+// NodeCache stores the outputs of graph nodes, see CachePolicy.
+// Values are the outputs of nodes as they are, in stream mode the concatenated outputs.
+// A cached value is passed to downstream nodes on every hit, so it must not be modified by them.
+type NodeCache interface {
+	Get(ctx context.Context, key string) (value any, ok bool, err error)
+	Set(ctx context.Context, key string, value any) error
+}
+
+// CachePolicy configures the cache of a graph node, attached through WithCachePolicy.
+type CachePolicy struct {
+	// Cache stores the outputs, it can be shared among nodes and runs.
+	// The outputs are keyed by the node path from the root graph, which doesn't tell one graph from another,
+	// so the graphs sharing a cache must have distinct Namespaces.
+	Cache NodeCache
+
+	// Namespace is part of the cache key, separating the outputs of different graphs sharing Cache,
+	// e.g. the name of the graph, or a version changing with the runnable of the node.
+	// optional, empty by default
+	Namespace string
+
+	// OptionsKey derives the part of the cache key from the call options of the node, e.g. the model name of an embedding option.
+	// Returning false means the options affect the output in a way that can't be keyed, and the node is executed without cache.
+	// If nil, the node is cached only when it's called without options.
+	OptionsKey func(ctx context.Context, opts []any) (string, bool)
+}
+
+// cacheKey is derived from the namespace, the node path, the serialized input and the options key.
+// Inputs whose types can't be serialized, see schema.RegisterName, are not cached.
+func (p *CachePolicy) cacheKey(ctx context.Context, input any, opts []any) (string, bool) {
+	path, ok := getNodePath(ctx)
+	if !ok {
+		return "", false
+	}
+
+	var optionsKey string
+	if len(opts) > 0 {
+		if p.OptionsKey == nil {
+			return "", false
+		}
+		if optionsKey, ok = p.OptionsKey(ctx, opts); !ok {
+			return "", false
+		}
+	}
+
+	data, err := (&serialization.InternalSerializer{}).MarshalCanonical(input)
+	if err != nil {
+		return "", false
+	}
+
+	h := sha256.New()
+	h.Write([]byte(p.Namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(path.GetPath(), "\x1F")))
+	h.Write([]byte{0})
+	h.Write(data)
+	h.Write([]byte{0})
+	h.Write([]byte(optionsKey))
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+func (p *CachePolicy) get(ctx context.Context, key string) (any, bool) {
+	value, ok, err := p.Cache.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	return value, ok
+}
+
+// markCacheHit sets RunInfo.CacheHit of the node, whose RunInfo is created for every execution.
+func markCacheHit(ctx context.Context) {
+	if ri, ok := ctx.Value(nodeRunInfoKey{}).(*callbacks.RunInfo); ok {
+		ri.CacheHit = true
+	}
+}
+
+// cachedComposableRunnable wraps the node's runnable to serve the output from the cache.
+// Errors of the cache are ignored, the node is executed as if it's not cached.
+func cachedComposableRunnable(p *CachePolicy, r *composableRunnable) *composableRunnable {
+	if p == nil || p.Cache == nil {
+		return r
+	}
+
+	wrapper := *r
+	helper := r.genericHelper
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (any, error) {
+		key, ok := p.cacheKey(ctx, input, opts)
+		if !ok {
+			return i(ctx, input, opts...)
+		}
+
+		if value, hit := p.get(ctx, key); hit {
+			markCacheHit(ctx)
+			ctx = callbacks.OnStart(ctx, input)
+			callbacks.OnEnd(ctx, value)
+			return value, nil
+		}
+
+		output, err := i(ctx, input, opts...)
+		if err != nil {
+			return nil, err
+		}
+		_ = p.Cache.Set(ctx, key, output)
+		return output, nil
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (streamReader, error) {
+		inputs := input.copy(2)
+		inputValue, err := helper.inputStreamConvertPair.concatStream(inputs[0])
+		if err != nil {
+			return t(ctx, inputs[1], opts...)
+		}
+		key, ok := p.cacheKey(ctx, inputValue, opts)
+		if !ok {
+			return t(ctx, inputs[1], opts...)
+		}
+
+		if value, hit := p.get(ctx, key); hit {
+			output, err := helper.outputStreamConvertPair.restoreStream(value)
+			if err == nil {
+				inputs[1].close()
+				markCacheHit(ctx)
+				var cbSR *schema.StreamReader[any]
+				ctx, cbSR = callbacks.OnStartWithStreamInput(ctx, schema.StreamReaderFromArray([]any{inputValue}))
+				cbSR.Close()
+				_, cbSR = callbacks.OnEndWithStreamOutput(ctx, schema.StreamReaderFromArray([]any{value}))
+				cbSR.Close()
+				return output, nil
+			}
+		}
+
+		output, err := t(ctx, inputs[1], opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := output.copy(2)
+		go func() {
+			value, err := helper.outputStreamConvertPair.concatStream(outputs[1])
+			if err == nil {
+				_ = p.Cache.Set(ctx, key, value)
+			}
+		}()
+		return outputs[0], nil
+	}
+
+	return &wrapper
+}
+
+// LRUNodeCache is an in-memory NodeCache evicting the least recently used values when it's full.
+type LRUNodeCache struct {
+	capacity int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value any
+}
+
+// NewLRUNodeCache creates an LRUNodeCache holding at most capacity values.
+func NewLRUNodeCache(capacity int) *LRUNodeCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUNodeCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements NodeCache.
+func (c *LRUNodeCache) Get(_ context.Context, key string) (any, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true, nil
+}
+
+// Set implements NodeCache.
+func (c *LRUNodeCache) Set(_ context.Context, key string, value any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.ll.MoveToFront(e)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of values in the cache.
+func (c *LRUNodeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestCachePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invoke", func(t *testing.T) {
+		calls := 0
+		cache := NewLRUNodeCache(10)
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambdaWithOption(func(ctx context.Context, input string, opts ...string) (string, error) {
+			calls++
+			return input + "_out", nil
+		}), WithCachePolicy(&CachePolicy{Cache: cache})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		var mu sync.Mutex
+		var hits []bool
+		cb := callbacks.NewHandlerBuilder().OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if info.Component == ComponentOfLambda {
+				mu.Lock()
+				hits = append(hits, info.CacheHit)
+				mu.Unlock()
+			}
+			return ctx
+		}).Build()
+
+		for _, in := range []string{"a", "a", "b"} {
+			out, err := r.Invoke(ctx, in, WithCallbacks(cb))
+			assert.NoError(t, err)
+			assert.Equal(t, in+"_out", out)
+		}
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, []bool{false, true, false}, hits)
+		assert.Equal(t, 2, cache.Len())
+
+		// called with options but no OptionsKey, not cached
+		_, err = r.Invoke(ctx, "a", WithLambdaOption("opt").DesignateNode("1"))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("options key", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambdaWithOption(func(ctx context.Context, input string, opts ...string) (string, error) {
+			calls++
+			return input, nil
+		}), WithCachePolicy(&CachePolicy{
+			Cache: NewLRUNodeCache(10),
+			OptionsKey: func(ctx context.Context, opts []any) (string, bool) {
+				return opts[0].(string), true
+			},
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		for _, opt := range []string{"x", "x", "y"} {
+			_, err = r.Invoke(ctx, "a", WithLambdaOption(opt).DesignateNode("1"))
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		calls := 0
+		cache := NewLRUNodeCache(10)
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+			calls++
+			return schema.StreamReaderFromArray([]string{input, "_", "out"}), nil
+		}), WithCachePolicy(&CachePolicy{Cache: cache})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		sr, err := r.Stream(ctx, "a")
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "a_out", out)
+
+		// the output is concatenated and cached asynchronously
+		assert.Eventually(t, func() bool { return cache.Len() == 1 }, time.Second, 10*time.Millisecond)
+
+		sr, err = r.Stream(ctx, "a")
+		assert.NoError(t, err)
+		chunk, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "a_out", chunk)
+		assert.Equal(t, 1, calls)
+
+		// the cache is shared by invoke
+		out, err = r.Invoke(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "a_out", out)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("map input", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[map[string]any, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input map[string]any) (string, error) {
+			calls++
+			return "out", nil
+		}), WithCachePolicy(&CachePolicy{Cache: NewLRUNodeCache(10)})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		// equal maps built in different orders share the cache key
+		forward, backward := map[string]any{}, map[string]any{}
+		for i := 0; i < 20; i++ {
+			forward[strconv.Itoa(i)] = map[string]any{"a": i, "b": strconv.Itoa(i)}
+			backward[strconv.Itoa(19-i)] = map[string]any{"b": strconv.Itoa(19 - i), "a": 19 - i}
+		}
+		for _, in := range []map[string]any{forward, backward, forward, backward} {
+			_, err = r.Invoke(ctx, in)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		cache := NewLRUNodeCache(10)
+		newRunnable := func(namespace, suffix string) Runnable[string, string] {
+			g := NewGraph[string, string]()
+			assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+				return input + suffix, nil
+			}), WithCachePolicy(&CachePolicy{Cache: cache, Namespace: namespace})))
+			assert.NoError(t, g.AddEdge(START, "1"))
+			assert.NoError(t, g.AddEdge("1", END))
+			r, err := g.Compile(ctx)
+			assert.NoError(t, err)
+			return r
+		}
+
+		// graphs sharing the cache with the same node path are separated by namespaces
+		out, err := newRunnable("x", "_x").Invoke(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "a_x", out)
+		out, err = newRunnable("y", "_y").Invoke(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "a_y", out)
+		out, err = newRunnable("x", "_z").Invoke(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "a_x", out)
+		assert.Equal(t, 2, cache.Len())
+	})
+}
+
+func TestLRUNodeCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUNodeCache(2)
+	assert.NoError(t, c.Set(ctx, "a", 1))
+	assert.NoError(t, c.Set(ctx, "b", 2))
+	_, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.NoError(t, c.Set(ctx, "c", 3))
+
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+	v, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, ok, _ = c.Get(ctx, "c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, 2, c.Len())
+}
@@ -114,6 +114,9 @@ type checkpoint struct {
 
 	InterruptID2Addr  map[string]Address
 	InterruptID2State map[string]core.InterruptState
+	// only available in the checkpoint of the outermost graph
+	InterruptID2Point      map[string]*core.InterruptPoint
+	InterruptID2ResumeData map[string]any
 }
 
 type stateModifierKey struct{}
@@ -143,6 +146,7 @@ func getCheckPointFromStore(ctx context.Context, id string, cpr *checkPointer) (
 }
 
 func setCheckPointToCtx(ctx context.Context, cp *checkpoint) context.Context {
+	ctx = core.PopulateResumeData(ctx, cp.InterruptID2ResumeData)
 	ctx = core.PopulateInterruptState(ctx, cp.InterruptID2Addr, cp.InterruptID2State)
 	return context.WithValue(ctx, checkPointKey{}, cp)
 }
@@ -199,6 +203,11 @@ func (c *checkPointer) get(ctx context.Context, id string) (*checkpoint, bool, e
 	if err != nil {
 		return nil, false, err
 	}
+	// upgrade the values of old versions, see schema.RegisterMigration
+	err = serialization.Migrate(cp)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to migrate checkpoint: %w", err)
+	}
 
 	return cp, true, nil
 }
@@ -209,7 +218,21 @@ func (c *checkPointer) set(ctx context.Context, id string, cp *checkpoint) error
 		return err
 	}
 
-	return c.store.Set(ctx, id, data)
+	err = c.store.Set(ctx, id, data)
+	if err != nil {
+		return err
+	}
+
+	if h := getCheckPointHistory(ctx); h != nil {
+		return h.append(ctx, c.store, id, data, cp, true)
+	}
+	return nil
+}
+
+// canSerialize returns whether the value can be serialized by itself, e.g. an interrupt info.
+func (c *checkPointer) canSerialize(v any) bool {
+	_, err := c.serializer.Marshal(v)
+	return err == nil
 }
 
 // convertCheckPoint if value in checkpoint is streamReader, convert it to non-stream
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cloudwego/eino/internal/core"
+)
+
+// CheckPointVersion describes a historical version of a checkpoint, see WithCheckPointHistory.
+type CheckPointVersion = core.CheckPointVersion
+
+// WithCheckPointHistory makes the graph write a new version of the checkpoint before every super step,
+// as well as on interrupt, in addition to overwriting the checkpoint of the write-to checkpoint ID.
+// The versions can be listed by GetCheckPointHistory, inspected by InspectCheckPoint,
+// and resumed from by passing CheckPointVersion.CheckPointID to WithCheckPointID.
+// Versions are read-only, so resuming from a version requires WithWriteToCheckPointID, which forks a new branch, e.g.
+//
+//	history, _ := compose.GetCheckPointHistory(ctx, store, "run-1")
+//	out, err := runnable.Invoke(ctx, input,
+//		compose.WithCheckPointID(history[2].CheckPointID),
+//		compose.WithWriteToCheckPointID("run-1-retry"),
+//		compose.WithStateModifier(fixState),
+//		compose.WithCheckPointHistory())
+//
+// Only the outermost graph writes versions, and the super steps of a run in stream mode aren't versioned
+// since the streams between nodes can't be saved without waiting for them.
+// The first super step of a run resumed from a checkpoint isn't versioned either, which is the checkpoint itself.
+func WithCheckPointHistory() Option {
+	return Option{
+		checkPointHistory: true,
+	}
+}
+
+// CheckPointVersionID returns the checkpoint ID the version of the checkpoint is stored at.
+func CheckPointVersionID(checkPointID string, version int) string {
+	return core.CheckPointVersionID(checkPointID, version)
+}
+
+// GetCheckPointHistory returns the versions of the checkpoint written with WithCheckPointHistory, ordered by version.
+func GetCheckPointHistory(ctx context.Context, store CheckPointStore, checkPointID string) ([]*CheckPointVersion, error) {
+	return core.GetCheckPointHistory(ctx, store, checkPointID)
+}
+
+// CheckPointSnapshot is the content of a checkpoint of a graph, values of streams are concatenated.
+type CheckPointSnapshot struct {
+	// State is the local state of the graph, nil if the graph has no state.
+	State any
+	// NodeInputs are the inputs of the nodes to run when resuming from the checkpoint.
+	NodeInputs map[string]any
+	// RerunNodes are the interrupted nodes to rerun, whose inputs are zero values if not in NodeInputs.
+	RerunNodes []string
+	// ChannelValues are the values written to the nodes that haven't been triggered, by node key and then predecessor key.
+	ChannelValues map[string]map[string]any
+	// SubGraphs are the checkpoints of the interrupted subgraphs, by node key.
+	SubGraphs map[string]*CheckPointSnapshot
+}
+
+// InspectCheckPoint loads the checkpoint from the store and returns its content.
+// serializer should be the one set by WithSerializer, nil for the default one.
+func InspectCheckPoint(ctx context.Context, store CheckPointStore, serializer Serializer, checkPointID string) (*CheckPointSnapshot, error) {
+	cp, err := loadCheckPoint(ctx, store, serializer, checkPointID)
+	if err != nil {
+		return nil, err
+	}
+	return newCheckPointSnapshot(cp)
+}
+
+func newCheckPointSnapshot(cp *checkpoint) (*CheckPointSnapshot, error) {
+	s := &CheckPointSnapshot{
+		State:         cp.State,
+		NodeInputs:    cp.Inputs,
+		RerunNodes:    cp.RerunNodes,
+		ChannelValues: make(map[string]map[string]any),
+	}
+	for key, ch := range cp.Channels {
+		err := ch.convertValues(func(m map[string]any) error {
+			if len(m) == 0 {
+				return nil
+			}
+			values := make(map[string]any, len(m))
+			for from, v := range m {
+				values[from] = v
+			}
+			s.ChannelValues[key] = values
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cp.SubGraphs) > 0 {
+		s.SubGraphs = make(map[string]*CheckPointSnapshot, len(cp.SubGraphs))
+		for key, sub := range cp.SubGraphs {
+			subSnapshot, err := newCheckPointSnapshot(sub)
+			if err != nil {
+				return nil, err
+			}
+			s.SubGraphs[key] = subSnapshot
+		}
+	}
+	return s, nil
+}
+
+type checkPointHistoryKey struct{}
+
+// checkPointHistory records the versions of the checkpoint of a run.
+type checkPointHistory struct {
+	forkedFrom string
+	step       int
+}
+
+func withCheckPointHistory(ctx context.Context, h *checkPointHistory) context.Context {
+	return context.WithValue(ctx, checkPointHistoryKey{}, h)
+}
+
+func getCheckPointHistory(ctx context.Context) *checkPointHistory {
+	if h, ok := ctx.Value(checkPointHistoryKey{}).(*checkPointHistory); ok {
+		return h
+	}
+	return nil
+}
+
+func (h *checkPointHistory) append(ctx context.Context, store CheckPointStore, checkPointID string, data []byte, cp *checkpoint, interrupted bool) error {
+	nextNodes := make([]string, 0, len(cp.Inputs)+len(cp.RerunNodes))
+	for key := range cp.Inputs {
+		nextNodes = append(nextNodes, key)
+	}
+	for _, key := range cp.RerunNodes {
+		if _, ok := cp.Inputs[key]; !ok {
+			nextNodes = append(nextNodes, key)
+		}
+	}
+	sort.Strings(nextNodes)
+
+	return core.AppendCheckPointVersion(ctx, store, checkPointID, data, &CheckPointVersion{
+		Step:        h.step,
+		NextNodes:   nextNodes,
+		Interrupted: interrupted,
+		ForkedFrom:  h.forkedFrom,
+	})
+}
+
+// saveStepVersion writes the version of the checkpoint before running the tasks of the super step.
+func (r *runner) saveStepVersion(ctx context.Context, h *checkPointHistory, checkPointID string, nextTasks []*task, cm *channelManager) error {
+	cp := &checkpoint{
+		Channels:       cm.channels,
+		Inputs:         make(map[string]any, len(nextTasks)),
+		SkipPreHandler: map[string]bool{},
+	}
+	if r.runCtx != nil {
+		if state, ok := ctx.Value(stateKey{}).(*internalState); ok {
+			cp.State = state.state
+		}
+	}
+	for _, t := range nextTasks {
+		cp.Inputs[t.nodeKey] = t.input
+		if t.skipPreHandler {
+			cp.SkipPreHandler[t.nodeKey] = true
+		}
+	}
+
+	data, err := r.checkPointer.serializer.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return h.append(ctx, r.checkPointer.store, checkPointID, data, cp, false)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPointHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newInMemoryStore()
+
+	g := NewGraph[string, string](WithGenLocalState(func(ctx context.Context) *testStruct {
+		return &testStruct{}
+	}))
+	for _, key := range []string{"1", "2", "3"} {
+		key := key
+		assert.NoError(t, g.AddLambdaNode(key, InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return in + key, nil
+		}), WithStatePreHandler(func(ctx context.Context, in string, state *testStruct) (string, error) {
+			return in + state.A, nil
+		})))
+	}
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", "2"))
+	assert.NoError(t, g.AddEdge("2", "3"))
+	assert.NoError(t, g.AddEdge("3", END))
+	r, err := g.Compile(ctx, WithCheckPointStore(store), WithInterruptBeforeNodes([]string{"3"}))
+	assert.NoError(t, err)
+
+	_, err = r.Invoke(ctx, "start", WithCheckPointID("run"), WithCheckPointHistory())
+	_, ok := ExtractInterruptInfo(err)
+	assert.True(t, ok)
+
+	history, err := GetCheckPointHistory(ctx, store, "run")
+	assert.NoError(t, err)
+	if !assert.Len(t, history, 3) {
+		return
+	}
+	assert.Equal(t, CheckPointVersionID("run", 2), history[1].CheckPointID)
+	assert.Equal(t, []string{"1"}, history[0].NextNodes)
+	assert.Equal(t, 1, history[1].Step)
+	assert.Equal(t, []string{"2"}, history[1].NextNodes)
+	assert.False(t, history[1].Interrupted)
+	assert.Equal(t, []string{"3"}, history[2].NextNodes)
+	assert.True(t, history[2].Interrupted)
+
+	snapshot, err := InspectCheckPoint(ctx, store, nil, history[1].CheckPointID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"2": "start1"}, snapshot.NodeInputs)
+	assert.Equal(t, &testStruct{}, snapshot.State)
+
+	// versions are read-only
+	_, err = r.Invoke(ctx, "", WithCheckPointID(history[1].CheckPointID))
+	assert.Error(t, err)
+
+	// fork from the version before node 2 with a modified state
+	_, err = r.Invoke(ctx, "", WithCheckPointID(history[1].CheckPointID), WithWriteToCheckPointID("fork"),
+		WithStateModifier(func(ctx context.Context, path NodePath, state any) error {
+			state.(*testStruct).A = "!"
+			return nil
+		}), WithCheckPointHistory())
+	_, ok = ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	forkHistory, err := GetCheckPointHistory(ctx, store, "fork")
+	assert.NoError(t, err)
+	if assert.Len(t, forkHistory, 1) {
+		assert.Equal(t, history[1].CheckPointID, forkHistory[0].ForkedFrom)
+	}
+	out, err := r.Invoke(ctx, "", WithCheckPointID("fork"))
+	assert.NoError(t, err)
+	assert.Equal(t, "start1!2!3", out)
+
+	out, err = r.Invoke(ctx, "", WithCheckPointID("run"))
+	assert.NoError(t, err)
+	assert.Equal(t, "start123", out)
+
+	// IDs of users looking like versions are plain checkpoint IDs, with or without history
+	_, err = r.Invoke(ctx, "x", WithCheckPointID("x@v1"))
+	_, ok = ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	out, err = r.Invoke(ctx, "", WithCheckPointID("x@v1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x123", out)
+	_, err = r.Invoke(ctx, "y", WithCheckPointID("y@v1"), WithCheckPointHistory())
+	_, ok = ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	out, err = r.Invoke(ctx, "", WithCheckPointID("y@v1"), WithCheckPointHistory())
+	assert.NoError(t, err)
+	assert.Equal(t, "y123", out)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -2002,3 +2003,60 @@ func (f *checkpointTestTool[I, O]) InvokableRun(ctx context.Context, argumentsIn
 	}
 	return sonic.MarshalString(o)
 }
+
+type migrationStateV1 struct {
+	Tags string
+}
+
+type migrationStateV2 struct {
+	Tags []string
+}
+
+func init() {
+	schema.RegisterNameWithVersion[*migrationStateV2]("_compose_test_migration_state", 2)
+	schema.RegisterMigration[*migrationStateV1, *migrationStateV2]("_compose_test_migration_state", 1,
+		func(s *migrationStateV1) (*migrationStateV2, error) {
+			return &migrationStateV2{Tags: strings.Split(s.Tags, ",")}, nil
+		})
+}
+
+func TestCheckPointMigration(t *testing.T) {
+	ctx := context.Background()
+	store := newInMemoryStore()
+
+	newGraph := func(genState func(ctx context.Context) any, preHandler func(ctx context.Context, in string, state any) (string, error)) Runnable[string, string] {
+		g := NewGraph[string, string](WithGenLocalState(genState))
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return in, nil
+		})))
+		assert.NoError(t, g.AddLambdaNode("2", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return in, nil
+		}), WithStatePreHandler(preHandler)))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", "2"))
+		assert.NoError(t, g.AddEdge("2", END))
+		r, err := g.Compile(ctx, WithCheckPointStore(store), WithInterruptBeforeNodes([]string{"2"}))
+		assert.NoError(t, err)
+		return r
+	}
+
+	// the checkpoint is written by the old version of the graph
+	oldGraph := newGraph(func(ctx context.Context) any {
+		return &migrationStateV1{Tags: "a,b"}
+	}, func(ctx context.Context, in string, state any) (string, error) {
+		return in, nil
+	})
+	_, err := oldGraph.Invoke(ctx, "start", WithCheckPointID("1"))
+	_, ok := ExtractInterruptInfo(err)
+	assert.True(t, ok)
+
+	// and resumed by the new version, whose state has changed
+	upgradedGraph := newGraph(func(ctx context.Context) any {
+		return &migrationStateV2{}
+	}, func(ctx context.Context, in string, state any) (string, error) {
+		return in + ":" + strings.Join(state.(*migrationStateV2).Tags, "|"), nil
+	})
+	out, err := upgradedGraph.Invoke(ctx, "", WithCheckPointID("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "start:a|b", out)
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileExt = ".ckpt"
+
+// FileStoreConfig is the config of NewFileStore.
+type FileStoreConfig struct {
+	// Dir is the directory of the checkpoint files, it's created if it doesn't exist.
+	Dir string
+	// TTL is the time a checkpoint lives after it's written, 0 means forever.
+	TTL time.Duration
+}
+
+// FileStore stores each checkpoint in a file of a local directory.
+// A checkpoint is written to a temporary file first and then renamed, so a crash never leaves a partially written checkpoint.
+// Operations are serialized within the FileStore, multiple processes sharing the directory are not coordinated.
+type FileStore struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+var _ Store = (*FileStore)(nil)
+
+// fileHeader is the first line of a checkpoint file, followed by the checkpoint.
+type fileHeader struct {
+	ID        string `json:"id"`
+	Version   int64  `json:"version"`
+	UpdatedAt int64  `json:"updated_at"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+func (h *fileHeader) info() *CheckPointInfo {
+	info := &CheckPointInfo{ID: h.ID, Version: h.Version, UpdatedAt: time.Unix(0, h.UpdatedAt)}
+	if h.ExpiresAt > 0 {
+		info.ExpiresAt = time.Unix(0, h.ExpiresAt)
+	}
+	return info
+}
+
+// NewFileStore creates a FileStore.
+func NewFileStore(_ context.Context, config *FileStoreConfig) (*FileStore, error) {
+	if config == nil || config.Dir == "" {
+		return nil, errors.New("file checkpoint store requires a directory")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create checkpoint directory fail: %w", err)
+	}
+	return &FileStore{dir: config.Dir, ttl: config.TTL, now: time.Now}, nil
+}
+
+func (s *FileStore) path(checkPointID string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(checkPointID))+fileExt)
+}
+
+// Get implements compose.CheckPointStore.
+func (s *FileStore) Get(ctx context.Context, checkPointID string) ([]byte, bool, error) {
+	data, _, ok, err := s.GetWithVersion(ctx, checkPointID)
+	return data, ok, err
+}
+
+// Set implements compose.CheckPointStore.
+func (s *FileStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, _, err := s.read(checkPointID, false)
+	if err != nil {
+		return err
+	}
+	var version int64
+	if h != nil {
+		version = h.Version
+	}
+	_, err = s.write(checkPointID, checkPoint, version+1)
+	return err
+}
+
+// GetWithVersion implements Store.
+func (s *FileStore) GetWithVersion(_ context.Context, checkPointID string) ([]byte, int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, data, err := s.read(checkPointID, true)
+	if err != nil || h == nil {
+		return nil, 0, false, err
+	}
+	return data, h.Version, true, nil
+}
+
+// SetWithVersion implements Store.
+func (s *FileStore) SetWithVersion(_ context.Context, checkPointID string, checkPoint []byte, expectedVersion int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, _, err := s.read(checkPointID, false)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	if h != nil {
+		version = h.Version
+	}
+	if version != expectedVersion {
+		return 0, fmt.Errorf("%w: checkpoint[%s] version is %d, expected %d", ErrVersionConflict, checkPointID, version, expectedVersion)
+	}
+	return s.write(checkPointID, checkPoint, version+1)
+}
+
+// List implements Store.
+func (s *FileStore) List(_ context.Context, prefix string) ([]*CheckPointInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint directory fail: %w", err)
+	}
+
+	var infos []*CheckPointInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		id, err := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(name, fileExt))
+		if err != nil || !hasPrefix(string(id), prefix) {
+			continue
+		}
+		h, _, err := s.read(string(id), false)
+		if err != nil {
+			return nil, err
+		}
+		if h != nil {
+			infos = append(infos, h.info())
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos, nil
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(_ context.Context, checkPointID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(checkPointID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return nil
+}
+
+// PurgeExpired implements Store.
+func (s *FileStore) PurgeExpired(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint directory fail: %w", err)
+	}
+
+	now := s.now()
+	n := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		h, err := readFileHeader(filepath.Join(s.dir, name))
+		if err != nil || h == nil {
+			continue
+		}
+		if isExpired(h.info().ExpiresAt, now) {
+			if err = os.Remove(filepath.Join(s.dir, name)); err == nil {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// read returns the header and the checkpoint, nil header if the checkpoint doesn't exist or has expired.
+func (s *FileStore) read(checkPointID string, withData bool) (*fileHeader, []byte, error) {
+	f, err := os.Open(s.path(checkPointID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("open checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	h, err := decodeFileHeader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if isExpired(h.info().ExpiresAt, s.now()) {
+		return nil, nil, nil
+	}
+	if !withData {
+		return h, nil, nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return h, data, nil
+}
+
+func readFileHeader(path string) (*fileHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeFileHeader(bufio.NewReader(f))
+}
+
+func decodeFileHeader(r *bufio.Reader) (*fileHeader, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("read header fail: %w", err)
+	}
+	h := &fileHeader{}
+	if err = json.Unmarshal(bytes.TrimSuffix(line, []byte("\n")), h); err != nil {
+		return nil, fmt.Errorf("decode header fail: %w", err)
+	}
+	return h, nil
+}
+
+// write writes the checkpoint to a temporary file, and renames it to the checkpoint file.
+func (s *FileStore) write(checkPointID string, checkPoint []byte, version int64) (int64, error) {
+	now := s.now()
+	h := &fileHeader{ID: checkPointID, Version: version, UpdatedAt: now.UnixNano()}
+	if exp := expiresAt(now, s.ttl); !exp.IsZero() {
+		h.ExpiresAt = exp.UnixNano()
+	}
+	header, err := json.Marshal(h)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("create temporary checkpoint file fail: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(append(header, '\n')); err != nil {
+		return 0, fmt.Errorf("write checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if _, err = tmp.Write(checkPoint); err != nil {
+		return 0, fmt.Errorf("write checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return 0, fmt.Errorf("sync checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, fmt.Errorf("close checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if err = os.Rename(tmp.Name(), s.path(checkPointID)); err != nil {
+		return 0, fmt.Errorf("rename checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointstore
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := NewFileStore(ctx, &FileStoreConfig{Dir: dir, TTL: time.Hour})
+	assert.NoError(t, err)
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	s.now = clock.Now
+	testStore(t, s, clock)
+
+	t.Run("no temporary files left", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		for _, e := range entries {
+			assert.Equal(t, fileExt, e.Name()[len(e.Name())-len(fileExt):])
+		}
+	})
+
+	t.Run("ids are escaped", func(t *testing.T) {
+		s, err := NewFileStore(ctx, &FileStoreConfig{Dir: t.TempDir()})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Set(ctx, "../a/b", []byte("x")))
+		infos, err := s.List(ctx, "")
+		assert.NoError(t, err)
+		if assert.Len(t, infos, 1) {
+			assert.Equal(t, "../a/b", infos[0].ID)
+			assert.True(t, infos[0].ExpiresAt.IsZero())
+		}
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		s, err := NewFileStore(ctx, &FileStoreConfig{Dir: t.TempDir()})
+		assert.NoError(t, err)
+		testGraphResume(t, s)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewFileStore(ctx, &FileStoreConfig{})
+		assert.Error(t, err)
+	})
+}
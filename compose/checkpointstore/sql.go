@@ -0,0 +1,292 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultSQLTable = "eino_checkpoints"
+
+// SQLStoreConfig is the config of NewSQLStore.
+type SQLStoreConfig struct {
+	// DB is the database, with the driver of it registered by the user.
+	DB *sql.DB
+	// Table is the table of the checkpoints, "eino_checkpoints" by default. The table should be created beforehand, e.g.
+	//
+	//	CREATE TABLE eino_checkpoints (
+	//		id         VARCHAR(255) PRIMARY KEY,
+	//		data       BLOB   NOT NULL,
+	//		version    BIGINT NOT NULL,
+	//		updated_at BIGINT NOT NULL, -- unix nanoseconds
+	//		expires_at BIGINT NOT NULL  -- unix nanoseconds, 0 for never
+	//	);
+	Table string
+	// TTL is the time a checkpoint lives after it's written, 0 means forever.
+	TTL time.Duration
+	// Placeholder returns the n-th(starting from 1) bind parameter placeholder of the driver, "?" by default.
+	// e.g. func(n int) string { return fmt.Sprintf("$%d", n) } for PostgreSQL.
+	Placeholder func(n int) string
+}
+
+// SQLStore stores checkpoints in a table by database/sql, e.g. of SQLite, MySQL or PostgreSQL.
+// Writes are done in transactions, and SetWithVersion is guarded by the version column,
+// so the store can be shared by multiple processes.
+type SQLStore struct {
+	db  *sql.DB
+	ttl time.Duration
+	now func() time.Time
+
+	getStmt       string
+	getMetaStmt   string
+	insertStmt    string
+	updateStmt    string
+	deleteStmt    string
+	listStmt      string
+	purgeStmt     string
+	deleteExpStmt string
+}
+
+var _ Store = (*SQLStore)(nil)
+
+// NewSQLStore creates a SQLStore.
+func NewSQLStore(_ context.Context, config *SQLStoreConfig) (*SQLStore, error) {
+	if config == nil || config.DB == nil {
+		return nil, errors.New("sql checkpoint store requires a database")
+	}
+	table := config.Table
+	if table == "" {
+		table = defaultSQLTable
+	}
+	ph := config.Placeholder
+	if ph == nil {
+		ph = func(int) string { return "?" }
+	}
+	phs := func(n int) []any {
+		ret := make([]any, n)
+		for i := range ret {
+			ret[i] = ph(i + 1)
+		}
+		return ret
+	}
+
+	return &SQLStore{
+		db:  config.DB,
+		ttl: config.TTL,
+		now: time.Now,
+
+		getStmt:       fmt.Sprintf("SELECT data, version, expires_at FROM %s WHERE id = %s", table, ph(1)),
+		getMetaStmt:   fmt.Sprintf("SELECT version, expires_at FROM %s WHERE id = %s", table, ph(1)),
+		insertStmt:    fmt.Sprintf("INSERT INTO %s (id, data, version, updated_at, expires_at) VALUES (%s, %s, %s, %s, %s)", append([]any{table}, phs(5)...)...),
+		updateStmt:    fmt.Sprintf("UPDATE %s SET data = %s, version = %s, updated_at = %s, expires_at = %s WHERE id = %s AND version = %s", append([]any{table}, phs(6)...)...),
+		deleteStmt:    fmt.Sprintf("DELETE FROM %s WHERE id = %s", table, ph(1)),
+		listStmt:      fmt.Sprintf("SELECT id, version, updated_at, expires_at FROM %s WHERE id LIKE %s ESCAPE '!'", table, ph(1)),
+		purgeStmt:     fmt.Sprintf("DELETE FROM %s WHERE expires_at > 0 AND expires_at <= %s", table, ph(1)),
+		deleteExpStmt: fmt.Sprintf("DELETE FROM %s WHERE id = %s AND expires_at > 0 AND expires_at <= %s", table, ph(1), ph(2)),
+	}, nil
+}
+
+// Get implements compose.CheckPointStore.
+func (s *SQLStore) Get(ctx context.Context, checkPointID string) ([]byte, bool, error) {
+	data, _, ok, err := s.GetWithVersion(ctx, checkPointID)
+	return data, ok, err
+}
+
+// Set implements compose.CheckPointStore.
+func (s *SQLStore) Set(ctx context.Context, checkPointID string, checkPoint []byte) error {
+	_, err := s.set(ctx, checkPointID, checkPoint, -1)
+	return err
+}
+
+// GetWithVersion implements Store.
+func (s *SQLStore) GetWithVersion(ctx context.Context, checkPointID string) ([]byte, int64, bool, error) {
+	var (
+		data      []byte
+		version   int64
+		expiresAt int64
+	)
+	err := s.db.QueryRowContext(ctx, s.getStmt, checkPointID).Scan(&data, &version, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("get checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if isExpired(unixNano(expiresAt), s.now()) {
+		return nil, 0, false, nil
+	}
+	return data, version, true, nil
+}
+
+// SetWithVersion implements Store.
+func (s *SQLStore) SetWithVersion(ctx context.Context, checkPointID string, checkPoint []byte, expectedVersion int64) (int64, error) {
+	return s.set(ctx, checkPointID, checkPoint, expectedVersion)
+}
+
+// List implements Store.
+func (s *SQLStore) List(ctx context.Context, prefix string) ([]*CheckPointInfo, error) {
+	rows, err := s.db.QueryContext(ctx, s.listStmt, likePrefixPattern(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("list checkpoints fail: %w", err)
+	}
+	defer rows.Close()
+
+	now := s.now()
+	var infos []*CheckPointInfo
+	for rows.Next() {
+		var (
+			id                            string
+			version, updatedAt, expiresAt int64
+		)
+		if err = rows.Scan(&id, &version, &updatedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("list checkpoints fail: %w", err)
+		}
+		// LIKE is case-insensitive in some databases, e.g. SQLite, so the prefix is checked again
+		if !hasPrefix(id, prefix) || isExpired(unixNano(expiresAt), now) {
+			continue
+		}
+		infos = append(infos, &CheckPointInfo{
+			ID:        id,
+			Version:   version,
+			UpdatedAt: time.Unix(0, updatedAt),
+			ExpiresAt: unixNano(expiresAt),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("list checkpoints fail: %w", err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos, nil
+}
+
+// Delete implements Store.
+func (s *SQLStore) Delete(ctx context.Context, checkPointID string) error {
+	if _, err := s.db.ExecContext(ctx, s.deleteStmt, checkPointID); err != nil {
+		return fmt.Errorf("delete checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return nil
+}
+
+// PurgeExpired implements Store.
+func (s *SQLStore) PurgeExpired(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, s.purgeStmt, s.now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("purge expired checkpoints fail: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge expired checkpoints fail: %w", err)
+	}
+	return int(n), nil
+}
+
+// set writes the checkpoint in a transaction, expectedVersion < 0 means unconditionally.
+func (s *SQLStore) set(ctx context.Context, checkPointID string, checkPoint []byte, expectedVersion int64) (version int64, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := s.now()
+	var (
+		current int64
+		curExp  int64
+		existed = true
+	)
+	err = tx.QueryRowContext(ctx, s.getMetaStmt, checkPointID).Scan(&current, &curExp)
+	if errors.Is(err, sql.ErrNoRows) {
+		existed, err = false, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if existed && isExpired(unixNano(curExp), now) {
+		// an expired checkpoint is replaced as if it didn't exist
+		if _, err = tx.ExecContext(ctx, s.deleteExpStmt, checkPointID, now.UnixNano()); err != nil {
+			return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+		}
+		existed, current = false, 0
+	}
+	if expectedVersion >= 0 && current != expectedVersion {
+		err = fmt.Errorf("%w: checkpoint[%s] version is %d, expected %d", ErrVersionConflict, checkPointID, current, expectedVersion)
+		return 0, err
+	}
+
+	version = current + 1
+	var exp int64
+	if t := expiresAt(now, s.ttl); !t.IsZero() {
+		exp = t.UnixNano()
+	}
+	if existed {
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, s.updateStmt, checkPoint, version, now.UnixNano(), exp, checkPointID, current)
+		if err != nil {
+			return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+		}
+		var n int64
+		if n, err = res.RowsAffected(); err != nil {
+			return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+		}
+		if n == 0 {
+			err = fmt.Errorf("%w: checkpoint[%s] is modified concurrently", ErrVersionConflict, checkPointID)
+			return 0, err
+		}
+	} else if _, err = tx.ExecContext(ctx, s.insertStmt, checkPointID, checkPoint, version, now.UnixNano(), exp); err != nil {
+		// the checkpoint may be inserted concurrently, which violates the primary key.
+		// The transaction is rolled back first, since some databases, e.g. PostgreSQL, abort it on the failure.
+		_ = tx.Rollback()
+		if s.exists(ctx, checkPointID) {
+			err = fmt.Errorf("%w: checkpoint[%s] is inserted concurrently", ErrVersionConflict, checkPointID)
+			return 0, err
+		}
+		return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("set checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return version, nil
+}
+
+// exists reports whether the checkpoint exists, failing to query is reported as not existing.
+func (s *SQLStore) exists(ctx context.Context, checkPointID string) bool {
+	var version, expiresAt int64
+	return s.db.QueryRowContext(ctx, s.getMetaStmt, checkPointID).Scan(&version, &expiresAt) == nil
+}
+
+// likePrefixPattern returns the LIKE pattern matching the IDs with the prefix, escaped by '!'.
+func likePrefixPattern(prefix string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(prefix) + "%"
+}
+
+func unixNano(n int64) time.Time {
+	if n <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,353 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSQLDriver is an in-process database/sql driver interpreting the statements of SQLStore,
+// transactions are serialized and rolled back by restoring the snapshot taken on Begin.
+type fakeSQLDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeSQLDB
+}
+
+type fakeSQLDB struct {
+	mu   sync.Mutex
+	rows map[string]fakeSQLRow
+	// concurrentInserts are committed by another process right before the rows of the same IDs are inserted
+	concurrentInserts map[string]fakeSQLRow
+}
+
+type fakeSQLRow struct {
+	data                          []byte
+	version, updatedAt, expiresAt int64
+}
+
+type fakeSQLConn struct {
+	db       *fakeSQLDB
+	snapshot map[string]fakeSQLRow // not nil in a transaction
+}
+
+type fakeSQLStmt struct {
+	conn  *fakeSQLConn
+	query string
+}
+
+type fakeSQLResult int64
+
+type fakeSQLRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+var testSQLDriver = &fakeSQLDriver{dbs: map[string]*fakeSQLDB{}}
+
+func init() {
+	sql.Register("eino_fake_checkpoint", testSQLDriver)
+}
+
+func (d *fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeSQLDB{rows: map[string]fakeSQLRow{}}
+		d.dbs[name] = db
+	}
+	return &fakeSQLConn{db: db}, nil
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeSQLConn) Close() error {
+	return nil
+}
+
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	c.snapshot = make(map[string]fakeSQLRow, len(c.db.rows))
+	for k, v := range c.db.rows {
+		c.snapshot[k] = v
+	}
+	return c, nil
+}
+
+func (c *fakeSQLConn) Commit() error {
+	c.snapshot = nil
+	c.db.mu.Unlock()
+	return nil
+}
+
+func (c *fakeSQLConn) Rollback() error {
+	c.db.rows = c.snapshot
+	c.snapshot = nil
+	c.db.mu.Unlock()
+	return nil
+}
+
+func (s *fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (s *fakeSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	ret, err := s.run(args)
+	if err != nil {
+		return nil, err
+	}
+	return ret.(fakeSQLResult), nil
+}
+
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	ret, err := s.run(args)
+	if err != nil {
+		return nil, err
+	}
+	return ret.(*fakeSQLRows), nil
+}
+
+func (s *fakeSQLStmt) run(args []driver.Value) (any, error) {
+	db := s.conn.db
+	if s.conn.snapshot == nil {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+	}
+
+	q := s.query
+	switch {
+	case strings.HasPrefix(q, "SELECT data, version, expires_at"):
+		rows := &fakeSQLRows{columns: []string{"data", "version", "expires_at"}}
+		if r, ok := db.rows[args[0].(string)]; ok {
+			rows.values = append(rows.values, []driver.Value{r.data, r.version, r.expiresAt})
+		}
+		return rows, nil
+	case strings.HasPrefix(q, "SELECT version, expires_at"):
+		rows := &fakeSQLRows{columns: []string{"version", "expires_at"}}
+		if r, ok := db.rows[args[0].(string)]; ok {
+			rows.values = append(rows.values, []driver.Value{r.version, r.expiresAt})
+		}
+		return rows, nil
+	case strings.HasPrefix(q, "SELECT id, version, updated_at, expires_at"):
+		rows := &fakeSQLRows{columns: []string{"id", "version", "updated_at", "expires_at"}}
+		like := fakeSQLLike(args[0].(string))
+		for id, r := range db.rows {
+			if like.MatchString(id) {
+				rows.values = append(rows.values, []driver.Value{id, r.version, r.updatedAt, r.expiresAt})
+			}
+		}
+		return rows, nil
+	case strings.HasPrefix(q, "INSERT"):
+		id := args[0].(string)
+		if r, ok := db.concurrentInserts[id]; ok {
+			delete(db.concurrentInserts, id)
+			db.rows[id] = r
+			if s.conn.snapshot != nil {
+				// committed by another process, survives the rollback
+				s.conn.snapshot[id] = r
+			}
+		}
+		if _, ok := db.rows[id]; ok {
+			return nil, fmt.Errorf("duplicate primary key: %s", id)
+		}
+		db.rows[id] = fakeSQLRow{data: args[1].([]byte), version: args[2].(int64), updatedAt: args[3].(int64), expiresAt: args[4].(int64)}
+		return fakeSQLResult(1), nil
+	case strings.HasPrefix(q, "UPDATE"):
+		id := args[4].(string)
+		if r, ok := db.rows[id]; !ok || r.version != args[5].(int64) {
+			return fakeSQLResult(0), nil
+		}
+		db.rows[id] = fakeSQLRow{data: args[0].([]byte), version: args[1].(int64), updatedAt: args[2].(int64), expiresAt: args[3].(int64)}
+		return fakeSQLResult(1), nil
+	case strings.Contains(q, "WHERE id = ? AND expires_at"):
+		id, now := args[0].(string), args[1].(int64)
+		if r, ok := db.rows[id]; ok && r.expiresAt > 0 && r.expiresAt <= now {
+			delete(db.rows, id)
+			return fakeSQLResult(1), nil
+		}
+		return fakeSQLResult(0), nil
+	case strings.Contains(q, "WHERE id = ?"):
+		if _, ok := db.rows[args[0].(string)]; ok {
+			delete(db.rows, args[0].(string))
+			return fakeSQLResult(1), nil
+		}
+		return fakeSQLResult(0), nil
+	case strings.Contains(q, "WHERE expires_at"):
+		n := 0
+		for id, r := range db.rows {
+			if r.expiresAt > 0 && r.expiresAt <= args[0].(int64) {
+				delete(db.rows, id)
+				n++
+			}
+		}
+		return fakeSQLResult(n), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", q)
+}
+
+// fakeSQLLike compiles the LIKE pattern escaped by '!'.
+func fakeSQLLike(pattern string) *regexp.Regexp {
+	sb := strings.Builder{}
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '!':
+			i++
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '%':
+			sb.WriteString("(?s:.*)")
+		case '_':
+			sb.WriteString("(?s:.)")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+func (r fakeSQLResult) LastInsertId() (int64, error) {
+	return 0, errors.New("not supported")
+}
+
+func (r fakeSQLResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+func (r *fakeSQLRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeSQLRows) Close() error {
+	return nil
+}
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("eino_fake_checkpoint", t.Name())
+	assert.NoError(t, err)
+	defer db.Close()
+
+	s, err := NewSQLStore(ctx, &SQLStoreConfig{DB: db, TTL: time.Hour})
+	assert.NoError(t, err)
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	s.now = clock.Now
+	testStore(t, s, clock)
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.SetWithVersion(ctx, "concurrent", []byte("x"), 0); err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				} else {
+					assert.True(t, errors.Is(err, ErrVersionConflict))
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("inserted concurrently", func(t *testing.T) {
+		testSQLDriver.mu.Lock()
+		testSQLDriver.dbs[t.Name()] = &fakeSQLDB{rows: map[string]fakeSQLRow{}, concurrentInserts: map[string]fakeSQLRow{
+			"racy": {data: []byte("other"), version: 1},
+		}}
+		testSQLDriver.mu.Unlock()
+		db, err := sql.Open("eino_fake_checkpoint", t.Name())
+		assert.NoError(t, err)
+		defer db.Close()
+		s, err := NewSQLStore(ctx, &SQLStoreConfig{DB: db})
+		assert.NoError(t, err)
+
+		_, err = s.SetWithVersion(ctx, "racy", []byte("mine"), 0)
+		assert.True(t, errors.Is(err, ErrVersionConflict))
+		data, version, ok, err := s.GetWithVersion(ctx, "racy")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "other", string(data))
+		assert.Equal(t, int64(1), version)
+	})
+
+	t.Run("list escapes the prefix", func(t *testing.T) {
+		assert.Equal(t, "a!_b!%c!!d%", likePrefixPattern("a_b%c!d"))
+
+		for _, id := range []string{"run_1", "runX1", "run%1"} {
+			assert.NoError(t, s.Set(ctx, id, []byte("x")))
+		}
+		var ids []string
+		infos, err := s.List(ctx, "run_")
+		assert.NoError(t, err)
+		for _, info := range infos {
+			ids = append(ids, info.ID)
+		}
+		assert.Equal(t, []string{"run_1"}, ids)
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		s, err := NewSQLStore(ctx, &SQLStoreConfig{DB: db, Table: "graph_checkpoints"})
+		assert.NoError(t, err)
+		testGraphResume(t, s)
+	})
+
+	t.Run("placeholder", func(t *testing.T) {
+		s, err := NewSQLStore(ctx, &SQLStoreConfig{DB: db, Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }})
+		assert.NoError(t, err)
+		assert.Equal(t, "UPDATE eino_checkpoints SET data = $1, version = $2, updated_at = $3, expires_at = $4 WHERE id = $5 AND version = $6", s.updateStmt)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewSQLStore(ctx, &SQLStoreConfig{})
+		assert.Error(t, err)
+	})
+}
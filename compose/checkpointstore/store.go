@@ -0,0 +1,74 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package checkpointstore provides durable CheckPointStore implementations,
+// which can be used by both compose.WithCheckPointStore and adk.RunnerConfig.CheckPointStore.
+package checkpointstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// ErrVersionConflict is returned by SetWithVersion when the version of the checkpoint isn't the expected one.
+var ErrVersionConflict = errors.New("checkpoint version conflict")
+
+// CheckPointInfo is the metadata of a stored checkpoint.
+type CheckPointInfo struct {
+	ID string
+	// Version starts from 1 and increases on every write of the checkpoint.
+	Version   int64
+	UpdatedAt time.Time
+	// ExpiresAt is zero if the checkpoint never expires.
+	ExpiresAt time.Time
+}
+
+// Store is a CheckPointStore which also supports listing, deleting and optimistic concurrency.
+// Expired checkpoints are treated as non-existent by all the methods.
+type Store interface {
+	compose.CheckPointStore
+
+	// GetWithVersion returns the checkpoint along with its version.
+	GetWithVersion(ctx context.Context, checkPointID string) (checkPoint []byte, version int64, existed bool, err error)
+	// SetWithVersion writes the checkpoint only if its current version is expectedVersion, 0 for a checkpoint that doesn't exist,
+	// and returns the new version. ErrVersionConflict is returned otherwise.
+	SetWithVersion(ctx context.Context, checkPointID string, checkPoint []byte, expectedVersion int64) (version int64, err error)
+	// List returns the checkpoints whose IDs have the prefix, ordered by ID.
+	List(ctx context.Context, prefix string) ([]*CheckPointInfo, error)
+	// Delete removes the checkpoint, it's not an error if the checkpoint doesn't exist.
+	Delete(ctx context.Context, checkPointID string) error
+	// PurgeExpired removes the expired checkpoints and returns the number of them.
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+func expiresAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func isExpired(expiresAt, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+func hasPrefix(id, prefix string) bool {
+	return prefix == "" || strings.HasPrefix(id, prefix)
+}
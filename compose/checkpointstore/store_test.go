@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// testStore runs the common cases of a Store, whose TTL is an hour and whose clock is the given one.
+func testStore(t *testing.T, s Store, clock *testClock) {
+	ctx := context.Background()
+
+	t.Run("get and set", func(t *testing.T) {
+		_, ok, err := s.Get(ctx, "missing")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, s.Set(ctx, "a", []byte("v1")))
+		assert.NoError(t, s.Set(ctx, "a", []byte("v2")))
+		data, version, ok, err := s.GetWithVersion(ctx, "a")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "v2", string(data))
+		assert.Equal(t, int64(2), version)
+	})
+
+	t.Run("version", func(t *testing.T) {
+		version, err := s.SetWithVersion(ctx, "b", []byte("v1"), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), version)
+
+		_, err = s.SetWithVersion(ctx, "b", []byte("x"), 0)
+		assert.True(t, errors.Is(err, ErrVersionConflict))
+
+		version, err = s.SetWithVersion(ctx, "b", []byte("v2"), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), version)
+
+		_, err = s.SetWithVersion(ctx, "b", []byte("x"), 1)
+		assert.True(t, errors.Is(err, ErrVersionConflict))
+		data, _, _ := s.Get(ctx, "b")
+		assert.Equal(t, "v2", string(data))
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "run-2", []byte("x")))
+		assert.NoError(t, s.Set(ctx, "run-1", []byte("x")))
+		infos, err := s.List(ctx, "run-")
+		assert.NoError(t, err)
+		if assert.Len(t, infos, 2) {
+			assert.Equal(t, "run-1", infos[0].ID)
+			assert.Equal(t, "run-2", infos[1].ID)
+			assert.Equal(t, int64(1), infos[0].Version)
+			assert.Equal(t, clock.now.Add(time.Hour).UnixNano(), infos[0].ExpiresAt.UnixNano())
+		}
+
+		assert.NoError(t, s.Delete(ctx, "run-1"))
+		assert.NoError(t, s.Delete(ctx, "run-1"))
+		infos, err = s.List(ctx, "run-")
+		assert.NoError(t, err)
+		assert.Len(t, infos, 1)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "old", []byte("x")))
+		clock.now = clock.now.Add(30 * time.Minute)
+		assert.NoError(t, s.Set(ctx, "new", []byte("x")))
+		clock.now = clock.now.Add(45 * time.Minute)
+
+		_, ok, err := s.Get(ctx, "old")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		_, ok, err = s.Get(ctx, "new")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// an expired checkpoint is written as a new one
+		version, err := s.SetWithVersion(ctx, "old", []byte("y"), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), version)
+
+		clock.now = clock.now.Add(24 * time.Hour)
+		infos, err := s.List(ctx, "")
+		assert.NoError(t, err)
+		assert.Empty(t, infos)
+		n, err := s.PurgeExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, n)
+	})
+}
+
+// testGraphResume checks the store works as the CheckPointStore of a graph.
+func testGraphResume(t *testing.T, s Store) {
+	ctx := context.Background()
+	g := compose.NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("1", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return in + "1", nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("2", compose.InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return in + "2", nil
+	})))
+	assert.NoError(t, g.AddEdge(compose.START, "1"))
+	assert.NoError(t, g.AddEdge("1", "2"))
+	assert.NoError(t, g.AddEdge("2", compose.END))
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(s), compose.WithInterruptBeforeNodes([]string{"2"}))
+	assert.NoError(t, err)
+
+	_, err = r.Invoke(ctx, "start", compose.WithCheckPointID("graph-1"))
+	_, ok := compose.ExtractInterruptInfo(err)
+	assert.True(t, ok)
+	infos, err := s.List(ctx, "graph-")
+	assert.NoError(t, err)
+	assert.Len(t, infos, 1)
+
+	out, err := r.Invoke(ctx, "", compose.WithCheckPointID("graph-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "start12", out)
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/components"
+)
+
+// ConcurrencyLimiterConfig is the config of a ConcurrencyLimiter.
+type ConcurrencyLimiterConfig struct {
+	// MaxConcurrency is the max number of nodes executing at the same time, 0 means unlimited.
+	MaxConcurrency int
+	// MaxConcurrencyByComponent is the max number of nodes of a component type executing at the same time,
+	// e.g. {components.ComponentOfChatModel: 4} allows at most 4 chat model calls at once.
+	MaxConcurrencyByComponent map[components.Component]int
+}
+
+// ConcurrencyLimiter caps the number of graph nodes executing at the same time, see WithConcurrencyLimiter.
+// A node waits for a free slot before it's executed, and releases the slot when it returns.
+// In stream mode, the slot of its component type is held until its output stream ends, e.g. until the chat model
+// finishes generating, for which the output stream is received eagerly and buffered until it's consumed.
+// While a node runs a nested graph, e.g. a Lambda invoking a compiled graph, by NewMapLambda or RunSubTask,
+// its slots are released, so that the nodes of the nested graph sharing the limiter can run.
+// A limiter can be shared by multiple graphs and runs, e.g. to keep all the chat model calls of a process under the quota.
+type ConcurrencyLimiter struct {
+	total       chan struct{}
+	byComponent map[components.Component]chan struct{}
+}
+
+// NewConcurrencyLimiter creates a ConcurrencyLimiter.
+func NewConcurrencyLimiter(config *ConcurrencyLimiterConfig) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{byComponent: make(map[components.Component]chan struct{})}
+	if config == nil {
+		return l
+	}
+	if config.MaxConcurrency > 0 {
+		l.total = make(chan struct{}, config.MaxConcurrency)
+	}
+	for c, n := range config.MaxConcurrencyByComponent {
+		if n > 0 {
+			l.byComponent[c] = make(chan struct{}, n)
+		}
+	}
+	return l
+}
+
+// nodeSlot is the slots of a limiter held by a node.
+type nodeSlot struct {
+	component chan struct{}
+	total     chan struct{}
+
+	mu                       sync.Mutex
+	holdComponent, holdTotal bool
+	// suspended is the number of the nested graphs running, e.g. concurrently by NewMapLambda
+	suspended int
+}
+
+// acquire waits for the slots of the node, the slot of the component type is taken first,
+// so that waiting for it doesn't hold a slot of the total.
+func (s *nodeSlot) acquire(ctx context.Context) error {
+	if s.component != nil {
+		select {
+		case s.component <- struct{}{}:
+			s.mu.Lock()
+			s.holdComponent = true
+			s.mu.Unlock()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.total != nil {
+		select {
+		case s.total <- struct{}{}:
+			s.mu.Lock()
+			s.holdTotal = true
+			s.mu.Unlock()
+		case <-ctx.Done():
+			s.release()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *nodeSlot) release() {
+	s.releaseTotal()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holdComponent {
+		<-s.component
+		s.holdComponent = false
+	}
+}
+
+func (s *nodeSlot) releaseTotal() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holdTotal {
+		<-s.total
+		s.holdTotal = false
+	}
+}
+
+type concurrencyLimiterKey struct{}
+
+type nodeSlotKey struct{}
+
+// withConcurrencyLimiter makes the limiter apply to the graph and the graphs nested in it,
+// unless they have their own limiters.
+func withConcurrencyLimiter(ctx context.Context, l *ConcurrencyLimiter) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, concurrencyLimiterKey{}, l)
+}
+
+// acquireNodeSlot waits for a slot of the node, which is nil if the node isn't limited.
+// The nodes of sub graphs are not limited, as the nodes in them are, a sub graph holding a slot could block its own nodes.
+func acquireNodeSlot(ctx context.Context, t *task) (*nodeSlot, error) {
+	l, ok := ctx.Value(concurrencyLimiterKey{}).(*ConcurrencyLimiter)
+	if !ok {
+		return nil, nil
+	}
+
+	var c components.Component
+	if t.call != nil && t.call.action != nil && t.call.action.meta != nil {
+		c = t.call.action.meta.component
+	}
+	switch c {
+	case ComponentOfGraph, ComponentOfWorkflow, ComponentOfChain:
+		return nil, nil
+	}
+
+	s := &nodeSlot{component: l.byComponent[c], total: l.total}
+	if s.component == nil && s.total == nil {
+		return nil, nil
+	}
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// releaseNodeSlot releases the slot of the node when it returns,
+// except for the slot of its component type in stream mode, which is released when its output stream ends.
+func releaseNodeSlot(t *task, s *nodeSlot) {
+	if s == nil {
+		return
+	}
+	sr, ok := t.output.(streamReader)
+	if !ok || t.err != nil {
+		s.release()
+		return
+	}
+	s.releaseTotal()
+	s.mu.Lock()
+	holdComponent := s.holdComponent
+	s.mu.Unlock()
+	if holdComponent {
+		t.output = sr.withDrainHook(s.release)
+	}
+}
+
+func withNodeSlot(ctx context.Context, s *nodeSlot) context.Context {
+	return context.WithValue(ctx, nodeSlotKey{}, s)
+}
+
+// suspendNodeSlot releases the slot of the node running a nested graph, returning the context for the nested graph,
+// in which its nodes take slots of their own, and the function to take the slot back when the nested graph returns.
+func suspendNodeSlot(ctx context.Context) (context.Context, func()) {
+	s, ok := ctx.Value(nodeSlotKey{}).(*nodeSlot)
+	if !ok || s == nil {
+		return ctx, func() {}
+	}
+
+	s.mu.Lock()
+	s.suspended++
+	first := s.suspended == 1
+	s.mu.Unlock()
+	if first {
+		s.release()
+	}
+
+	return withNodeSlot(ctx, nil), func() {
+		s.mu.Lock()
+		s.suspended--
+		last := s.suspended == 0
+		s.mu.Unlock()
+		if last {
+			// the node fails with the error of ctx anyway if it's done
+			_ = s.acquire(ctx)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+)
+
+type concurrencyCounter struct {
+	running, max int32
+}
+
+func (c *concurrencyCounter) run(d time.Duration) {
+	cur := atomic.AddInt32(&c.running, 1)
+	for {
+		m := atomic.LoadInt32(&c.max)
+		if cur <= m || atomic.CompareAndSwapInt32(&c.max, m, cur) {
+			break
+		}
+	}
+	time.Sleep(d)
+	atomic.AddInt32(&c.running, -1)
+}
+
+func newWideTestGraph(t *testing.T, width int, counter *concurrencyCounter, opts ...GraphCompileOption) Runnable[string, map[string]any] {
+	g := NewGraph[string, map[string]any]()
+	for i := 0; i < width; i++ {
+		key := fmt.Sprintf("n%d", i)
+		assert.NoError(t, g.AddLambdaNode(key, InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			counter.run(20 * time.Millisecond)
+			return in, nil
+		}), WithOutputKey(key)))
+		assert.NoError(t, g.AddEdge(START, key))
+		assert.NoError(t, g.AddEdge(key, END))
+	}
+	r, err := g.Compile(context.Background(), opts...)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("total", func(t *testing.T) {
+		counter := &concurrencyCounter{}
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{MaxConcurrency: 2})
+		out, err := newWideTestGraph(t, 6, counter, WithConcurrencyLimiter(limiter)).Invoke(ctx, "x")
+		assert.NoError(t, err)
+		assert.Len(t, out, 6)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&counter.max))
+	})
+
+	t.Run("by component shared across runs", func(t *testing.T) {
+		counter := &concurrencyCounter{}
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{
+			MaxConcurrencyByComponent: map[components.Component]int{ComponentOfLambda: 3},
+		})
+		r1 := newWideTestGraph(t, 4, counter, WithConcurrencyLimiter(limiter))
+		r2 := newWideTestGraph(t, 4, counter, WithConcurrencyLimiter(limiter))
+
+		var wg sync.WaitGroup
+		for _, r := range []Runnable[string, map[string]any]{r1, r2} {
+			wg.Add(1)
+			go func(r Runnable[string, map[string]any]) {
+				defer wg.Done()
+				_, err := r.Invoke(ctx, "x")
+				assert.NoError(t, err)
+			}(r)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(3), atomic.LoadInt32(&counter.max))
+	})
+
+	t.Run("sub graph", func(t *testing.T) {
+		counter := &concurrencyCounter{}
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{MaxConcurrency: 1})
+
+		sub := NewGraph[string, map[string]any]()
+		for _, key := range []string{"a", "b"} {
+			assert.NoError(t, sub.AddLambdaNode(key, InvokableLambda(func(ctx context.Context, in string) (string, error) {
+				counter.run(10 * time.Millisecond)
+				return in, nil
+			}), WithOutputKey(key)))
+			assert.NoError(t, sub.AddEdge(START, key))
+			assert.NoError(t, sub.AddEdge(key, END))
+		}
+		g := NewGraph[string, map[string]any]()
+		assert.NoError(t, g.AddGraphNode("sub", sub))
+		assert.NoError(t, g.AddEdge(START, "sub"))
+		assert.NoError(t, g.AddEdge("sub", END))
+		r, err := g.Compile(ctx, WithConcurrencyLimiter(limiter))
+		assert.NoError(t, err)
+
+		// the sub graph node doesn't hold a slot, and its nodes are limited
+		out, err := r.Invoke(ctx, "x")
+		assert.NoError(t, err)
+		assert.Len(t, out, 2)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&counter.max))
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{MaxConcurrency: 1})
+		slot := &nodeSlot{total: limiter.total}
+		assert.NoError(t, slot.acquire(ctx))
+		defer slot.release()
+
+		cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err := newWideTestGraph(t, 1, &concurrencyCounter{}, WithConcurrencyLimiter(limiter)).Invoke(cctx, "x")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("nested runs", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{MaxConcurrency: 1})
+		inner := NewGraph[string, string]()
+		assert.NoError(t, inner.AddLambdaNode("s", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+			return in + "!", nil
+		})))
+		assert.NoError(t, inner.AddEdge(START, "s"))
+		assert.NoError(t, inner.AddEdge("s", END))
+		innerR, err := inner.Compile(ctx, WithConcurrencyLimiter(limiter))
+		assert.NoError(t, err)
+
+		g := NewGraph[string, []string]()
+		assert.NoError(t, g.AddLambdaNode("p", InvokableLambda(func(ctx context.Context, in string) ([]string, error) {
+			out, err := RunSubTask(ctx, "s", innerR, in)
+			if err != nil {
+				return nil, err
+			}
+			return []string{out, out, out}, nil
+		})))
+		assert.NoError(t, g.AddLambdaNode("m", NewMapLambda(innerR, nil)))
+		assert.NoError(t, g.AddEdge(START, "p"))
+		assert.NoError(t, g.AddEdge("p", "m"))
+		assert.NoError(t, g.AddEdge("m", END))
+		r, err := g.Compile(ctx, WithConcurrencyLimiter(limiter))
+		assert.NoError(t, err)
+
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		out, err := r.Invoke(cctx, "x")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"x!!", "x!!", "x!!"}, out)
+	})
+
+	t.Run("stream holds the component slot until it ends", func(t *testing.T) {
+		counter := &concurrencyCounter{}
+		limiter := NewConcurrencyLimiter(&ConcurrencyLimiterConfig{
+			MaxConcurrencyByComponent: map[components.Component]int{ComponentOfLambda: 1},
+		})
+		g := NewGraph[string, map[string]any]()
+		for _, key := range []string{"a", "b", "c"} {
+			assert.NoError(t, g.AddLambdaNode(key, StreamableLambda(func(ctx context.Context, in string) (*schema.StreamReader[string], error) {
+				sr, sw := schema.Pipe[string](0)
+				go func() {
+					defer sw.Close()
+					// streaming counts as running
+					counter.run(30 * time.Millisecond)
+					sw.Send(in, nil)
+				}()
+				return sr, nil
+			}), WithOutputKey(key)))
+			assert.NoError(t, g.AddEdge(START, key))
+			assert.NoError(t, g.AddEdge(key, END))
+		}
+		r, err := g.Compile(ctx, WithConcurrencyLimiter(limiter))
+		assert.NoError(t, err)
+
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		sr, err := r.Stream(cctx, "x")
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Len(t, out, 3)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&counter.max))
+	})
+}
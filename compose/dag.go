@@ -53,6 +53,7 @@ type dagChannel struct {
 
 	ControlPredecessors map[string]dependencyState
 	Values              map[string]any
+	Arrivals            []string
 	DataPredecessors    map[string]bool // if all dependencies have been skipped, indirect dependencies won't effect.
 	Skipped             bool
 
@@ -61,6 +62,8 @@ type dagChannel struct {
 
 func (ch *dagChannel) setMergeConfig(cfg FanInMergeConfig) {
 	ch.mergeConfig.StreamMergeWithSourceEOF = cfg.StreamMergeWithSourceEOF
+	ch.mergeConfig.Reducer = cfg.Reducer
+	ch.mergeConfig.StreamInterleave = cfg.StreamInterleave
 }
 
 func (ch *dagChannel) load(c channel) error {
@@ -72,6 +75,7 @@ func (ch *dagChannel) load(c channel) error {
 	ch.DataPredecessors = dc.DataPredecessors
 	ch.Skipped = dc.Skipped
 	ch.Values = dc.Values
+	ch.Arrivals = dc.Arrivals
 	return nil
 }
 
@@ -87,6 +91,7 @@ func (ch *dagChannel) reportValues(ins map[string]any) error {
 		ch.DataPredecessors[k] = true
 		ch.Values[k] = v
 	}
+	ch.Arrivals = reportArrivals(ch.Arrivals, ins)
 	return nil
 }
 
@@ -148,6 +153,7 @@ func (ch *dagChannel) get(isStream bool, name string, edgeHandler *edgeHandlerMa
 
 	defer func() {
 		ch.Values = make(map[string]any)
+		ch.Arrivals = nil
 		for k := range ch.ControlPredecessors {
 			ch.ControlPredecessors[k] = dependencyStateWaiting
 		}
@@ -159,8 +165,8 @@ func (ch *dagChannel) get(isStream bool, name string, edgeHandler *edgeHandlerMa
 	valueList := make([]any, len(ch.Values))
 	names := make([]string, len(ch.Values))
 	i := 0
-	for k, value := range ch.Values {
-		resolvedV, err := edgeHandler.handle(k, name, value, isStream)
+	for _, k := range arrivalOrder(ch.Values, ch.Arrivals) {
+		resolvedV, err := edgeHandler.handle(k, name, ch.Values[k], isStream)
 		if err != nil {
 			return nil, false, err
 		}
@@ -182,6 +188,8 @@ func (ch *dagChannel) get(isStream bool, name string, edgeHandler *edgeHandlerMa
 	mergeOpts := &mergeOptions{
 		streamMergeWithSourceEOF: ch.mergeConfig.StreamMergeWithSourceEOF,
 		names:                    names,
+		reducer:                  ch.mergeConfig.Reducer,
+		streamInterleave:         ch.mergeConfig.StreamInterleave,
 	}
 	v, err := mergeValues(valueList, mergeOpts)
 	if err != nil {
@@ -0,0 +1,326 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DryRunStub generates the output of a stubbed component node from its input,
+// outputType is the output type of the node.
+type DryRunStub func(ctx context.Context, input any, outputType reflect.Type) (any, error)
+
+// DryRunReport is the result of DryRun.
+type DryRunReport struct {
+	// Output is the output of the graph, nil if the run fails.
+	Output any
+	// Nodes are the executions of the nodes, including the ones in subgraphs, ordered by the time they start.
+	Nodes []*DryRunNode
+	// Branches are the decisions made by the branches, ordered by the time they are made.
+	Branches []*DryRunBranch
+}
+
+// DryRunNode is an execution of a node in DryRun.
+type DryRunNode struct {
+	// NodePath is the path of the node from the root graph.
+	NodePath *NodePath
+	// Step is the step of the graph run in which the node is executed, counted from 0.
+	Step      int
+	Component components.Component
+	// Stubbed reports whether the node returns the output of a stub or a fixture instead of being executed.
+	Stubbed bool
+	// Input is the input of the node, which is the result of the field mappings to the node if any.
+	Input  any
+	Output any
+	Err    error
+}
+
+// DryRunBranch is a decision of a branch in DryRun.
+type DryRunBranch struct {
+	// NodePath is the path of the start node of the branch from the root graph.
+	NodePath *NodePath
+	// Selected are the keys of the end nodes selected by the branch.
+	Selected []string
+}
+
+type dryRunOptions struct {
+	compileOptions []GraphCompileOption
+	runOptions     []Option
+	fixtures       map[string]any
+	stubs          map[components.Component]DryRunStub
+}
+
+// DryRunOption configures DryRun.
+type DryRunOption func(*dryRunOptions)
+
+// WithDryRunFixture makes the node return the output instead of being executed,
+// which must be of the output type of the node. Any node can be given a fixture, including Lambda nodes.
+func WithDryRunFixture(nodePath *NodePath, output any) DryRunOption {
+	return func(o *dryRunOptions) {
+		o.fixtures[nodePathKey(nodePath.GetPath())] = output
+	}
+}
+
+// WithDryRunStub replaces the default stub of the component nodes of the type, e.g. components.ComponentOfChatModel.
+func WithDryRunStub(component components.Component, stub DryRunStub) DryRunOption {
+	return func(o *dryRunOptions) {
+		o.stubs[component] = stub
+	}
+}
+
+// WithDryRunCompileOptions sets the options to compile the graph with.
+func WithDryRunCompileOptions(opts ...GraphCompileOption) DryRunOption {
+	return func(o *dryRunOptions) {
+		o.compileOptions = append(o.compileOptions, opts...)
+	}
+}
+
+// WithDryRunOptions sets the options to invoke the compiled graph with.
+func WithDryRunOptions(opts ...Option) DryRunOption {
+	return func(o *dryRunOptions) {
+		o.runOptions = append(o.runOptions, opts...)
+	}
+}
+
+// DryRun compiles the graph and invokes it with every component node replaced by a stub,
+// and reports the nodes executed, the decisions of the branches and the inputs the nodes receive from field mappings.
+// Lambda, passthrough and subgraph nodes are executed as they are, and the nodes of the subgraphs are stubbed as well.
+// Stubs are chosen by the component type of the node, the default ones return:
+//   - ChatModel: an empty assistant message
+//   - ToolsNode: a tool message with empty content for each tool call of the input message
+//   - other components: the zero value of the output type, where pointers to structs, slices and maps are empty but not nil
+//
+// so the wiring of a graph, e.g. a Workflow, can be checked in unit tests without any model.
+// The graph is compiled by DryRun, after which it can no longer be modified.
+// When the run fails, the report of the nodes executed so far is returned along with the error.
+// e.g.
+//
+//	report, err := compose.DryRun[map[string]any, *schema.Message](ctx, wf, input,
+//		compose.WithDryRunFixture(compose.NewNodePath("intent_model"), schema.AssistantMessage("search", nil)))
+func DryRun[I, O any](ctx context.Context, g AnyGraph, input I, opts ...DryRunOption) (*DryRunReport, error) {
+	o := &dryRunOptions{
+		fixtures: make(map[string]any),
+		stubs:    make(map[components.Component]DryRunStub),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	r, err := compileAnyGraph[I, O](ctx, g, append(o.compileOptions, withDryRunStubs())...)
+	if err != nil {
+		return nil, err
+	}
+
+	dr := &dryRunner{options: o, report: &DryRunReport{}}
+	out, err := r.Invoke(context.WithValue(ctx, dryRunnerKey{}, dr), input, o.runOptions...)
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if err != nil {
+		return dr.report, err
+	}
+	dr.report.Output = out
+	return dr.report, nil
+}
+
+// withDryRunStubs makes the nodes of the graph and its subgraphs stubbable by DryRun.
+func withDryRunStubs() GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.dryRun = true
+	}
+}
+
+type dryRunnerKey struct{}
+
+type dryRunner struct {
+	options *dryRunOptions
+
+	mu     sync.Mutex
+	report *DryRunReport
+}
+
+func getDryRunner(ctx context.Context) (*dryRunner, *NodePath, bool) {
+	dr, ok := ctx.Value(dryRunnerKey{}).(*dryRunner)
+	if !ok {
+		return nil, nil, false
+	}
+	path, ok := getNodePath(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+	return dr, path, true
+}
+
+func (dr *dryRunner) addNode(n *DryRunNode) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.report.Nodes = append(dr.report.Nodes, n)
+}
+
+func (dr *dryRunner) finishNode(n *DryRunNode, output any, err error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	n.Output, n.Err = output, err
+}
+
+// recordDryRunBranch records the decision of the branch of the node of the graph running in ctx.
+func recordDryRunBranch(ctx context.Context, nodeKey string, selected []string) {
+	dr, ok := ctx.Value(dryRunnerKey{}).(*dryRunner)
+	if !ok {
+		return
+	}
+	var path []string
+	if graphPath, ok := getNodePath(ctx); ok {
+		path = append(path, graphPath.GetPath()...)
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.report.Branches = append(dr.report.Branches, &DryRunBranch{
+		NodePath: NewNodePath(append(path, nodeKey)...),
+		Selected: append([]string{}, selected...),
+	})
+}
+
+// stub returns the stub output of the node, false if the node isn't stubbed.
+func (dr *dryRunner) stub(ctx context.Context, path *NodePath, meta *executorMeta, input any, outputType reflect.Type) (any, bool, error) {
+	if fixture, ok := dr.options.fixtures[nodePathKey(path.GetPath())]; ok {
+		return fixture, true, nil
+	}
+	if meta == nil {
+		return nil, false, nil
+	}
+	switch meta.component {
+	case ComponentOfLambda, ComponentOfPassthrough, ComponentOfGraph, ComponentOfChain, ComponentOfWorkflow:
+		return nil, false, nil
+	}
+
+	stub, ok := dr.options.stubs[meta.component]
+	if !ok {
+		stub = defaultDryRunStub(meta.component)
+	}
+	output, err := stub(ctx, input, outputType)
+	return output, true, err
+}
+
+func defaultDryRunStub(c components.Component) DryRunStub {
+	switch c {
+	case components.ComponentOfChatModel:
+		return func(_ context.Context, _ any, _ reflect.Type) (any, error) {
+			return &schema.Message{Role: schema.Assistant}, nil
+		}
+	case ComponentOfToolsNode:
+		return func(_ context.Context, input any, _ reflect.Type) (any, error) {
+			msg, ok := input.(*schema.Message)
+			if !ok || msg == nil {
+				return []*schema.Message{}, nil
+			}
+			ret := make([]*schema.Message, 0, len(msg.ToolCalls))
+			for _, tc := range msg.ToolCalls {
+				ret = append(ret, schema.ToolMessage("", tc.ID, schema.WithToolName(tc.Function.Name)))
+			}
+			return ret, nil
+		}
+	default:
+		return func(_ context.Context, _ any, outputType reflect.Type) (any, error) {
+			return emptyValueOf(outputType), nil
+		}
+	}
+}
+
+// emptyValueOf returns the zero value of the type, except that pointers to structs, slices and maps are empty but not nil.
+func emptyValueOf(t reflect.Type) any {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			return reflect.New(t.Elem()).Interface()
+		}
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0).Interface()
+	case reflect.Map:
+		return reflect.MakeMap(t).Interface()
+	}
+	return reflect.Zero(t).Interface()
+}
+
+// dryRunComposableRunnable wraps the node's runnable so that it's replaced by its stub in DryRun,
+// and its execution is recorded.
+func dryRunComposableRunnable(r *composableRunnable) *composableRunnable {
+	wrapper := *r
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (any, error) {
+		dr, path, ok := getDryRunner(ctx)
+		if !ok {
+			return i(ctx, input, opts...)
+		}
+
+		n := &DryRunNode{NodePath: path, Input: input}
+		n.Step, _ = ctx.Value(nodeStepKey{}).(int)
+		if r.meta != nil {
+			n.Component = r.meta.component
+		}
+		dr.addNode(n)
+
+		output, stubbed, err := dr.stub(ctx, path, r.meta, input, r.outputType)
+		if !stubbed {
+			output, err = i(ctx, input, opts...)
+		} else if err == nil {
+			n.Stubbed = true
+			output, err = r.genericHelper.outputConverter.invoke(output)
+			if err != nil {
+				err = fmt.Errorf("stub of node %v returns unexpected output: %w", path.GetPath(), err)
+			}
+		}
+		dr.finishNode(n, output, err)
+		return output, err
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (streamReader, error) {
+		dr, path, ok := getDryRunner(ctx)
+		if !ok {
+			return t(ctx, input, opts...)
+		}
+		// DryRun invokes the graph, nodes are only run by Transform when the graph is streamed by a Lambda,
+		// in which case the input is passed through to the node without being recorded.
+		if _, ok = dr.options.fixtures[nodePathKey(path.GetPath())]; !ok && (r.meta == nil || r.meta.component == ComponentOfLambda) {
+			return t(ctx, input, opts...)
+		}
+
+		in, err := r.inputStreamConvertPair.concatStream(input)
+		if err != nil {
+			return nil, err
+		}
+		out, err := wrapper.i(ctx, in, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.genericHelper.outputConverter.transform(packStreamReader(schema.StreamReaderFromArray([]any{out}))), nil
+	}
+
+	return &wrapper
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("graph", func(t *testing.T) {
+		toolsNode, err := NewToolNode(ctx, &ToolsNodeConfig{})
+		require.NoError(t, err)
+
+		g := NewGraph[[]*schema.Message, *schema.Message]()
+		_ = g.AddChatModelNode("model", &testModel{})
+		_ = g.AddToolsNode("tools", toolsNode)
+		_ = g.AddLambdaNode("last", InvokableLambda(func(ctx context.Context, in []*schema.Message) (*schema.Message, error) {
+			return in[len(in)-1], nil
+		}))
+		_ = g.AddEdge(START, "model")
+		_ = g.AddBranch("model", NewGraphBranch(func(ctx context.Context, in *schema.Message) (string, error) {
+			if len(in.ToolCalls) > 0 {
+				return "tools", nil
+			}
+			return END, nil
+		}, map[string]bool{"tools": true, END: true}))
+		_ = g.AddEdge("tools", "last")
+		_ = g.AddEdge("last", END)
+
+		toolCall := schema.AssistantMessage("", []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "search"}}})
+		report, err := DryRun[[]*schema.Message, *schema.Message](ctx, g, []*schema.Message{schema.UserMessage("hi")},
+			WithDryRunFixture(NewNodePath("model"), toolCall))
+		require.NoError(t, err)
+
+		expected := schema.ToolMessage("", "call_1", schema.WithToolName("search"))
+		assert.Equal(t, expected, report.Output)
+		assert.Equal(t, []*DryRunBranch{{NodePath: NewNodePath("model"), Selected: []string{"tools"}}}, report.Branches)
+		require.Len(t, report.Nodes, 3)
+		assert.Equal(t, &DryRunNode{NodePath: NewNodePath("model"), Step: 0, Component: components.ComponentOfChatModel,
+			Stubbed: true, Input: []*schema.Message{schema.UserMessage("hi")}, Output: toolCall}, report.Nodes[0])
+		assert.Equal(t, &DryRunNode{NodePath: NewNodePath("tools"), Step: 1, Component: ComponentOfToolsNode,
+			Stubbed: true, Input: toolCall, Output: []*schema.Message{expected}}, report.Nodes[1])
+		assert.Equal(t, &DryRunNode{NodePath: NewNodePath("last"), Step: 2, Component: ComponentOfLambda,
+			Input: []*schema.Message{expected}, Output: expected}, report.Nodes[2])
+
+		// without the fixture, the default stub of the chat model makes no tool call
+		report, err = DryRun[[]*schema.Message, *schema.Message](ctx, g, []*schema.Message{schema.UserMessage("hi")})
+		require.NoError(t, err)
+		assert.Equal(t, &schema.Message{Role: schema.Assistant}, report.Output)
+		assert.Equal(t, []*DryRunBranch{{NodePath: NewNodePath("model"), Selected: []string{END}}}, report.Branches)
+		assert.Len(t, report.Nodes, 1)
+	})
+
+	t.Run("workflow field mapping", func(t *testing.T) {
+		type in struct {
+			Query string
+			Topic string
+		}
+		sub := NewGraph[[]*schema.Message, *schema.Message]()
+		_ = sub.AddChatModelNode("model", &testModel{})
+		_ = sub.AddEdge(START, "model")
+		_ = sub.AddEdge("model", END)
+
+		wf := NewWorkflow[in, map[string]any]()
+		wf.AddLambdaNode("prompt", InvokableLambda(func(ctx context.Context, in map[string]any) ([]*schema.Message, error) {
+			return []*schema.Message{schema.UserMessage(in["topic"].(string) + ": " + in["query"].(string))}, nil
+		})).
+			AddInput(START, MapFields("Query", "query"), MapFields("Topic", "topic"))
+		wf.AddGraphNode("agent", sub).AddInput("prompt")
+		wf.End().
+			AddInput("agent", MapFields("Content", "answer")).
+			AddInput(START, MapFields("Query", "query"))
+
+		report, err := DryRun[in, map[string]any](ctx, wf, in{Query: "weather", Topic: "travel"},
+			WithDryRunStub(components.ComponentOfChatModel, func(ctx context.Context, input any, outputType reflect.Type) (any, error) {
+				msgs := input.([]*schema.Message)
+				return schema.AssistantMessage("re "+msgs[0].Content, nil), nil
+			}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"answer": "re travel: weather", "query": "weather"}, report.Output)
+		require.Len(t, report.Nodes, 3)
+		assert.Equal(t, NewNodePath("prompt"), report.Nodes[0].NodePath)
+		assert.Equal(t, map[string]any{"query": "weather", "topic": "travel"}, report.Nodes[0].Input)
+		assert.False(t, report.Nodes[0].Stubbed)
+		assert.Equal(t, NewNodePath("agent"), report.Nodes[1].NodePath)
+		assert.Equal(t, ComponentOfGraph, report.Nodes[1].Component)
+		assert.Equal(t, NewNodePath("agent", "model"), report.Nodes[2].NodePath)
+		assert.True(t, report.Nodes[2].Stubbed)
+	})
+
+	t.Run("default stubs", func(t *testing.T) {
+		assert.Equal(t, &schema.Document{}, emptyValueOf(reflect.TypeOf(&schema.Document{})))
+		assert.Equal(t, []*schema.Document{}, emptyValueOf(reflect.TypeOf([]*schema.Document{})))
+		assert.Equal(t, map[string]any{}, emptyValueOf(reflect.TypeOf(map[string]any{})))
+		assert.Equal(t, "", emptyValueOf(reflect.TypeOf("")))
+		assert.Nil(t, emptyValueOf(reflect.TypeOf((*any)(nil)).Elem()))
+	})
+
+	t.Run("fixture of wrong type", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, in string) (string, error) { return in, nil }))
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", END)
+
+		report, err := DryRun[string, string](ctx, g, "x", WithDryRunFixture(NewNodePath("a"), 1))
+		assert.Error(t, err)
+		require.NotNil(t, report)
+		require.Len(t, report.Nodes, 1)
+		assert.Error(t, report.Nodes[0].Err)
+
+		report, err = DryRun[string, string](ctx, g, "x", WithDryRunFixture(NewNodePath("a"), "y"))
+		require.NoError(t, err)
+		assert.Equal(t, "y", report.Output)
+	})
+}
@@ -17,15 +17,53 @@
 package compose
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // ErrExceedMaxSteps graph will throw this error when the number of steps exceeds the maximum number of steps.
 var ErrExceedMaxSteps = errors.New("exceeds max steps")
 
+// NodeTimeoutError is returned when a graph node doesn't finish within its timeout,
+// which is set by WithNodeTimeouts, WithNodeTimeout, or derived from the run deadline by WithDeadlineSplit.
+// errors.Is(err, context.DeadlineExceeded) reports true for it.
+type NodeTimeoutError struct {
+	// NodePath is the path of the node from the root graph.
+	NodePath NodePath
+	Timeout  time.Duration
+}
+
+func (e *NodeTimeoutError) Error() string {
+	return fmt.Sprintf("node %v timeout after %v", e.NodePath.path, e.Timeout)
+}
+
+func (e *NodeTimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// NodeError is the failure of a graph node routed to its error handler node, see AddErrorEdge and AddErrorBranch.
+// The error handler node receives it as its input, and so does the condition of an error branch.
+type NodeError struct {
+	// NodePath is the path of the failed node from the root graph.
+	NodePath NodePath
+	// Input is the input of the failed node, in stream mode the concatenated input stream.
+	Input any
+	// Err is the error returned by the failed node.
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %v failed: %v", e.NodePath.path, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
 func newUnexpectedInputTypeErr(expected reflect.Type, got reflect.Type) error {
 	return fmt.Errorf("unexpected input type. expected: %v, got: %v", expected, got)
 }
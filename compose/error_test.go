@@ -135,3 +135,169 @@ func TestContextCancelDuringRun(t *testing.T) {
 	unwrappedErr := ie.Unwrap()
 	assert.ErrorIs(t, unwrappedErr, context.Canceled)
 }
+
+func newErrorEdgeTestGraph(t *testing.T, fail bool, handled *[]*NodeError, opts ...GraphCompileOption) Runnable[string, string] {
+	errRetrieve := errors.New("retrieve fail")
+	g := NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("retriever", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		if fail {
+			return "", errRetrieve
+		}
+		return input + "_doc", nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("fallback", InvokableLambda(func(ctx context.Context, input *NodeError) (string, error) {
+		*handled = append(*handled, input)
+		return input.Input.(string) + "_fallback", nil
+	})))
+	assert.NoError(t, g.AddLambdaNode("answer", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "_answer", nil
+	})))
+	assert.NoError(t, g.AddEdge(START, "retriever"))
+	assert.NoError(t, g.AddEdge("retriever", "answer"))
+	assert.NoError(t, g.AddErrorEdge("retriever", "fallback"))
+	assert.NoError(t, g.AddEdge("fallback", "answer"))
+	assert.NoError(t, g.AddEdge("answer", END))
+	r, err := g.Compile(context.Background(), opts...)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestErrorEdge(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mode := range []NodeTriggerMode{AnyPredecessor, AllPredecessor} {
+		t.Run(string(mode), func(t *testing.T) {
+			var handled []*NodeError
+			out, err := newErrorEdgeTestGraph(t, false, &handled, WithNodeTriggerMode(mode)).Invoke(ctx, "q")
+			assert.NoError(t, err)
+			assert.Equal(t, "q_doc_answer", out)
+			assert.Empty(t, handled)
+
+			out, err = newErrorEdgeTestGraph(t, true, &handled, WithNodeTriggerMode(mode)).Invoke(ctx, "q")
+			assert.NoError(t, err)
+			assert.Equal(t, "q_fallback_answer", out)
+			assert.Len(t, handled, 1)
+			assert.Equal(t, []string{"retriever"}, handled[0].NodePath.GetPath())
+			assert.Equal(t, "q", handled[0].Input)
+			assert.ErrorContains(t, handled[0], "retrieve fail")
+		})
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		var handled []*NodeError
+		sr, err := newErrorEdgeTestGraph(t, true, &handled).Transform(ctx, schema.StreamReaderFromArray([]string{"a", "b"}))
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "ab_fallback_answer", out)
+		assert.Equal(t, "ab", handled[0].Input)
+	})
+
+	t.Run("error branch", func(t *testing.T) {
+		errUnavailable := errors.New("unavailable")
+		newGraph := func(failWith error) Runnable[string, string] {
+			g := NewGraph[string, string]()
+			assert.NoError(t, g.AddLambdaNode("retriever", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+				if failWith != nil {
+					return "", failWith
+				}
+				return input + "_doc", nil
+			})))
+			assert.NoError(t, g.AddLambdaNode("fallback", InvokableLambda(func(ctx context.Context, input *NodeError) (string, error) {
+				return input.Input.(string) + "_fallback", nil
+			})))
+			assert.NoError(t, g.AddLambdaNode("apology", InvokableLambda(func(ctx context.Context, input error) (string, error) {
+				return "sorry", nil
+			})))
+			assert.NoError(t, g.AddEdge(START, "retriever"))
+			assert.NoError(t, g.AddEdge("retriever", END))
+			assert.NoError(t, g.AddErrorBranch("retriever", NewGraphBranch(func(ctx context.Context, in *NodeError) (string, error) {
+				if errors.Is(in, errUnavailable) {
+					return "fallback", nil
+				}
+				return "apology", nil
+			}, map[string]bool{"fallback": true, "apology": true})))
+			assert.NoError(t, g.AddEdge("fallback", END))
+			assert.NoError(t, g.AddEdge("apology", END))
+			r, err := g.Compile(ctx, WithNodeTriggerMode(AllPredecessor))
+			assert.NoError(t, err)
+			return r
+		}
+
+		out, err := newGraph(nil).Invoke(ctx, "q")
+		assert.NoError(t, err)
+		assert.Equal(t, "q_doc", out)
+		out, err = newGraph(errUnavailable).Invoke(ctx, "q")
+		assert.NoError(t, err)
+		assert.Equal(t, "q_fallback", out)
+		out, err = newGraph(errors.New("bad query")).Invoke(ctx, "q")
+		assert.NoError(t, err)
+		assert.Equal(t, "sorry", out)
+
+		sr, err := newGraph(errUnavailable).Stream(ctx, "q")
+		assert.NoError(t, err)
+		out, err = concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "q_fallback", out)
+	})
+
+	t.Run("error branch type mismatch", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		})))
+		assert.NoError(t, g.AddLambdaNode("2", InvokableLambda(func(ctx context.Context, input error) (string, error) {
+			return "", nil
+		})))
+		assert.ErrorContains(t, g.AddErrorBranch("1", NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+			return "2", nil
+		}, map[string]bool{"2": true})), "doesn't accept *NodeError")
+	})
+
+	t.Run("handler with other predecessors", func(t *testing.T) {
+		for name, addOther := range map[string]func(g *Graph[string, string]) error{
+			"edge": func(g *Graph[string, string]) error {
+				return g.AddEdge("other", "fallback")
+			},
+			"branch": func(g *Graph[string, string]) error {
+				return g.AddBranch("other", NewGraphBranch(func(ctx context.Context, in any) (string, error) {
+					return "fallback", nil
+				}, map[string]bool{"fallback": true, END: true}))
+			},
+			"error edge": func(g *Graph[string, string]) error {
+				return g.AddErrorEdge("other", "fallback")
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				g := NewGraph[string, string]()
+				for _, key := range []string{"retriever", "other"} {
+					assert.NoError(t, g.AddLambdaNode(key, InvokableLambda(func(ctx context.Context, input any) (any, error) {
+						return input, nil
+					})))
+				}
+				assert.NoError(t, g.AddLambdaNode("fallback", InvokableLambda(func(ctx context.Context, input any) (string, error) {
+					return "", nil
+				})))
+				assert.NoError(t, g.AddEdge(START, "retriever"))
+				assert.NoError(t, g.AddEdge("retriever", "other"))
+				assert.NoError(t, g.AddEdge("other", END))
+				assert.NoError(t, g.AddErrorEdge("retriever", "fallback"))
+				assert.NoError(t, addOther(g))
+				assert.NoError(t, g.AddEdge("fallback", END))
+				_, err := g.Compile(ctx)
+				assert.ErrorContains(t, err, "can't have other predecessors")
+			})
+		}
+	})
+
+	t.Run("handler type mismatch", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		})))
+		assert.NoError(t, g.AddLambdaNode("2", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		})))
+		assert.ErrorContains(t, g.AddErrorEdge("1", "2"), "doesn't accept *NodeError")
+	})
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"sort"
+
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// FanInReducer merges the values a node receives from multiple predecessors in the order they arrive,
+// set by FanInMergeConfig.Reducer in place of the merge function registered for the type.
+// Values arriving together, e.g. from predecessors finishing in the same super step of a DAG, are ordered by the keys of the predecessors.
+type FanInReducer struct {
+	// the input type of the node, nil for any type
+	typ          reflect.Type
+	reduce       func(values []any) (any, error)
+	reduceStream func(streams []streamReader, interleave bool) (streamReader, error)
+}
+
+// AppendReducer concatenates the slices from the predecessors in the order they arrive.
+// In stream mode, the streams are concatenated one after another, or interleaved if FanInMergeConfig.StreamInterleave is set.
+func AppendReducer[T any]() *FanInReducer {
+	return &FanInReducer{
+		typ: generic.TypeOf[[]T](),
+		reduce: func(values []any) (any, error) {
+			var ret []T
+			for _, v := range values {
+				s, err := castFanInValue[[]T](v)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, s...)
+			}
+			return ret, nil
+		},
+		reduceStream: func(streams []streamReader, interleave bool) (streamReader, error) {
+			return appendStreams[[]T](streams, interleave)
+		},
+	}
+}
+
+// MessagesReducer concatenates the message lists from the predecessors in the order they arrive,
+// dropping the messages whose IDs have appeared before, so that e.g. the history passed through several branches is kept once.
+// id returns the ID of the message, messages with empty IDs are never dropped.
+// If id is nil, a message is identified by its pointer.
+// In stream mode, the streams are concatenated one after another, or interleaved if FanInMergeConfig.StreamInterleave is set.
+func MessagesReducer(id func(*schema.Message) string) *FanInReducer {
+	newFilter := func() func(msgs []*schema.Message) []*schema.Message {
+		seenIDs := make(map[string]bool)
+		seenMsgs := make(map[*schema.Message]bool)
+		return func(msgs []*schema.Message) []*schema.Message {
+			ret := make([]*schema.Message, 0, len(msgs))
+			for _, msg := range msgs {
+				if id == nil {
+					if seenMsgs[msg] {
+						continue
+					}
+					seenMsgs[msg] = true
+				} else if msgID := id(msg); msgID != "" {
+					if seenIDs[msgID] {
+						continue
+					}
+					seenIDs[msgID] = true
+				}
+				ret = append(ret, msg)
+			}
+			return ret
+		}
+	}
+
+	return &FanInReducer{
+		typ: generic.TypeOf[[]*schema.Message](),
+		reduce: func(values []any) (any, error) {
+			filter := newFilter()
+			var ret []*schema.Message
+			for _, v := range values {
+				msgs, err := castFanInValue[[]*schema.Message](v)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, filter(msgs)...)
+			}
+			return ret, nil
+		},
+		reduceStream: func(streams []streamReader, interleave bool) (streamReader, error) {
+			merged, err := appendStreams[[]*schema.Message](streams, interleave)
+			if err != nil {
+				return nil, err
+			}
+			sr, ok := unpackStreamReader[[]*schema.Message](merged)
+			if !ok {
+				return nil, fmt.Errorf("(fan-in reducer) unexpected chunk type. expect: %v, got: %v",
+					generic.TypeOf[[]*schema.Message](), merged.getChunkType())
+			}
+			filter := newFilter()
+			return packStreamReader(schema.StreamReaderWithConvert(sr, func(msgs []*schema.Message) ([]*schema.Message, error) {
+				if msgs = filter(msgs); len(msgs) == 0 {
+					return nil, schema.ErrNoValue
+				}
+				return msgs, nil
+			})), nil
+		},
+	}
+}
+
+// LastWriterWinsReducer takes the value of the predecessor arriving last, discarding the others.
+// In stream mode, it takes the stream arriving last.
+func LastWriterWinsReducer() *FanInReducer {
+	return &FanInReducer{
+		reduce: func(values []any) (any, error) {
+			return values[len(values)-1], nil
+		},
+		reduceStream: func(streams []streamReader, _ bool) (streamReader, error) {
+			for _, s := range streams[:len(streams)-1] {
+				s.close()
+			}
+			return streams[len(streams)-1], nil
+		},
+	}
+}
+
+// FirstArrivalWinsReducer takes the value of the predecessor arriving first, discarding the others.
+// In stream mode, it takes the stream arriving first.
+func FirstArrivalWinsReducer() *FanInReducer {
+	return &FanInReducer{
+		reduce: func(values []any) (any, error) {
+			return values[0], nil
+		},
+		reduceStream: func(streams []streamReader, _ bool) (streamReader, error) {
+			for _, s := range streams[1:] {
+				s.close()
+			}
+			return streams[0], nil
+		},
+	}
+}
+
+// CustomReducer folds the values from the predecessors in the order they arrive by reduce,
+// whose existing is the result so far, starting with the value arriving first.
+// In stream mode, each stream is concatenated before being folded, and the result is sent as a single chunk.
+func CustomReducer[T any](reduce func(existing, incoming T) (T, error)) *FanInReducer {
+	fold := func(values []T) (T, error) {
+		ret := values[0]
+		for _, v := range values[1:] {
+			var err error
+			ret, err = reduce(ret, v)
+			if err != nil {
+				return ret, err
+			}
+		}
+		return ret, nil
+	}
+
+	return &FanInReducer{
+		typ: generic.TypeOf[T](),
+		reduce: func(values []any) (any, error) {
+			ts := make([]T, 0, len(values))
+			for _, v := range values {
+				t, err := castFanInValue[T](v)
+				if err != nil {
+					return nil, err
+				}
+				ts = append(ts, t)
+			}
+			return fold(ts)
+		},
+		reduceStream: func(streams []streamReader, _ bool) (streamReader, error) {
+			srs, err := unpackFanInStreams[T](streams)
+			if err != nil {
+				return nil, err
+			}
+			sr, sw := schema.Pipe[T](1)
+			go func() {
+				defer func() {
+					if e := recover(); e != nil {
+						_ = sw.Send(generic.NewInstance[T](), safe.NewPanicErr(e, debug.Stack()))
+					}
+					sw.Close()
+				}()
+
+				ts := make([]T, 0, len(srs))
+				for i, s := range srs {
+					t, err := concatStreamReader(s)
+					if err != nil {
+						for _, rest := range srs[i+1:] {
+							rest.Close()
+						}
+						_ = sw.Send(t, err)
+						return
+					}
+					ts = append(ts, t)
+				}
+				_ = sw.Send(fold(ts))
+			}()
+			return packStreamReader(sr), nil
+		},
+	}
+}
+
+func (r *FanInReducer) merge(vs []any, streamInterleave bool) (any, error) {
+	if _, ok := vs[0].(streamReader); !ok {
+		return r.reduce(vs)
+	}
+
+	ss := make([]streamReader, 0, len(vs))
+	for _, v := range vs {
+		s, ok := v.(streamReader)
+		if !ok {
+			return nil, fmt.Errorf("(fan-in reducer) unexpected type. expect stream, got: %v", reflect.TypeOf(v))
+		}
+		ss = append(ss, s)
+	}
+	return r.reduceStream(ss, streamInterleave)
+}
+
+func castFanInValue[T any](v any) (T, error) {
+	t, ok := v.(T)
+	if !ok && v != nil {
+		return t, fmt.Errorf("(fan-in reducer) unexpected type. expect: %v, got: %v", generic.TypeOf[T](), reflect.TypeOf(v))
+	}
+	return t, nil
+}
+
+func unpackFanInStreams[T any](streams []streamReader) ([]*schema.StreamReader[T], error) {
+	ret := make([]*schema.StreamReader[T], 0, len(streams))
+	for _, s := range streams {
+		sr, ok := unpackStreamReader[T](s)
+		if !ok {
+			return nil, fmt.Errorf("(fan-in reducer) unexpected chunk type. expect: %v, got: %v", generic.TypeOf[T](), s.getChunkType())
+		}
+		ret = append(ret, sr)
+	}
+	return ret, nil
+}
+
+// appendStreams concatenates the streams one after another, or interleaves them.
+func appendStreams[T any](streams []streamReader, interleave bool) (streamReader, error) {
+	srs, err := unpackFanInStreams[T](streams)
+	if err != nil {
+		return nil, err
+	}
+	if interleave {
+		return packStreamReader(schema.MergeStreamReaders(srs)), nil
+	}
+	sr, sw := schema.Pipe[T](0)
+	go func() {
+		i := 0
+		defer func() {
+			if e := recover(); e != nil {
+				_ = sw.Send(generic.NewInstance[T](), safe.NewPanicErr(e, debug.Stack()))
+			}
+			for ; i < len(srs); i++ {
+				srs[i].Close()
+			}
+			sw.Close()
+		}()
+
+		for ; i < len(srs); i++ {
+			for {
+				chunk, err := srs[i].Recv()
+				if err == io.EOF {
+					break
+				}
+				if sw.Send(chunk, err) || err != nil {
+					return
+				}
+			}
+			srs[i].Close()
+		}
+	}()
+	return packStreamReader(sr), nil
+}
+
+// arrivalOrder returns the keys of the values in the order they arrive,
+// where arrivals are the keys in the order they're reported.
+func arrivalOrder(values map[string]any, arrivals []string) []string {
+	ret := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, k := range arrivals {
+		if _, ok := values[k]; ok && !seen[k] {
+			ret = append(ret, k)
+			seen[k] = true
+		}
+	}
+	if len(ret) == len(values) {
+		return ret
+	}
+	// values restored from checkpoints written before the arrivals were recorded
+	var rest []string
+	for k := range values {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(ret, rest...)
+}
+
+// reportArrivals appends the keys reported to the arrivals, a key reported again is moved to the end.
+func reportArrivals(arrivals []string, ins map[string]any) []string {
+	keys := make([]string, 0, len(ins))
+	for k := range ins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	reported := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		reported[k] = true
+	}
+	ret := arrivals[:0]
+	for _, k := range arrivals {
+		if !reported[k] {
+			ret = append(ret, k)
+		}
+	}
+	return append(ret, keys...)
+}
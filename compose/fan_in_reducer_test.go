@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestFanInReducer(t *testing.T) {
+	stream := func(chunks ...[]int) streamReader {
+		return packStreamReader(schema.StreamReaderFromArray(chunks))
+	}
+
+	t.Run("append", func(t *testing.T) {
+		opts := &mergeOptions{reducer: AppendReducer[int]()}
+		v, err := mergeValues([]any{[]int{1}, []int{2, 3}, []int(nil)}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, v)
+
+		v, err = mergeValues([]any{stream([]int{1}, []int{2}), stream([]int{3})}, opts)
+		require.NoError(t, err)
+		sr, ok := unpackStreamReader[[]int](v.(streamReader))
+		require.True(t, ok)
+		var chunks [][]int
+		for {
+			chunk, err := sr.Recv()
+			if err != nil {
+				break
+			}
+			chunks = append(chunks, chunk)
+		}
+		assert.Equal(t, [][]int{{1}, {2}, {3}}, chunks)
+
+		_, err = mergeValues([]any{[]int{1}, []string{"a"}}, opts)
+		assert.Error(t, err)
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		history := schema.UserMessage("hi")
+		a, b := schema.AssistantMessage("a", nil), schema.AssistantMessage("b", nil)
+		v, err := mergeValues([]any{[]*schema.Message{history, a}, []*schema.Message{history, b}},
+			&mergeOptions{reducer: MessagesReducer(nil)})
+		require.NoError(t, err)
+		assert.Equal(t, []*schema.Message{history, a, b}, v)
+
+		byContent := MessagesReducer(func(m *schema.Message) string { return m.Content })
+		v, err = mergeValues([]any{
+			[]*schema.Message{schema.UserMessage("hi"), a},
+			[]*schema.Message{schema.UserMessage("hi"), schema.AssistantMessage("a", nil), b},
+		}, &mergeOptions{reducer: byContent})
+		require.NoError(t, err)
+		assert.Equal(t, []*schema.Message{schema.UserMessage("hi"), a, b}, v)
+
+		v, err = mergeValues([]any{
+			packStreamReader(schema.StreamReaderFromArray([][]*schema.Message{{history}, {a}})),
+			packStreamReader(schema.StreamReaderFromArray([][]*schema.Message{{history}, {b}})),
+		}, &mergeOptions{reducer: MessagesReducer(nil)})
+		require.NoError(t, err)
+		sr, _ := unpackStreamReader[[]*schema.Message](v.(streamReader))
+		var msgs []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, chunk...)
+		}
+		assert.Equal(t, []*schema.Message{history, a, b}, msgs)
+
+		// the input type of the node isn't checked against the reducer for an interface, e.g. any
+		for _, interleave := range []bool{false, true} {
+			_, err = mergeValues([]any{stream([]int{1}), stream([]int{2})},
+				&mergeOptions{reducer: MessagesReducer(nil), streamInterleave: interleave})
+			assert.ErrorContains(t, err, "unexpected chunk type")
+		}
+	})
+
+	t.Run("winner", func(t *testing.T) {
+		v, err := mergeValues([]any{1, 2, 3}, &mergeOptions{reducer: LastWriterWinsReducer()})
+		require.NoError(t, err)
+		assert.Equal(t, 3, v)
+		v, err = mergeValues([]any{1, 2, 3}, &mergeOptions{reducer: FirstArrivalWinsReducer()})
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		join := CustomReducer(func(existing, incoming string) (string, error) {
+			return existing + "," + incoming, nil
+		})
+		v, err := mergeValues([]any{"a", "b", "c"}, &mergeOptions{reducer: join})
+		require.NoError(t, err)
+		assert.Equal(t, "a,b,c", v)
+
+		v, err = mergeValues([]any{
+			packStreamReader(schema.StreamReaderFromArray([]string{"a", "b"})),
+			packStreamReader(schema.StreamReaderFromArray([]string{"c"})),
+		}, &mergeOptions{reducer: join})
+		require.NoError(t, err)
+		sr, _ := unpackStreamReader[string](v.(streamReader))
+		s, err := concatStreamReader(sr)
+		require.NoError(t, err)
+		assert.Equal(t, "ab,c", s)
+	})
+
+	t.Run("arrival order in graph", func(t *testing.T) {
+		ctx := context.Background()
+		appendNode := func(s string) *Lambda {
+			return InvokableLambda(func(ctx context.Context, in []string) ([]string, error) {
+				return append(append([]string{}, in...), s), nil
+			})
+		}
+		build := func() *Graph[[]string, []string] {
+			g := NewGraph[[]string, []string]()
+			_ = g.AddLambdaNode("fast", appendNode("fast"))
+			_ = g.AddLambdaNode("slow1", appendNode("slow1"))
+			_ = g.AddLambdaNode("slow2", appendNode("slow2"))
+			_ = g.AddEdge(START, "slow1")
+			_ = g.AddEdge(START, "fast")
+			_ = g.AddEdge("slow1", "slow2")
+			_ = g.AddEdge("slow2", END)
+			_ = g.AddEdge("fast", END)
+			return g
+		}
+
+		for reducer, expected := range map[*FanInReducer][]string{
+			AppendReducer[string]():   {"fast", "slow1", "slow2"},
+			LastWriterWinsReducer():   {"slow1", "slow2"},
+			FirstArrivalWinsReducer(): {"fast"},
+		} {
+			r, err := build().Compile(ctx, WithNodeTriggerMode(AllPredecessor), WithEagerExecutionDisabled(),
+				WithFanInMergeConfig(map[string]FanInMergeConfig{END: {Reducer: reducer}}))
+			require.NoError(t, err)
+			out, err := r.Invoke(ctx, nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected, out)
+
+			sr, err := r.Stream(ctx, nil)
+			require.NoError(t, err)
+			out = nil
+			for {
+				chunk, err := sr.Recv()
+				if err != nil {
+					break
+				}
+				out = append(out, chunk...)
+			}
+			assert.Equal(t, expected, out)
+		}
+
+		_, err := build().Compile(ctx, WithFanInMergeConfig(map[string]FanInMergeConfig{END: {Reducer: AppendReducer[int]()}}))
+		assert.ErrorContains(t, err, "fan-in reducer of node[end]")
+	})
+
+	t.Run("field mappings", func(t *testing.T) {
+		ctx := context.Background()
+		build := func() *Workflow[string, map[string]any] {
+			wf := NewWorkflow[string, map[string]any]()
+			wf.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+				return in + "_a", nil
+			})).AddInput(START)
+			wf.AddLambdaNode("b", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+				return in + "_b", nil
+			})).AddInput(START)
+			wf.End().AddInput("a", ToField("a")).AddInput("b", ToField("b"))
+			return wf
+		}
+
+		_, err := build().Compile(ctx, WithFanInMergeConfig(map[string]FanInMergeConfig{END: {Reducer: AppendReducer[string]()}}))
+		assert.ErrorContains(t, err, "the node has field mappings")
+
+		// the results of the mappings are merged
+		r, err := build().Compile(ctx, WithFanInMergeConfig(map[string]FanInMergeConfig{END: {
+			Reducer: CustomReducer(func(existing, incoming map[string]any) (map[string]any, error) {
+				ret := map[string]any{}
+				for k, v := range existing {
+					ret[k] = v
+				}
+				for k, v := range incoming {
+					ret[k] = v
+				}
+				return ret, nil
+			}),
+		}}))
+		require.NoError(t, err)
+		out, err := r.Invoke(ctx, "x")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "x_a", "b": "x_b"}, out)
+	})
+}
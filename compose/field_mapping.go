@@ -19,10 +19,13 @@ package compose
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"runtime/debug"
 	"strings"
 
+	"github.com/bytedance/sonic"
+
 	"github.com/cloudwego/eino/internal/generic"
 	"github.com/cloudwego/eino/internal/safe"
 	"github.com/cloudwego/eino/schema"
@@ -34,6 +37,22 @@ type FieldMapping struct {
 	to          string
 
 	customExtractor func(input any) (any, error)
+
+	defaultValue any
+	hasDefault   bool
+	predicate    *fieldPredicate
+	converter    *fieldConverter
+}
+
+type fieldPredicate struct {
+	fromType  reflect.Type
+	predicate func(any) (bool, error)
+}
+
+type fieldConverter struct {
+	fromType reflect.Type
+	toType   reflect.Type
+	convert  func(any) (any, error)
 }
 
 // String returns the string representation of the FieldMapping.
@@ -62,10 +81,14 @@ func (m *FieldMapping) String() string {
 // This is an exclusive mapping - once set, no other field mappings can be added since the successor input
 // has already been fully mapped.
 // Field: either the field of a struct, or the key of a map.
-func FromField(from string) *FieldMapping {
-	return &FieldMapping{
+func FromField(from string, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: from,
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // ToField creates a FieldMapping that maps the entire predecessor output to a single successor field.
@@ -82,11 +105,15 @@ func ToField(to string, opts ...FieldMappingOption) *FieldMapping {
 
 // MapFields creates a FieldMapping that maps a single predecessor field to a single successor field.
 // Field: either the field of a struct, or the key of a map.
-func MapFields(from, to string) *FieldMapping {
-	return &FieldMapping{
+func MapFields(from, to string, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: from,
 		to:   to,
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 func (m *FieldMapping) FromNodeKey() string {
@@ -106,7 +133,7 @@ func (m *FieldMapping) Equals(o *FieldMapping) bool {
 		return o == nil
 	}
 
-	if o == nil || m.customExtractor != nil || o.customExtractor != nil {
+	if o == nil || m.customExtractor != nil || o.customExtractor != nil || m.hasTransforms() || o.hasTransforms() {
 		return false
 	}
 
@@ -151,10 +178,14 @@ const pathSeparator = "\x1F"
 //	FromFieldPath(FieldPath{"user", "profile", "name"})
 //
 // Note: The field path elements must not contain the internal path separator character ('\x1F').
-func FromFieldPath(fromFieldPath FieldPath) *FieldMapping {
-	return &FieldMapping{
+func FromFieldPath(fromFieldPath FieldPath, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: fromFieldPath.join(),
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // ToFieldPath creates a FieldMapping that maps the entire predecessor output to a single successor field path.
@@ -186,11 +217,15 @@ func ToFieldPath(toFieldPath FieldPath, opts ...FieldMappingOption) *FieldMappin
 //	)
 //
 // Note: The field path elements must not contain the internal path separator character ('\x1F').
-func MapFieldPaths(fromFieldPath, toFieldPath FieldPath) *FieldMapping {
-	return &FieldMapping{
+func MapFieldPaths(fromFieldPath, toFieldPath FieldPath, opts ...FieldMappingOption) *FieldMapping {
+	fm := &FieldMapping{
 		from: fromFieldPath.join(),
 		to:   toFieldPath.join(),
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // FieldMappingOption is a functional option for configuring a FieldMapping.
@@ -205,6 +240,148 @@ func WithCustomExtractor(extractor func(input any) (any, error)) FieldMappingOpt
 	}
 }
 
+// WithDefault sets the value to map when the source field is missing,
+// i.e. the map key is not found or an intermediate value on the source path is nil.
+// The value is assigned to the target field as it is, so it must be assignable to the type of the target field.
+// In stream mode, the value is sent as the last chunk if the source field is missing in all chunks.
+func WithDefault(value any) FieldMappingOption {
+	return func(m *FieldMapping) {
+		m.defaultValue = value
+		m.hasDefault = true
+	}
+}
+
+// WithConverter converts the value of the source field before it's assigned to the target field,
+// so that mapping between fields of different types doesn't need a Lambda node in between, e.g.
+//
+//	MapFields("Count", "count", WithConverter(strconv.Atoi))
+//
+// F is checked against the type of the source field and T against the type of the target field at compile time.
+// In stream mode, the converter is applied to each chunk of the source field.
+func WithConverter[F, T any](convert func(F) (T, error)) FieldMappingOption {
+	return func(m *FieldMapping) {
+		m.converter = &fieldConverter{
+			fromType: generic.TypeOf[F](),
+			toType:   generic.TypeOf[T](),
+			convert: func(v any) (any, error) {
+				f, err := castFieldValue[F](v)
+				if err != nil {
+					return nil, err
+				}
+				return convert(f)
+			},
+		}
+	}
+}
+
+// WithJSONUnmarshal converts the source field, which is a JSON string, to T, e.g.
+//
+//	MapFields("Arguments", "params", WithJSONUnmarshal[*SearchParams]())
+func WithJSONUnmarshal[T any]() FieldMappingOption {
+	return WithConverter(func(s string) (T, error) {
+		var t T
+		if err := sonic.UnmarshalString(s, &t); err != nil {
+			return t, fmt.Errorf("unmarshal json to %v fail: %w", generic.TypeOf[T](), err)
+		}
+		return t, nil
+	})
+}
+
+// WithPredicate makes the mapping apply only when the predicate returns true for the value of the source field,
+// otherwise the target field is left unset, e.g.
+//
+//	wf.AddLambdaNode("search", search).
+//		AddInput("intent", MapFields("Query", "query", WithPredicate(func(q string) bool { return q != "" })))
+//
+// The predicate is evaluated before the converter, and F is checked against the type of the source field at compile time.
+// In stream mode, the predicate is applied to each chunk of the source field.
+func WithPredicate[F any](predicate func(F) bool) FieldMappingOption {
+	return func(m *FieldMapping) {
+		m.predicate = &fieldPredicate{
+			fromType: generic.TypeOf[F](),
+			predicate: func(v any) (bool, error) {
+				f, err := castFieldValue[F](v)
+				if err != nil {
+					return false, err
+				}
+				return predicate(f), nil
+			},
+		}
+	}
+}
+
+func castFieldValue[F any](v any) (F, error) {
+	f, ok := v.(F)
+	if !ok && v != nil {
+		return f, fmt.Errorf("field value type[%T] isn't [%v]", v, generic.TypeOf[F]())
+	}
+	if !ok {
+		switch generic.TypeOf[F]().Kind() {
+		case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		default:
+			return f, fmt.Errorf("field value is nil, which isn't [%v]", generic.TypeOf[F]())
+		}
+	}
+	return f, nil
+}
+
+func (m *FieldMapping) hasTransforms() bool {
+	return m.hasDefault || m.predicate != nil || m.converter != nil
+}
+
+// transform applies the predicate and the converter to the value of the source field,
+// returns false if the mapping doesn't apply to the value.
+func (m *FieldMapping) transform(taken any) (any, bool, error) {
+	if m.predicate != nil {
+		ok, err := m.predicate.predicate(taken)
+		if err != nil {
+			return nil, false, fmt.Errorf("predicate of field mapping %s fail: %w", m, err)
+		}
+		if !ok {
+			return nil, false, nil
+		}
+	}
+	if m.converter != nil {
+		converted, err := m.converter.convert(taken)
+		if err != nil {
+			return nil, false, fmt.Errorf("converter of field mapping %s fail: %w", m, err)
+		}
+		return converted, true, nil
+	}
+	return taken, true, nil
+}
+
+// validateTransforms checks the default value, the predicate and the converter of the mapping against the types of the fields,
+// predecessorFieldType is nil if the type of the source field is unknown at compile time.
+func (m *FieldMapping) validateTransforms(predecessorFieldType, successorFieldType reflect.Type) error {
+	if m.hasDefault {
+		if dt := reflect.TypeOf(m.defaultValue); dt == nil {
+			switch successorFieldType.Kind() {
+			case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+			default:
+				return fmt.Errorf("static check failed for mapping %s, default value nil is not assignable to [%v]", m, successorFieldType)
+			}
+		} else if !dt.AssignableTo(successorFieldType) {
+			return fmt.Errorf("static check failed for mapping %s, default value type[%v] is not assignable to [%v]", m, dt, successorFieldType)
+		}
+	}
+
+	if predecessorFieldType != nil {
+		if m.predicate != nil && checkAssignable(predecessorFieldType, m.predicate.fromType) == assignableTypeMustNot {
+			return fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] of predicate is absolutely not assignable", m, predecessorFieldType, m.predicate.fromType)
+		}
+		if m.converter != nil && checkAssignable(predecessorFieldType, m.converter.fromType) == assignableTypeMustNot {
+			return fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] of converter is absolutely not assignable", m, predecessorFieldType, m.converter.fromType)
+		}
+	}
+
+	if m.converter != nil && checkAssignable(m.converter.toType, successorFieldType) == assignableTypeMustNot {
+		return fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] of converter is absolutely not assignable", m, m.converter.toType, successorFieldType)
+	}
+
+	return nil
+}
+
 func (m *FieldMapping) targetPath() FieldPath {
 	return splitFieldPath(m.to)
 }
@@ -482,21 +659,56 @@ func checkAndExtractFieldType(paths []string, typ reflect.Type) (extracted refle
 var strType = reflect.TypeOf("")
 
 func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourcePaths map[string]FieldPath) func(any) (map[string]any, error) {
+	return trackedFieldMap(mappings, allowMapKeyNotFound, uncheckedSourcePaths, nil)
+}
+
+// trackedFieldMap is fieldMap that also records the target of each mapping whose source field is found in found, if not nil.
+func trackedFieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourcePaths map[string]FieldPath,
+	found map[string]bool) func(any) (map[string]any, error) {
 	return func(input any) (result map[string]any, err error) {
 		result = make(map[string]any, len(mappings))
+		assign := func(mapping *FieldMapping, taken any) error {
+			if found != nil {
+				found[mapping.to] = true
+			}
+			v, ok, err := mapping.transform(taken)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result[mapping.to] = v
+			}
+			return nil
+		}
+		// missing handles a missing source field, returns false if the mapping has no default value
+		missing := func(mapping *FieldMapping) bool {
+			if !mapping.hasDefault {
+				return false
+			}
+			if !allowMapKeyNotFound {
+				result[mapping.to] = mapping.defaultValue
+			}
+			return true
+		}
+
 		var inputValue reflect.Value
 	loop:
 		for _, mapping := range mappings {
 			if mapping.customExtractor != nil {
-				result[mapping.to], err = mapping.customExtractor(input)
+				taken, err := mapping.customExtractor(input)
 				if err != nil {
 					return nil, err
 				}
+				if err = assign(mapping, taken); err != nil {
+					return nil, err
+				}
 				continue
 			}
 
 			if len(mapping.from) == 0 {
-				result[mapping.to] = input
+				if err = assign(mapping, input); err != nil {
+					return nil, err
+				}
 				continue
 			}
 
@@ -518,10 +730,16 @@ func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourc
 				}
 
 				if !pathInputValue.IsValid() {
+					if missing(mapping) {
+						continue loop
+					}
 					return nil, fmt.Errorf("intermediate source value on path=%v is nil for type [%v]", fromPath[:i+1], pathInputType)
 				}
 
 				if pathInputValue.Kind() == reflect.Map && pathInputValue.IsNil() {
+					if missing(mapping) {
+						continue loop
+					}
 					return nil, fmt.Errorf("intermediate source value on path=%v is nil for map type [%v]", fromPath[:i+1], pathInputType)
 				}
 
@@ -536,7 +754,7 @@ func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourc
 					// map key not found can only be a request time error, so we won't panic here
 					var mapKeyNotFoundErr *errMapKeyNotFound
 					if errors.As(err, &mapKeyNotFoundErr) {
-						if allowMapKeyNotFound {
+						if allowMapKeyNotFound || missing(mapping) {
 							continue loop
 						}
 						return nil, err
@@ -558,7 +776,9 @@ func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourc
 				}
 			}
 
-			result[mapping.to] = taken
+			if err = assign(mapping, taken); err != nil {
+				return nil, err
+			}
 		}
 
 		return result, nil
@@ -567,10 +787,60 @@ func fieldMap(mappings []*FieldMapping, allowMapKeyNotFound bool, uncheckedSourc
 
 func streamFieldMap(mappings []*FieldMapping, uncheckedSourcePaths map[string]FieldPath) func(streamReader) streamReader {
 	return func(input streamReader) streamReader {
-		return packStreamReader(schema.StreamReaderWithConvert(input.toAnyStreamReader(), fieldMap(mappings, true, uncheckedSourcePaths)))
+		var hasDefault bool
+		for _, mapping := range mappings {
+			hasDefault = hasDefault || mapping.hasDefault
+		}
+		if !hasDefault {
+			return packStreamReader(schema.StreamReaderWithConvert(input.toAnyStreamReader(), fieldMap(mappings, true, uncheckedSourcePaths)))
+		}
+
+		found := make(map[string]bool, len(mappings))
+		s := schema.StreamReaderWithConvert(input.toAnyStreamReader(), trackedFieldMap(mappings, true, uncheckedSourcePaths, found))
+		return packStreamReader(appendFieldMappingDefaults(s, mappings, found))
 	}
 }
 
+// appendFieldMappingDefaults sends the default values of the mappings whose source fields are missing in all chunks
+// as the last chunk of the stream.
+func appendFieldMappingDefaults(s *schema.StreamReader[map[string]any], mappings []*FieldMapping, found map[string]bool) *schema.StreamReader[map[string]any] {
+	sr, sw := schema.Pipe[map[string]any](0)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				_ = sw.Send(nil, safe.NewPanicErr(e, debug.Stack()))
+			}
+			s.Close()
+			sw.Close()
+		}()
+
+		for {
+			chunk, err := s.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				_ = sw.Send(nil, err)
+				return
+			}
+			if sw.Send(chunk, nil) {
+				return
+			}
+		}
+
+		defaults := make(map[string]any)
+		for _, mapping := range mappings {
+			if mapping.hasDefault && !found[mapping.to] {
+				defaults[mapping.to] = mapping.defaultValue
+			}
+		}
+		if len(defaults) > 0 {
+			sw.Send(defaults, nil)
+		}
+	}()
+	return sr
+}
+
 func takeOne(inputValue reflect.Value, inputType reflect.Type, from string) (taken any, takenType reflect.Type, err error) {
 	var f reflect.Value
 	switch k := inputValue.Kind(); k {
@@ -677,6 +947,9 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 		}
 
 		if mapping.customExtractor != nil { // custom extractor applies to request-time data, so skip compile-time check
+			if err = mapping.validateTransforms(nil, successorFieldType); err != nil {
+				return nil, nil, err
+			}
 			continue
 		}
 
@@ -692,6 +965,19 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 			uncheckedSourcePath[mapping.from] = predecessorRemaining
 		}
 
+		if len(predecessorRemaining) > 0 {
+			err = mapping.validateTransforms(nil, successorFieldType)
+		} else {
+			err = mapping.validateTransforms(predecessorFieldType, successorFieldType)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if mapping.converter != nil {
+			// the source field is checked by the converter at request time, and it's the output of the converter that's assigned
+			predecessorFieldType, predecessorRemaining = mapping.converter.toType, nil
+		}
+
 		checker := func(a any) (any, error) {
 			trueInType := reflect.TypeOf(a)
 			if trueInType == nil {
@@ -146,7 +146,11 @@ func compileAnyGraph[I, O any](ctx context.Context, g AnyGraph, opts ...GraphCom
 	}
 
 	ctxWrapper := func(ctx context.Context, opts ...Option) context.Context {
-		return initGraphCallbacks(AppendAddressSegment(ctx, AddressSegmentRunnable, option.graphName), cr.nodeInfo, cr.meta, opts...)
+		ctx, isSubTask := enterSubTask(ctx)
+		if !isSubTask {
+			ctx = AppendAddressSegment(ctx, AddressSegmentRunnable, option.graphName)
+		}
+		return initGraphCallbacks(ctx, cr.nodeInfo, cr.meta, opts...)
 	}
 
 	rp, err := toGenericRunnable[I, O](cr, ctxWrapper)
@@ -58,6 +58,8 @@ type graph struct {
 	nodes        map[string]*graphNode
 	controlEdges map[string][]string
 	dataEdges    map[string][]string
+	errorEdges   map[string]string
+	errorRoutes  map[string]*GraphBranch // the error edges and error branches, see AddErrorEdge and AddErrorBranch
 	branches     map[string][]*GraphBranch
 	startNodes   []string
 	endNodes     []string
@@ -86,6 +88,9 @@ type graph struct {
 	handlerOnEdges   map[string]map[string][]handlerPair
 	handlerPreNode   map[string][]handlerPair
 	handlerPreBranch map[string][][]handlerPair
+
+	// the edges and branches whose types are checked at runtime, reported by Lint
+	runtimeTypeChecks []*Diagnostic
 }
 
 type newGraphConfig struct {
@@ -119,6 +124,8 @@ func newGraph(cfg *newGraphConfig) *graph {
 		nodes:        make(map[string]*graphNode),
 		dataEdges:    make(map[string][]string),
 		controlEdges: make(map[string][]string),
+		errorEdges:   make(map[string]string),
+		errorRoutes:  make(map[string]*GraphBranch),
 		branches:     make(map[string][]*GraphBranch),
 
 		toValidateMap: make(map[string][]struct {
@@ -185,7 +192,8 @@ func (g *graph) addNode(key string, node *graphNode, options *graphAddNodeOpts)
 	// check options
 	if options.needState {
 		if g.stateGenerator == nil {
-			return fmt.Errorf("node '%s' needs state but graph state is not enabled", key)
+			return newDiagnosticError(DiagnosticStateNotEnabled, []string{key},
+				fmt.Errorf("node '%s' needs state but graph state is not enabled", key))
 		}
 	}
 
@@ -432,6 +440,85 @@ func (g *graph) AddBranch(startNode string, branch *GraphBranch) (err error) {
 	return g.addBranch(startNode, branch, false)
 }
 
+// AddErrorEdge routes the failure of the start node to the error handler node, instead of failing the graph.
+// When the start node fails, the error handler node receives a *NodeError as its input, and the other successors of the start node are skipped.
+// When the start node succeeds, the error handler node is skipped.
+// The input type of the error handler node must accept *NodeError, e.g. *NodeError, error or any,
+// and the error handler node can't have other predecessors, so each failing node needs an error handler node of its own.
+// Interrupts of the start node are not routed, neither are the errors in its output stream in stream mode.
+// e.g.
+//
+//	graph.AddErrorEdge("retriever", "fallback_retriever")
+func (g *graph) AddErrorEdge(startNode, handlerNode string) (err error) {
+	branch := NewGraphBranch(func(context.Context, *NodeError) (string, error) {
+		return handlerNode, nil
+	}, map[string]bool{handlerNode: true})
+	if err = g.addErrorRoute(startNode, branch); err != nil {
+		return err
+	}
+	g.errorEdges[startNode] = handlerNode
+	return nil
+}
+
+// AddErrorBranch routes the failure of the start node to the error handler nodes selected by the branch, instead of failing the graph,
+// e.g. by the type of the error.
+// The condition of the branch receives the *NodeError, so its input type must accept *NodeError, e.g. *NodeError, error or any.
+// Otherwise, it's the same as AddErrorEdge for each end node of the branch,
+// which receives the *NodeError as its input if it's selected, and is skipped otherwise.
+// e.g.
+//
+//	condition := func(ctx context.Context, in *compose.NodeError) (string, error) {
+//		if errors.Is(in, errRetrieverUnavailable) {
+//			return "fallback_retriever", nil
+//		}
+//		return "apology", nil
+//	}
+//	graph.AddErrorBranch("retriever", compose.NewGraphBranch(condition, map[string]bool{"fallback_retriever": true, "apology": true}))
+func (g *graph) AddErrorBranch(startNode string, branch *GraphBranch) (err error) {
+	return g.addErrorRoute(startNode, branch)
+}
+
+func (g *graph) addErrorRoute(startNode string, branch *GraphBranch) (err error) {
+	if g.buildError != nil {
+		return g.buildError
+	}
+	if g.compiled {
+		return ErrGraphCompiled
+	}
+
+	defer func() {
+		if err != nil {
+			g.buildError = err
+		}
+	}()
+
+	nodeErrType := reflect.TypeOf((*NodeError)(nil))
+	if _, ok := g.nodes[startNode]; !ok {
+		return fmt.Errorf("error edge start node '%s' needs to be added to graph first", startNode)
+	}
+	if _, ok := g.errorRoutes[startNode]; ok {
+		return fmt.Errorf("node '%s' already has an error edge or error branch", startNode)
+	}
+	if !nodeErrType.AssignableTo(branch.inputType) {
+		return fmt.Errorf("error branch input type[%v] doesn't accept *NodeError", branch.inputType)
+	}
+	if len(branch.endNodes) == 0 {
+		return errors.New("error branch requires end nodes")
+	}
+	for handlerNode := range branch.endNodes {
+		if _, ok := g.nodes[handlerNode]; !ok {
+			return fmt.Errorf("error edge handler node '%s' needs to be added to graph first", handlerNode)
+		}
+		inputType := g.getNodeInputType(handlerNode)
+		if inputType == nil || !nodeErrType.AssignableTo(inputType) {
+			return fmt.Errorf("error handler node '%s' input type[%v] doesn't accept *NodeError", handlerNode, inputType)
+		}
+	}
+
+	g.errorRoutes[startNode] = branch
+	return nil
+}
+
 func (g *graph) addBranch(startNode string, branch *GraphBranch, skipData bool) (err error) {
 	if g.buildError != nil {
 		return g.buildError
@@ -469,9 +556,12 @@ func (g *graph) addBranch(startNode string, branch *GraphBranch, skipData bool)
 	// check branch condition type
 	result := checkAssignable(g.getNodeOutputType(startNode), branch.inputType)
 	if result == assignableTypeMustNot {
-		return fmt.Errorf("condition's input type[%s] and start node[%s]'s output type[%s] are mismatched", branch.inputType.String(), startNode, g.getNodeOutputType(startNode).String())
+		return newDiagnosticError(DiagnosticTypeMismatch, []string{startNode},
+			fmt.Errorf("condition's input type[%s] and start node[%s]'s output type[%s] are mismatched", branch.inputType.String(), startNode, g.getNodeOutputType(startNode).String()))
 	} else if result == assignableTypeMay {
 		g.handlerPreBranch[startNode] = append(g.handlerPreBranch[startNode], []handlerPair{branch.inputConverter})
+		g.addRuntimeTypeCheck([]string{startNode}, "branch condition's input type[%s] is checked against start node[%s]'s output type[%s] at runtime",
+			branch.inputType, startNode, g.getNodeOutputType(startNode))
 	} else {
 		g.handlerPreBranch[startNode] = append(g.handlerPreBranch[startNode], []handlerPair{})
 	}
@@ -556,9 +646,12 @@ func (g *graph) updateToValidateMap() error {
 					// common node check
 					result := checkAssignable(startNodeOutputType, endNodeInputType)
 					if result == assignableTypeMustNot {
-						return fmt.Errorf("graph edge[%s]-[%s]: start node's output type[%s] and end node's input type[%s] mismatch",
-							startNode, endNode.endNode, startNodeOutputType.String(), endNodeInputType.String())
+						return newDiagnosticError(DiagnosticTypeMismatch, []string{startNode, endNode.endNode},
+							fmt.Errorf("graph edge[%s]-[%s]: start node's output type[%s] and end node's input type[%s] mismatch",
+								startNode, endNode.endNode, startNodeOutputType.String(), endNodeInputType.String()))
 					} else if result == assignableTypeMay {
+						g.addRuntimeTypeCheck([]string{startNode, endNode.endNode}, "graph edge[%s]-[%s]: start node's output type[%s] is checked against end node's input type[%s] at runtime",
+							startNode, endNode.endNode, startNodeOutputType, endNodeInputType)
 						// add runtime check edges
 						if _, ok := g.handlerOnEdges[startNode]; !ok {
 							g.handlerOnEdges[startNode] = make(map[string][]handlerPair)
@@ -577,7 +670,11 @@ func (g *graph) updateToValidateMap() error {
 					// field mapping check
 					checker, uncheckedSourcePaths, err := validateFieldMapping(g.getNodeOutputType(startNode), g.getNodeInputType(endNode.endNode), endNode.mappings)
 					if err != nil {
-						return err
+						return newDiagnosticError(DiagnosticTypeMismatch, []string{startNode, endNode.endNode}, err)
+					}
+					if checker != nil || len(uncheckedSourcePaths) > 0 {
+						g.addRuntimeTypeCheck([]string{startNode, endNode.endNode}, "graph edge[%s]-[%s]: field mappings %v are checked at runtime",
+							startNode, endNode.endNode, endNode.mappings)
 					}
 
 					g.handlerOnEdges[startNode][endNode.endNode] = append(g.handlerOnEdges[startNode][endNode.endNode], handlerPair{
@@ -692,6 +789,7 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 	}
 
 	key2SubGraphs := g.beforeChildGraphsCompile(opt)
+	ctx = withNodeWrapperFlags(ctx, opt)
 	chanSubscribeTo := make(map[string]*chanCall)
 	for name, node := range g.nodes {
 		node.beforeChildGraphCompile(name, key2SubGraphs)
@@ -705,6 +803,7 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 			action:   r,
 			writeTo:  g.dataEdges[name],
 			controls: g.controlEdges[name],
+			errorTo:  g.errorRoutes[name],
 
 			preProcessor:  node.nodeInfo.preProcessor,
 			postProcessor: node.nodeInfo.postProcessor,
@@ -721,6 +820,18 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 		chanSubscribeTo[name] = chCall
 	}
 
+	for _, chCall := range chanSubscribeTo {
+		for _, successor := range chCall.writeTo {
+			if successor == END || len(g.fieldMappingRecords[successor]) > 0 {
+				continue
+			}
+			if chCall.subTaskSuccessors == nil {
+				chCall.subTaskSuccessors = make(map[string]reflect.Type)
+			}
+			chCall.subTaskSuccessors[successor] = chanSubscribeTo[successor].action.inputType
+		}
+	}
+
 	dataPredecessors := make(map[string][]string)
 	controlPredecessors := make(map[string][]string)
 	for start, ends := range g.controlEdges {
@@ -741,6 +852,12 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 			}
 		}
 	}
+	for start, branch := range g.errorRoutes {
+		for end := range branch.endNodes {
+			controlPredecessors[end] = append(controlPredecessors[end], start)
+			dataPredecessors[end] = append(dataPredecessors[end], start)
+		}
+	}
 	for start, branches := range g.branches {
 		for _, branch := range branches {
 			for end := range branch.endNodes {
@@ -761,6 +878,15 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 		}
 	}
 
+	// checked after all the predecessors are added, including the start nodes of branches
+	for start, branch := range g.errorRoutes {
+		for end := range branch.endNodes {
+			if len(dataPredecessors[end]) > 1 {
+				return nil, fmt.Errorf("error handler node[%s] can't have other predecessors than node[%s]", end, start)
+			}
+		}
+	}
+
 	inputChannels := &chanCall{
 		writeTo:         g.dataEdges[START],
 		controls:        g.controlEdges[START],
@@ -775,6 +901,34 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 	if mergeConfigs == nil {
 		mergeConfigs = make(map[string]FanInMergeConfig)
 	}
+	for key, cfg := range mergeConfigs {
+		if cfg.Reducer == nil || cfg.Reducer.typ == nil {
+			continue
+		}
+		if _, ok := g.nodes[key]; !ok && key != END {
+			continue
+		}
+		// the values merged for the nodes with field mappings are the results of the mappings
+		if len(g.fieldMappingRecords[key]) > 0 {
+			if mapType := generic.TypeOf[map[string]any](); cfg.Reducer.typ != mapType {
+				return nil, fmt.Errorf("fan-in reducer of node[%s] merges values of type[%v], but the node has field mappings, whose results of type[%v] are merged",
+					key, cfg.Reducer.typ, mapType)
+			}
+			continue
+		}
+		if inputType := g.getNodeInputType(key); inputType != nil && inputType.Kind() != reflect.Interface && inputType != cfg.Reducer.typ {
+			return nil, fmt.Errorf("fan-in reducer of node[%s] merges values of type[%v], but the node's input type is [%v]",
+				key, cfg.Reducer.typ, inputType)
+		}
+	}
+
+	if opt != nil {
+		for key := range opt.nodeTimeouts {
+			if _, ok := g.nodes[key]; !ok {
+				return nil, fmt.Errorf("node timeout has been set to an unknown node: %s", key)
+			}
+		}
+	}
 
 	r := &runner{
 		chanSubscribeTo:     chanSubscribeTo,
@@ -795,6 +949,8 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 		preNodeHandlerManager:   &preNodeHandlerManager{h: g.handlerPreNode},
 		edgeHandlerManager:      &edgeHandlerManager{h: g.handlerOnEdges},
 
+		nodeWrapperFlags: getNodeWrapperFlags(ctx),
+
 		mergeConfigs: mergeConfigs,
 	}
 
@@ -824,6 +980,7 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 			return nil, err
 		}
 		r.dag = true
+		r.stepsToEnd = calculateStepsToEnd(successors)
 	}
 
 	if opt != nil {
@@ -856,10 +1013,37 @@ func (g *graph) compile(ctx context.Context, opt *graphCompileOptions) (*composa
 	return r.toComposableRunnable(), nil
 }
 
+// calculateStepsToEnd returns the number of nodes on the longest path from each node to END, the graph must be acyclic.
+func calculateStepsToEnd(successors map[string][]string) map[string]int {
+	steps := make(map[string]int, len(successors))
+	var visit func(key string) int
+	visit = func(key string) int {
+		if key == END {
+			return 0
+		}
+		if s, ok := steps[key]; ok {
+			return s
+		}
+		maxSteps := 0
+		for _, successor := range successors[key] {
+			if s := visit(successor); s > maxSteps {
+				maxSteps = s
+			}
+		}
+		steps[key] = maxSteps + 1
+		return steps[key]
+	}
+	for key := range successors {
+		visit(key)
+	}
+	return steps
+}
+
 func getSuccessors(c *chanCall) []string {
 	ret := make([]string, len(c.writeTo))
 	copy(ret, c.writeTo)
 	ret = append(ret, c.controls...)
+	ret = append(ret, c.errorHandlers()...)
 	for _, branch := range c.writeToBranches {
 		for node := range branch.endNodes {
 			ret = append(ret, node)
@@ -936,6 +1120,25 @@ func (g *graph) toGraphInfo(opt *graphCompileOptions, key2SubGraphs map[string]*
 		NewGraphOptions: g.newOpts,
 	}
 
+	if len(g.errorEdges) > 0 {
+		gInfo.ErrorEdges = gmap.Clone(g.errorEdges)
+	}
+	for startNode, b := range g.errorRoutes {
+		if _, ok := g.errorEdges[startNode]; ok {
+			continue
+		}
+		if gInfo.ErrorBranches == nil {
+			gInfo.ErrorBranches = make(map[string]GraphBranch)
+		}
+		gInfo.ErrorBranches[startNode] = GraphBranch{
+			invoke:        b.invoke,
+			collect:       b.collect,
+			inputType:     b.inputType,
+			genericHelper: b.genericHelper,
+			endNodes:      gmap.Clone(b.endNodes),
+		}
+	}
+
 	for key := range g.nodes {
 		gNode := g.nodes[key]
 		if gNode.executorMeta.component == ComponentOfPassthrough {
@@ -1065,6 +1268,9 @@ func validateDAG(chanSubscribeTo map[string]*chanCall, controlPredecessors map[s
 					}
 					m[subNode]--
 				}
+				for _, handler := range chanSubscribeTo[node].errorHandlers() {
+					m[handler]--
+				}
 				for _, subBranch := range chanSubscribeTo[node].writeToBranches {
 					for subNode := range subBranch.endNodes {
 						if subNode == END {
@@ -1097,6 +1303,7 @@ func findLoops(startNodes []string, chanCalls map[string]*chanCall) [][]string {
 	controlSuccessors := map[string][]string{}
 	for node, ch := range chanCalls {
 		controlSuccessors[node] = append(controlSuccessors[node], ch.controls...)
+		controlSuccessors[node] = append(controlSuccessors[node], ch.errorHandlers()...)
 		for _, b := range ch.writeToBranches {
 			for end := range b.endNodes {
 				controlSuccessors[node] = append(controlSuccessors[node], end)
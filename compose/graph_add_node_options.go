@@ -44,6 +44,9 @@ type nodeOptions struct {
 	outputKey string
 
 	graphCompileOption []GraphCompileOption // when this node is itself an AnyGraph, this option will be used to compile the node as a nested graph
+
+	retryPolicy *RetryPolicy
+	cachePolicy *CachePolicy
 }
 
 // WithNodeName sets the name of the node.
@@ -89,6 +92,30 @@ func WithGraphCompileOptions(opts ...GraphCompileOption) GraphAddNodeOpt {
 	}
 }
 
+// WithRetryPolicy sets the retry policy of the node, the node will be re-executed when it fails with a retryable error.
+// every attempt triggers the node's callbacks, use GetRetryAttempt in callback handlers to tell attempts apart.
+// in stream mode, the node is retried only if no chunk has been emitted to downstream yet.
+// e.g.
+//
+//	graph.AddRetrieverNode("retriever", r, compose.WithRetryPolicy(&compose.RetryPolicy{MaxAttempts: 3}))
+func WithRetryPolicy(policy *RetryPolicy) GraphAddNodeOpt {
+	return func(o *graphAddNodeOpts) {
+		o.nodeOptions.retryPolicy = policy
+	}
+}
+
+// WithCachePolicy enables caching the output of the node, which should be deterministic on its input, e.g. embedding, retriever, chat template or pure lambda.
+// on a cache hit, the node is not executed, while its OnStart and OnEnd callbacks are still triggered with RunInfo.CacheHit set.
+// in stream mode, the input and output are concatenated to be cached, and the cached output is emitted as a single chunk stream.
+// e.g.
+//
+//	graph.AddEmbeddingNode("embedding", e, compose.WithCachePolicy(&compose.CachePolicy{Cache: compose.NewLRUNodeCache(1024)}))
+func WithCachePolicy(policy *CachePolicy) GraphAddNodeOpt {
+	return func(o *graphAddNodeOpts) {
+		o.nodeOptions.cachePolicy = policy
+	}
+}
+
 // WithStatePreHandler modify node's input of I according to state S and input or store input information into state, and it's thread-safe.
 // notice: this option requires Graph to be created with WithGenLocalState option.
 // I: input type of the Node like ChatModel, Lambda, Retriever etc.
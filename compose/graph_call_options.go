@@ -104,6 +104,13 @@ type Option struct {
 	writeToCheckPointID *string
 	forceNewRun         bool
 	stateModifier       StateModifier
+	checkPointHistory   bool
+
+	nodeTimeout *time.Duration
+
+	replay *replayConfig
+
+	nodeOutputEmitter *nodeOutputEmitter
 }
 
 func (o Option) deepCopy() Option {
@@ -121,6 +128,7 @@ func (o Option) deepCopy() Option {
 		handler:     nHandler,
 		paths:       nPaths,
 		maxRunSteps: o.maxRunSteps,
+		nodeTimeout: o.nodeTimeout,
 	}
 }
 
@@ -237,6 +245,19 @@ func WithRuntimeMaxSteps(maxSteps int) Option {
 	}
 }
 
+// WithNodeTimeout overrides the execution timeout of nodes set by WithNodeTimeouts for a single call.
+// Without designation, it applies to all nodes of the graph. A zero timeout disables the timeout.
+// e.g.
+//
+//	runnable.Invoke(ctx, "input", compose.WithNodeTimeout(time.Second).DesignateNode("retriever"))
+//	// designate a node in the subgraph
+//	runnable.Invoke(ctx, "input", compose.WithNodeTimeout(time.Second).DesignateNodeWithPath(compose.NewNodePath("sub_graph", "retriever")))
+func WithNodeTimeout(timeout time.Duration) Option {
+	return Option{
+		nodeTimeout: &timeout,
+	}
+}
+
 func withComponentOption[TOption any](opts ...TOption) Option {
 	o := make([]any, 0, len(opts))
 	for i := range opts {
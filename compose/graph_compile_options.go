@@ -16,6 +16,11 @@
 
 package compose
 
+import (
+	"context"
+	"time"
+)
+
 type graphCompileOptions struct {
 	maxRunSteps     int
 	graphName       string
@@ -33,6 +38,16 @@ type graphCompileOptions struct {
 	eagerDisabled bool
 
 	mergeConfigs map[string]FanInMergeConfig
+
+	nodeTimeouts  map[string]time.Duration
+	deadlineSplit bool
+
+	concurrencyLimiter *ConcurrencyLimiter
+
+	replayEnabled           bool
+	nodeOutputStreamEnabled bool
+	// dryRun is set by DryRun
+	dryRun bool
 }
 
 func newGraphCompileOptions(opts ...GraphCompileOption) *graphCompileOptions {
@@ -114,6 +129,17 @@ func WithGraphCompileCallbacks(cbs ...GraphCompileCallback) GraphCompileOption {
 // tracking the completion of individual input streams in a named stream merge.
 type FanInMergeConfig struct {
 	StreamMergeWithSourceEOF bool //indicates whether to emit a SourceEOF error for each stream
+
+	// Reducer merges the inputs in the order they arrive instead of the merge function registered for the type,
+	// e.g. MessagesReducer(nil) for a node receiving message lists from several predecessors.
+	// Built-in reducers are AppendReducer, MessagesReducer, LastWriterWinsReducer, FirstArrivalWinsReducer and CustomReducer.
+	// For a Workflow node with field mappings, the inputs merged are the results of the mappings of each predecessor,
+	// i.e. map[string]any from the mapped fields to the values, rather than the input values of the node,
+	// so only the reducers of any type, or CustomReducer[map[string]any], apply.
+	Reducer *FanInReducer
+	// StreamInterleave makes AppendReducer and MessagesReducer interleave the chunks of the input streams as they come,
+	// rather than concatenating the streams one after another.
+	StreamInterleave bool
 }
 
 // WithFanInMergeConfig sets the fan-in merge configurations
@@ -124,6 +150,87 @@ func WithFanInMergeConfig(confs map[string]FanInMergeConfig) GraphCompileOption
 	}
 }
 
+// WithNodeTimeouts sets the execution timeout of the graph nodes, keyed by node key.
+// A node that doesn't finish within its timeout fails with a *NodeTimeoutError.
+// In stream mode, the timeout bounds the time until the node returns its output stream,
+// and the node's context keeps the deadline while the stream is being produced.
+// The timeouts can be overridden per call by WithNodeTimeout.
+// e.g.
+//
+//	graph.Compile(ctx, compose.WithNodeTimeouts(map[string]time.Duration{"retriever": 3 * time.Second}))
+func WithNodeTimeouts(timeouts map[string]time.Duration) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.nodeTimeouts = timeouts
+	}
+}
+
+// WithDeadlineSplit makes the graph split the deadline of the run context across the remaining steps.
+// When the context passed to the graph has a deadline, each node gets at most an even share of the time left,
+// e.g. with 9s left and 3 steps remaining, the node gets 3s. A node's own timeout still applies if it is shorter.
+// The remaining steps are the longest path to END in a DAG, or the steps left before reaching the max run steps in pregel mode.
+func WithDeadlineSplit() GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.deadlineSplit = true
+	}
+}
+
+// WithConcurrencyLimiter caps the number of nodes of the graph executing at the same time, see ConcurrencyLimiter.
+// The limiter also applies to the sub graphs and the graphs run inside the nodes, e.g. by a Lambda, unless they have their own.
+// A node running another graph releases its slot while the nested graph runs, whose nodes take slots of their own,
+// and takes the slot back when the nested graph returns, so nesting can't exhaust the limit.
+// e.g.
+//
+//	limiter := compose.NewConcurrencyLimiter(&compose.ConcurrencyLimiterConfig{
+//		MaxConcurrency:            16,
+//		MaxConcurrencyByComponent: map[components.Component]int{components.ComponentOfChatModel: 4},
+//	})
+//	graph.Compile(ctx, compose.WithConcurrencyLimiter(limiter))
+func WithConcurrencyLimiter(limiter *ConcurrencyLimiter) GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.concurrencyLimiter = limiter
+	}
+}
+
+// WithReplaySupport makes the nodes of the graph replayable, so that the graph can be run with WithReplay.
+// The subgraphs are replayable as well, but the graphs run inside the nodes, e.g. by a Lambda, need the option of their own.
+func WithReplaySupport() GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.replayEnabled = true
+	}
+}
+
+// WithNodeOutputStreamSupport makes the nodes of the graph report their outputs, so that the graph can be run with WithNodeOutputStream.
+// The subgraphs report as well, but the graphs run inside the nodes, e.g. by a Lambda, need the option of their own.
+func WithNodeOutputStreamSupport() GraphCompileOption {
+	return func(o *graphCompileOptions) {
+		o.nodeOutputStreamEnabled = true
+	}
+}
+
+// nodeWrapperFlags are the compile flags adding the wrappers of the nodes, inherited by the subgraphs through the compile context.
+type nodeWrapperFlags struct {
+	dryRun           bool
+	replay           bool
+	nodeOutputStream bool
+}
+
+type nodeWrapperFlagsKey struct{}
+
+func withNodeWrapperFlags(ctx context.Context, opt *graphCompileOptions) context.Context {
+	flags, _ := ctx.Value(nodeWrapperFlagsKey{}).(nodeWrapperFlags)
+	if opt != nil {
+		flags.dryRun = flags.dryRun || opt.dryRun
+		flags.replay = flags.replay || opt.replayEnabled
+		flags.nodeOutputStream = flags.nodeOutputStream || opt.nodeOutputStreamEnabled
+	}
+	return context.WithValue(ctx, nodeWrapperFlagsKey{}, flags)
+}
+
+func getNodeWrapperFlags(ctx context.Context) nodeWrapperFlags {
+	flags, _ := ctx.Value(nodeWrapperFlagsKey{}).(nodeWrapperFlags)
+	return flags
+}
+
 // InitGraphCompileCallbacks set global graph compile callbacks,
 // which ONLY will be added to top level graph compile options
 func InitGraphCompileCallbacks(cbs []GraphCompileCallback) {
@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"time"
@@ -264,6 +265,9 @@ type task struct {
 	option         []any
 	err            error
 	skipPreHandler bool
+	timeout        time.Duration
+	step           int
+	subTaskOutputs map[string]any // the outputs sent to the successors, see RunSubTaskTo
 }
 
 type taskManager struct {
@@ -283,20 +287,159 @@ type taskManager struct {
 }
 
 func (t *taskManager) execute(currentTask *task) {
+	var snapshot *inputSnapshot
 	defer func() {
 		panicInfo := recover()
 		if panicInfo != nil {
 			currentTask.output = nil
 			currentTask.err = safe.NewPanicErr(panicInfo, debug.Stack())
 		}
+		if snapshot != nil {
+			currentTask.err = snapshot.toNodeError(currentTask)
+		}
 
 		t.done.Send(currentTask)
 	}()
 
-	ctx := initNodeCallbacks(currentTask.ctx, currentTask.nodeKey, currentTask.call.action.nodeInfo, currentTask.call.action.meta, t.opts...)
+	slot, err := acquireNodeSlot(currentTask.ctx, currentTask)
+	if err != nil {
+		currentTask.err = err
+		return
+	}
+	defer func() {
+		releaseNodeSlot(currentTask, slot)
+	}()
+
+	if currentTask.call.errorTo != nil {
+		snapshot = takeInputSnapshot(currentTask)
+	}
+
+	ctx := context.WithValue(currentTask.ctx, nodeStepKey{}, currentTask.step)
+	ctx = withNodeSlot(ctx, slot)
+	ctx, routes := withSubTaskRoutes(ctx, currentTask)
+	if routes != nil {
+		defer func() {
+			routes.mu.Lock()
+			defer routes.mu.Unlock()
+			if currentTask.err == nil && len(routes.outputs) > 0 {
+				currentTask.subTaskOutputs = routes.outputs
+			}
+		}()
+	}
+	ctx = initNodeCallbacks(ctx, currentTask.nodeKey, currentTask.call.action.nodeInfo, currentTask.call.action.meta, t.opts...)
+	if currentTask.timeout > 0 {
+		currentTask.output, currentTask.err = t.runWithTimeout(ctx, currentTask)
+		return
+	}
 	currentTask.output, currentTask.err = t.runWrapper(ctx, currentTask.call.action, currentTask.input, currentTask.option...)
 }
 
+// inputSnapshot keeps the input of a node having error handler nodes, see AddErrorEdge and AddErrorBranch.
+type inputSnapshot struct {
+	input any
+}
+
+func takeInputSnapshot(currentTask *task) *inputSnapshot {
+	if sr, ok := currentTask.input.(streamReader); ok {
+		copies := sr.copy(2)
+		currentTask.input = copies[0]
+		return &inputSnapshot{input: copies[1]}
+	}
+	return &inputSnapshot{input: currentTask.input}
+}
+
+// toNodeError converts the failure of the node into a *NodeError, which is routed to the error handler node.
+func (s *inputSnapshot) toNodeError(currentTask *task) error {
+	sr, isStream := s.input.(streamReader)
+	if currentTask.err == nil || isInterruptError(currentTask.err) {
+		if isStream {
+			sr.close()
+		}
+		return currentTask.err
+	}
+
+	input := s.input
+	if isStream {
+		var err error
+		input, err = currentTask.call.action.inputStreamConvertPair.concatStream(sr)
+		if err != nil {
+			input = nil
+		}
+	}
+
+	path, ok := getNodePath(currentTask.ctx)
+	if !ok {
+		path = NewNodePath(currentTask.nodeKey)
+	}
+	return &NodeError{NodePath: *path, Input: input, Err: currentTask.err}
+}
+
+// runWithTimeout runs the task in a separate goroutine and stops waiting for it once the timeout is reached,
+// so that a node ignoring its context still can't block the graph.
+// An output stream keeps the timeout until it ends or is closed, failing with a *NodeTimeoutError if the timeout is reached first.
+func (t *taskManager) runWithTimeout(ctx context.Context, currentTask *task) (any, error) {
+	type result struct {
+		output any
+		err    error
+	}
+
+	nCtx, cancel := context.WithTimeout(ctx, currentTask.timeout)
+	resultCh := make(chan result, 1)
+	go func() {
+		defer func() {
+			panicInfo := recover()
+			if panicInfo != nil {
+				resultCh <- result{err: safe.NewPanicErr(panicInfo, debug.Stack())}
+			}
+		}()
+		output, err := t.runWrapper(nCtx, currentTask.call.action, currentTask.input, currentTask.option...)
+		resultCh <- result{output: output, err: err}
+	}()
+
+	select {
+	case res := <-resultCh:
+		if res.err != nil {
+			cancel()
+			if errors.Is(nCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				return nil, newNodeTimeoutError(currentTask)
+			}
+			return nil, res.err
+		}
+		if sr, ok := res.output.(streamReader); ok {
+			// the output stream may still be produced with nCtx, keep it until the stream ends
+			return sr.withEndHook(func(err error) error {
+				if errors.Is(nCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+					return newNodeTimeoutError(currentTask)
+				}
+				return err
+			}, cancel), nil
+		}
+		cancel()
+		return res.output, nil
+	case <-nCtx.Done():
+		cancel()
+		go func() {
+			// the node has been abandoned, release its output if any
+			res := <-resultCh
+			if sr, ok := res.output.(streamReader); ok {
+				sr.close()
+			}
+		}()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, newNodeTimeoutError(currentTask)
+	}
+}
+
+func newNodeTimeoutError(currentTask *task) error {
+	path, ok := getNodePath(currentTask.ctx)
+	if !ok {
+		path = NewNodePath(currentTask.nodeKey)
+	}
+	return &NodeTimeoutError{NodePath: *path, Timeout: currentTask.timeout}
+}
+
 func (t *taskManager) submit(tasks []*task) error {
 	if len(tasks) == 0 {
 		return nil
@@ -54,6 +54,9 @@ type nodeInfo struct {
 	preProcessor, postProcessor *composableRunnable
 
 	compileOption *graphCompileOptions // if the node is an AnyGraph, it will need compile options of its own
+
+	retryPolicy *RetryPolicy
+	cachePolicy *CachePolicy
 }
 
 // graphNode the complete information of the node in graph
@@ -137,6 +140,19 @@ func (gn *graphNode) compileIfNeeded(ctx context.Context) (*composableRunnable,
 	r.meta = gn.executorMeta
 	r.nodeInfo = gn.nodeInfo
 
+	flags := getNodeWrapperFlags(ctx)
+	if flags.dryRun {
+		r = dryRunComposableRunnable(r)
+	}
+	r = retryableComposableRunnable(gn.nodeInfo.retryPolicy, r)
+	r = cachedComposableRunnable(gn.nodeInfo.cachePolicy, r)
+	if flags.replay {
+		r = replayableComposableRunnable(r)
+	}
+	if flags.nodeOutputStream {
+		r = nodeOutputStreamedComposableRunnable(r)
+	}
+
 	if gn.nodeInfo.outputKey != "" {
 		r = outputKeyedComposableRunnable(gn.nodeInfo.outputKey, r)
 	}
@@ -173,5 +189,7 @@ func getNodeInfo(opts ...GraphAddNodeOpt) (*nodeInfo, *graphAddNodeOpts) {
 		preProcessor:  opt.processor.statePreHandler,
 		postProcessor: opt.processor.statePostHandler,
 		compileOption: newGraphCompileOptions(opt.nodeOptions.graphCompileOption...),
+		retryPolicy:   opt.nodeOptions.retryPolicy,
+		cachePolicy:   opt.nodeOptions.cachePolicy,
 	}, opt
 }
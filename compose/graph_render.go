@@ -0,0 +1,413 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderMermaid renders the GraphInfo into a Mermaid flowchart.
+// Nodes are labeled with their keys and component types, and edges are drawn as follows:
+//   - control and data edge: solid arrow, labeled with its field mappings if any
+//   - data only edge: dotted arrow labeled "data"
+//   - control only edge: thick arrow labeled "control"
+//   - branch: a diamond connected to each of its end nodes with dotted arrows
+//   - error edge: dotted arrow labeled "error"
+//   - error branch: a diamond connected from the failing node with an error edge, and to each of its end nodes with dotted arrows
+//
+// Nested graphs whose GraphInfo is available are rendered as subgraphs with their own start and end.
+// GraphInfo is usually obtained from a GraphCompileCallback, e.g.
+//
+//	type renderCallback struct{}
+//
+//	func (renderCallback) OnFinish(ctx context.Context, info *compose.GraphInfo) {
+//		fmt.Println(compose.RenderMermaid(info))
+//	}
+//
+//	graph.Compile(ctx, compose.WithGraphCompileCallbacks(renderCallback{}))
+func RenderMermaid(info *GraphInfo) string {
+	rg := buildRenderGraph(info)
+
+	sb := &strings.Builder{}
+	sb.WriteString("flowchart TD\n")
+	writeMermaidCluster(sb, rg, 1)
+	for _, e := range rg.edges {
+		sb.WriteString("    ")
+		sb.WriteString(e.from)
+		switch e.kind {
+		case renderEdgeData:
+			sb.WriteString(" -.->")
+		case renderEdgeControl:
+			sb.WriteString(" ==>")
+		case renderEdgeBranch, renderEdgeError:
+			sb.WriteString(" -.->")
+		default:
+			sb.WriteString(" -->")
+		}
+		if label := e.label(); label != "" {
+			sb.WriteString("|\"")
+			sb.WriteString(escapeMermaid(label))
+			sb.WriteString("\"|")
+		}
+		sb.WriteString(" ")
+		sb.WriteString(e.to)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func writeMermaidCluster(sb *strings.Builder, rg *renderGraph, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, n := range rg.nodes {
+		sb.WriteString(indent)
+		sb.WriteString(n.id)
+		label := "\"" + escapeMermaid(n.label) + "\""
+		switch n.shape {
+		case renderShapeTerminal:
+			sb.WriteString("([" + label + "])")
+		case renderShapeBranch:
+			sb.WriteString("{" + label + "}")
+		default:
+			sb.WriteString("[" + label + "]")
+		}
+		sb.WriteString("\n")
+	}
+	for _, c := range rg.clusters {
+		sb.WriteString(indent)
+		sb.WriteString("subgraph ")
+		sb.WriteString(c.id)
+		sb.WriteString(" [\"")
+		sb.WriteString(escapeMermaid(c.label))
+		sb.WriteString("\"]\n")
+		writeMermaidCluster(sb, c, depth+1)
+		sb.WriteString(indent)
+		sb.WriteString("end\n")
+	}
+}
+
+func escapeMermaid(s string) string {
+	s = strings.ReplaceAll(s, "\"", "#quot;")
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
+
+// RenderDOT renders the GraphInfo into Graphviz DOT text.
+// It follows the same conventions as RenderMermaid, nested graphs are rendered as clusters.
+func RenderDOT(info *GraphInfo) string {
+	rg := buildRenderGraph(info)
+
+	sb := &strings.Builder{}
+	sb.WriteString("digraph ")
+	sb.WriteString(quoteDOT(rg.label))
+	sb.WriteString(" {\n")
+	sb.WriteString("    node [shape=box];\n")
+	writeDOTCluster(sb, rg, 1)
+	for _, e := range rg.edges {
+		sb.WriteString("    ")
+		sb.WriteString(quoteDOT(e.from))
+		sb.WriteString(" -> ")
+		sb.WriteString(quoteDOT(e.to))
+
+		var attrs []string
+		if label := e.label(); label != "" {
+			attrs = append(attrs, "label="+quoteDOT(label))
+		}
+		switch e.kind {
+		case renderEdgeData, renderEdgeBranch:
+			attrs = append(attrs, "style=dashed")
+		case renderEdgeControl:
+			attrs = append(attrs, "style=bold")
+		case renderEdgeError:
+			attrs = append(attrs, "style=dashed", "color=red")
+		}
+		if len(attrs) > 0 {
+			sb.WriteString(" [")
+			sb.WriteString(strings.Join(attrs, ", "))
+			sb.WriteString("]")
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func writeDOTCluster(sb *strings.Builder, rg *renderGraph, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, n := range rg.nodes {
+		sb.WriteString(indent)
+		sb.WriteString(quoteDOT(n.id))
+		sb.WriteString(" [label=")
+		sb.WriteString(quoteDOT(n.label))
+		switch n.shape {
+		case renderShapeTerminal:
+			sb.WriteString(", shape=ellipse")
+		case renderShapeBranch:
+			sb.WriteString(", shape=diamond")
+		}
+		sb.WriteString("];\n")
+	}
+	for _, c := range rg.clusters {
+		sb.WriteString(indent)
+		sb.WriteString("subgraph ")
+		sb.WriteString(quoteDOT("cluster_" + c.id))
+		sb.WriteString(" {\n")
+		sb.WriteString(indent)
+		sb.WriteString("    label=")
+		sb.WriteString(quoteDOT(c.label))
+		sb.WriteString(";\n")
+		writeDOTCluster(sb, c, depth+1)
+		sb.WriteString(indent)
+		sb.WriteString("}\n")
+	}
+}
+
+func quoteDOT(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return "\"" + strings.ReplaceAll(s, "\n", "\\n") + "\""
+}
+
+type renderShape int
+
+const (
+	renderShapeBox renderShape = iota
+	renderShapeTerminal
+	renderShapeBranch
+)
+
+type renderEdgeKind int
+
+const (
+	renderEdgeControlAndData renderEdgeKind = iota
+	renderEdgeData
+	renderEdgeControl
+	renderEdgeBranch
+	renderEdgeError
+)
+
+type renderNode struct {
+	id    string
+	label string
+	shape renderShape
+}
+
+type renderEdge struct {
+	from, to string
+	kind     renderEdgeKind
+	mappings []string
+}
+
+func (e renderEdge) label() string {
+	var parts []string
+	switch e.kind {
+	case renderEdgeData:
+		parts = append(parts, "data")
+	case renderEdgeControl:
+		parts = append(parts, "control")
+	case renderEdgeError:
+		parts = append(parts, "error")
+	}
+	parts = append(parts, e.mappings...)
+	return strings.Join(parts, "\n")
+}
+
+// renderGraph is the format independent layout of a GraphInfo, one for each level of nested graphs.
+// edges are only collected in the root, because they may cross the boundary of nested graphs.
+type renderGraph struct {
+	id    string
+	label string
+
+	// ids of the START and END of this graph
+	start, end string
+
+	nodes    []renderNode
+	clusters []*renderGraph
+	edges    []renderEdge
+}
+
+type renderGraphBuilder struct {
+	root *renderGraph
+	ids  map[string]string
+}
+
+func buildRenderGraph(info *GraphInfo) *renderGraph {
+	name := info.Name
+	if name == "" {
+		name = "graph"
+	}
+	b := &renderGraphBuilder{
+		root: &renderGraph{label: name},
+		ids:  map[string]string{},
+	}
+	b.build(b.root, info, nil)
+	return b.root
+}
+
+// nodeID returns an identifier valid for both Mermaid and DOT, and unique across nested graphs.
+func (b *renderGraphBuilder) nodeID(path []string) string {
+	full := strings.Join(path, "\x00")
+	if id, ok := b.ids[full]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", len(b.ids))
+	b.ids[full] = id
+	return id
+}
+
+func (b *renderGraphBuilder) build(rg *renderGraph, info *GraphInfo, prefix []string) {
+	pathOf := func(key string) []string {
+		return append(append(make([]string, 0, len(prefix)+1), prefix...), key)
+	}
+
+	rg.start = b.nodeID(pathOf(START))
+	rg.end = b.nodeID(pathOf(END))
+	rg.nodes = append(rg.nodes, renderNode{id: rg.start, label: START, shape: renderShapeTerminal})
+
+	// entry and exit of each node, which are the START and END of the nested graph if it's rendered as a cluster
+	entries := map[string]string{START: rg.start, END: rg.end}
+	exits := map[string]string{START: rg.start, END: rg.end}
+
+	for _, key := range sortedKeys(info.Nodes) {
+		node := info.Nodes[key]
+		if node.GraphInfo != nil {
+			cluster := &renderGraph{
+				id:    b.nodeID(pathOf(key)),
+				label: nodeLabel(key, node),
+			}
+			b.build(cluster, node.GraphInfo, pathOf(key))
+			rg.clusters = append(rg.clusters, cluster)
+			entries[key], exits[key] = cluster.start, cluster.end
+			continue
+		}
+
+		id := b.nodeID(pathOf(key))
+		rg.nodes = append(rg.nodes, renderNode{id: id, label: nodeLabel(key, node)})
+		entries[key], exits[key] = id, id
+	}
+	rg.nodes = append(rg.nodes, renderNode{id: rg.end, label: END, shape: renderShapeTerminal})
+
+	// field mappings are recorded on the successor
+	mappings := map[[2]string][]string{}
+	for _, to := range sortedKeys(info.Nodes) {
+		for _, m := range info.Nodes[to].Mappings {
+			k := [2]string{m.FromNodeKey(), to}
+			mappings[k] = append(mappings[k], mappingLabel(m))
+		}
+	}
+
+	data := map[[2]string]bool{}
+	for from, tos := range info.DataEdges {
+		for _, to := range tos {
+			data[[2]string{from, to}] = true
+		}
+	}
+	control := map[[2]string]bool{}
+	for from, tos := range info.Edges {
+		for _, to := range tos {
+			control[[2]string{from, to}] = true
+		}
+	}
+
+	var edges [][2]string
+	for k := range data {
+		edges = append(edges, k)
+	}
+	for k := range control {
+		if !data[k] {
+			edges = append(edges, k)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+
+	root := b.root
+	for _, k := range edges {
+		kind := renderEdgeControlAndData
+		if !control[k] {
+			kind = renderEdgeData
+		} else if !data[k] {
+			kind = renderEdgeControl
+		}
+		root.edges = append(root.edges, renderEdge{
+			from:     exits[k[0]],
+			to:       entries[k[1]],
+			kind:     kind,
+			mappings: mappings[k],
+		})
+	}
+
+	for _, from := range sortedKeys(info.ErrorEdges) {
+		root.edges = append(root.edges, renderEdge{from: exits[from], to: entries[info.ErrorEdges[from]], kind: renderEdgeError})
+	}
+
+	for _, from := range sortedKeys(info.ErrorBranches) {
+		id := b.nodeID(pathOf(from + "\x00error_branch"))
+		rg.nodes = append(rg.nodes, renderNode{id: id, label: "error branch", shape: renderShapeBranch})
+		root.edges = append(root.edges, renderEdge{from: exits[from], to: id, kind: renderEdgeError})
+		branch := info.ErrorBranches[from]
+		for _, to := range sortedKeys(branch.GetEndNode()) {
+			root.edges = append(root.edges, renderEdge{from: id, to: entries[to], kind: renderEdgeBranch})
+		}
+	}
+
+	for _, from := range sortedKeys(info.Branches) {
+		for i, branch := range info.Branches[from] {
+			id := b.nodeID(pathOf(fmt.Sprintf("%s\x00branch\x00%d", from, i)))
+			rg.nodes = append(rg.nodes, renderNode{id: id, label: "branch", shape: renderShapeBranch})
+			root.edges = append(root.edges, renderEdge{from: exits[from], to: id})
+			for _, to := range sortedKeys(branch.GetEndNode()) {
+				root.edges = append(root.edges, renderEdge{from: id, to: entries[to], kind: renderEdgeBranch})
+			}
+		}
+	}
+}
+
+func nodeLabel(key string, node GraphNodeInfo) string {
+	label := key
+	if node.Name != "" && node.Name != key {
+		label += " (" + node.Name + ")"
+	}
+	if node.Component != "" {
+		label += "\n" + string(node.Component)
+	}
+	return label
+}
+
+func mappingLabel(m *FieldMapping) string {
+	from, to := "*", "*"
+	if m.from != "" {
+		from = strings.Join(m.FromPath(), ".")
+	}
+	if m.to != "" {
+		to = strings.Join(m.ToPath(), ".")
+	}
+	return from + " -> " + to
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type renderTestCallback struct {
+	info *GraphInfo
+}
+
+func (r *renderTestCallback) OnFinish(_ context.Context, info *GraphInfo) {
+	r.info = info
+}
+
+type renderTestStruct struct {
+	Query string
+	Count int
+}
+
+func buildRenderTestGraph(t *testing.T) *GraphInfo {
+	ctx := context.Background()
+
+	sub := NewGraph[string, string]()
+	assert.NoError(t, sub.AddLambdaNode("inner", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return in, nil
+	})))
+	assert.NoError(t, sub.AddEdge(START, "inner"))
+	assert.NoError(t, sub.AddEdge("inner", END))
+
+	wf := NewWorkflow[renderTestStruct, map[string]any]()
+	wf.AddLambdaNode("query", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return in, nil
+	}), WithNodeName("rewrite \"query\"")).AddInput(START, MapFields("Query", ""))
+	wf.AddGraphNode("sub", sub).AddInput("query")
+	wf.AddLambdaNode("count", InvokableLambda(func(ctx context.Context, in int) (int, error) {
+		return in, nil
+	})).AddInputWithOptions(START, []*FieldMapping{MapFields("Count", "")}, WithNoDirectDependency()).
+		AddDependency("query")
+	wf.AddBranch("query", NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+		return "count", nil
+	}, map[string]bool{"count": true, END: true}))
+	wf.End().AddInput("sub", ToField("answer")).AddInput("count", ToField("count"))
+
+	cb := &renderTestCallback{}
+	_, err := wf.Compile(ctx, WithGraphName("rag"), WithGraphCompileCallbacks(cb))
+	assert.NoError(t, err)
+	assert.NotNil(t, cb.info)
+	return cb.info
+}
+
+func TestRenderMermaid(t *testing.T) {
+	info := buildRenderTestGraph(t)
+	out := RenderMermaid(info)
+	expected := `flowchart TD
+    n0(["start"])
+    n2["count<br/>Lambda"]
+    n3["query (rewrite #quot;query#quot;)<br/>Lambda"]
+    n1(["end"])
+    n8{"branch"}
+    subgraph n4 ["sub<br/>Graph"]
+        n5(["start"])
+        n7["inner<br/>Lambda"]
+        n6(["end"])
+    end
+    n7 --> n6
+    n5 --> n7
+    n2 --> n1
+    n3 ==>|"control"| n2
+    n3 --> n5
+    n0 -.->|"data<br/>Count -> *"| n2
+    n0 -->|"Query -> *"| n3
+    n6 --> n1
+    n3 --> n8
+    n8 -.-> n2
+    n8 -.-> n1
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestRenderDOT(t *testing.T) {
+	info := buildRenderTestGraph(t)
+	out := RenderDOT(info)
+	expected := `digraph "rag" {
+    node [shape=box];
+    "n0" [label="start", shape=ellipse];
+    "n2" [label="count\nLambda"];
+    "n3" [label="query (rewrite \"query\")\nLambda"];
+    "n1" [label="end", shape=ellipse];
+    "n8" [label="branch", shape=diamond];
+    subgraph "cluster_n4" {
+        label="sub\nGraph";
+        "n5" [label="start", shape=ellipse];
+        "n7" [label="inner\nLambda"];
+        "n6" [label="end", shape=ellipse];
+    }
+    "n7" -> "n6";
+    "n5" -> "n7";
+    "n2" -> "n1";
+    "n3" -> "n2" [label="control", style=bold];
+    "n3" -> "n5";
+    "n0" -> "n2" [label="data\nCount -> *", style=dashed];
+    "n0" -> "n3" [label="Query -> *"];
+    "n6" -> "n1";
+    "n3" -> "n8";
+    "n8" -> "n2" [style=dashed];
+    "n8" -> "n1" [style=dashed];
+}
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestRenderErrorBranch(t *testing.T) {
+	ctx := context.Background()
+	g := NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("retriever", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		return in, nil
+	})))
+	for _, key := range []string{"fallback", "apology"} {
+		assert.NoError(t, g.AddLambdaNode(key, InvokableLambda(func(ctx context.Context, in *NodeError) (string, error) {
+			return "", nil
+		})))
+		assert.NoError(t, g.AddEdge(key, END))
+	}
+	assert.NoError(t, g.AddEdge(START, "retriever"))
+	assert.NoError(t, g.AddEdge("retriever", END))
+	assert.NoError(t, g.AddErrorBranch("retriever", NewGraphBranch(func(ctx context.Context, in *NodeError) (string, error) {
+		return "apology", nil
+	}, map[string]bool{"fallback": true, "apology": true})))
+
+	cb := &renderTestCallback{}
+	_, err := g.Compile(ctx, WithGraphCompileCallbacks(cb))
+	assert.NoError(t, err)
+	assert.Empty(t, cb.info.ErrorEdges)
+	branch := cb.info.ErrorBranches["retriever"]
+	assert.Equal(t, map[string]bool{"fallback": true, "apology": true}, branch.GetEndNode())
+
+	expected := `flowchart TD
+    n0(["start"])
+    n2["apology<br/>Lambda"]
+    n3["fallback<br/>Lambda"]
+    n4["retriever<br/>Lambda"]
+    n1(["end"])
+    n5{"error branch"}
+    n2 --> n1
+    n3 --> n1
+    n4 --> n1
+    n0 --> n4
+    n4 -.->|"error"| n5
+    n5 -.-> n2
+    n5 -.-> n3
+`
+	assert.Equal(t, expected, RenderMermaid(cb.info))
+}
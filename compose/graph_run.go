@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/cloudwego/eino/internal"
 	"github.com/cloudwego/eino/internal/core"
@@ -35,9 +36,25 @@ type chanCall struct {
 
 	controls []string // branch must control
 
+	errorTo *GraphBranch // selects the error handler nodes, see AddErrorEdge and AddErrorBranch
+
+	subTaskSuccessors map[string]reflect.Type // the successors the outputs of sub-tasks can be sent to, see RunSubTaskTo
+
 	preProcessor, postProcessor *composableRunnable
 }
 
+// errorHandlers returns the error handler nodes the failure of the node may be routed to.
+func (c *chanCall) errorHandlers() []string {
+	if c.errorTo == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(c.errorTo.endNodes))
+	for node := range c.errorTo.endNodes {
+		ret = append(ret, node)
+	}
+	return ret
+}
+
 type chanBuilder func(dependencies []string, indirectDependencies []string, zeroValue func() any, emptyStream func() streamReader) channel
 
 type runner struct {
@@ -81,6 +98,12 @@ type runner struct {
 	interruptAfterNodes  []string
 
 	mergeConfigs map[string]FanInMergeConfig
+
+	// the number of nodes on the longest path from each node to END, only available in dag mode
+	stepsToEnd map[string]int
+
+	// the flags the nodes are compiled with, including those inherited from the parent graphs
+	nodeWrapperFlags nodeWrapperFlags
 }
 
 func (r *runner) invoke(ctx context.Context, input any, opts ...Option) (any, error) {
@@ -119,6 +142,12 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		}
 	}()
 
+	// attached before any return, so that the stream of WithNodeOutputStream is closed even if the run fails early
+	ctx, emitter := withNodeOutputEmitter(ctx, opts)
+	if emitter != nil {
+		defer emitter.finish()
+	}
+
 	var runWrapper runnableCallWrapper
 	runWrapper = runnableInvoke
 	if isStream {
@@ -140,16 +169,36 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 	if extractErr != nil {
 		return nil, newGraphRunError(fmt.Errorf("graph extract option fail: %w", extractErr))
 	}
+	nodeTimeouts := r.resolveNodeTimeouts(opts)
+	if err = r.checkNodeWrapperOptions(opts); err != nil {
+		return nil, newGraphRunError(err)
+	}
+	ctx = withReplayer(ctx, opts)
+	ctx = withConcurrencyLimiter(ctx, r.options.concurrencyLimiter)
+	ctx, resumeNodeSlot := suspendNodeSlot(ctx)
+	defer resumeNodeSlot()
 
 	// Extract CheckPointID
 	checkPointID, writeToCheckPointID, stateModifier, forceNewRun := getCheckPointInfo(opts...)
 	if checkPointID != nil && r.checkPointer.store == nil {
 		return nil, newGraphRunError(fmt.Errorf("receive checkpoint id but have not set checkpoint store"))
 	}
+	if writeToCheckPointID != nil && core.IsCheckPointVersionID(*writeToCheckPointID) {
+		return nil, newGraphRunError(fmt.Errorf("checkpoint version[%s] is read-only, use WithWriteToCheckPointID to fork from it", *writeToCheckPointID))
+	}
 
 	// Extract subgraph
 	path, isSubGraph := getNodePath(ctx)
 
+	var history *checkPointHistory
+	if !isSubGraph {
+		history, err = r.initCheckPointHistory(writeToCheckPointID, opts)
+		if err != nil {
+			return nil, err
+		}
+		ctx = withCheckPointHistory(ctx, history)
+	}
+
 	// load checkpoint from ctx/store or init graph
 	initialized := false
 	var nextTasks []*task
@@ -177,6 +226,9 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		if cp != nil {
 			// load checkpoint from store
 			initialized = true
+			if history != nil && *checkPointID != *writeToCheckPointID {
+				history.forkedFrom = *checkPointID
+			}
 
 			ctx = setStateModifier(ctx, stateModifier)
 			ctx = setCheckPointToCtx(ctx, cp)
@@ -249,11 +301,23 @@ func (r *runner) run(ctx context.Context, isStream bool, input any, opts ...Opti
 		if !r.dag && step >= maxSteps {
 			return nil, newGraphRunError(ErrExceedMaxSteps)
 		}
+		if history != nil {
+			history.step = step
+			if !isStream && !(initialized && step == 0) {
+				if err = r.saveStepVersion(ctx, history, *writeToCheckPointID, nextTasks, cm); err != nil {
+					return nil, newGraphRunError(fmt.Errorf("failed to save checkpoint version: %w", err))
+				}
+			}
+		}
 
 		// 1. submit next tasks
 		// 2. get completed tasks
 		// 3. calculate next tasks
 
+		r.setTaskTimeouts(ctx, nextTasks, nodeTimeouts, maxSteps-step)
+		for _, t := range nextTasks {
+			t.step = step
+		}
 		err = tm.submit(nextTasks)
 		if err != nil {
 			return nil, newGraphRunError(fmt.Errorf("failed to submit tasks: %w", err))
@@ -379,6 +443,72 @@ func (r *runner) resolveMaxSteps(maxSteps int, opts []Option) (int, error) {
 	return maxSteps, nil
 }
 
+// checkNodeWrapperOptions fails the run options requiring the nodes to be wrapped at compile time, if they aren't.
+func (r *runner) checkNodeWrapperOptions(opts []Option) error {
+	for _, opt := range opts {
+		if opt.replay != nil && !r.nodeWrapperFlags.replay {
+			return errors.New("graph isn't compiled with WithReplaySupport, which is required by WithReplay")
+		}
+		if opt.nodeOutputEmitter != nil && !r.nodeWrapperFlags.nodeOutputStream {
+			return errors.New("graph isn't compiled with WithNodeOutputStreamSupport, which is required by WithNodeOutputStream")
+		}
+	}
+	return nil
+}
+
+func (r *runner) resolveNodeTimeouts(opts []Option) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(r.options.nodeTimeouts))
+	for key, timeout := range r.options.nodeTimeouts {
+		timeouts[key] = timeout
+	}
+	for _, opt := range opts {
+		if opt.nodeTimeout == nil {
+			continue
+		}
+		if len(opt.paths) == 0 {
+			for key := range r.chanSubscribeTo {
+				timeouts[key] = *opt.nodeTimeout
+			}
+			continue
+		}
+		for _, path := range opt.paths {
+			// options designated to nodes inside subgraphs are forwarded by extractOption
+			if len(path.path) == 1 {
+				timeouts[path.path[0]] = *opt.nodeTimeout
+			}
+		}
+	}
+	return timeouts
+}
+
+// setTaskTimeouts sets the timeout of each task to the node's timeout,
+// or to an even share of the time left before the run deadline if WithDeadlineSplit is enabled, whichever is shorter.
+func (r *runner) setTaskTimeouts(ctx context.Context, tasks []*task, timeouts map[string]time.Duration, stepsLeft int) {
+	deadline, hasDeadline := ctx.Deadline()
+	for _, t := range tasks {
+		t.timeout = timeouts[t.nodeKey]
+		if !r.options.deadlineSplit || !hasDeadline {
+			continue
+		}
+
+		steps := stepsLeft
+		if r.dag {
+			steps = r.stepsToEnd[t.nodeKey]
+		}
+		if steps < 1 {
+			steps = 1
+		}
+		share := time.Until(deadline) / time.Duration(steps)
+		if share <= 0 {
+			// leave it to the context deadline
+			continue
+		}
+		if t.timeout <= 0 || share < t.timeout {
+			t.timeout = share
+		}
+	}
+}
+
 func (r *runner) restoreCheckPointState(
 	ctx context.Context,
 	path NodePath,
@@ -473,6 +603,11 @@ func (r *runner) resolveInterruptCompletedTasks(tempInfo *interruptTempInfo, com
 				continue
 			}
 
+			if completedTask.call.errorTo != nil {
+				// routed to the error handler nodes
+				continue
+			}
+
 			return wrapGraphNodeError(completedTask.nodeKey, completedTask.err)
 		}
 
@@ -543,6 +678,9 @@ func (r *runner) handleInterrupt(
 	}
 
 	cp.InterruptID2Addr, cp.InterruptID2State = core.SignalToPersistenceMaps(is)
+	if !isSubGraph {
+		cp.InterruptID2Point = core.SignalToInterruptPoints(is, r.checkPointer.canSerialize)
+	}
 
 	for _, t := range nextTasks {
 		cp.Inputs[t.nodeKey] = t.input
@@ -673,6 +811,9 @@ func (r *runner) handleInterruptWithSubGraphAndRerunNodes(
 	}
 
 	cp.InterruptID2Addr, cp.InterruptID2State = core.SignalToPersistenceMaps(is)
+	if !isSubGraph {
+		cp.InterruptID2Point = core.SignalToInterruptPoints(is, r.checkPointer.canSerialize)
+	}
 
 	for _, t := range subgraphTasks {
 		cp.RerunNodes = append(cp.RerunNodes, t.nodeKey)
@@ -753,6 +894,20 @@ func (r *runner) createTasks(ctx context.Context, nodeMap map[string]any, optMap
 	return nextTasks, nil
 }
 
+func (r *runner) initCheckPointHistory(writeToCheckPointID *string, opts []Option) (*checkPointHistory, error) {
+	enabled := false
+	for _, opt := range opts {
+		enabled = enabled || opt.checkPointHistory
+	}
+	if !enabled {
+		return nil, nil
+	}
+	if writeToCheckPointID == nil || r.checkPointer.store == nil {
+		return nil, newGraphRunError(errors.New("checkpoint history requires a checkpoint store and a checkpoint id"))
+	}
+	return &checkPointHistory{}, nil
+}
+
 func getCheckPointInfo(opts ...Option) (checkPointID *string, writeToCheckPointID *string, stateModifier StateModifier, forceNewRun bool) {
 	for _, opt := range opts {
 		if opt.checkPointID != nil {
@@ -827,6 +982,16 @@ func (r *runner) resolveCompletedTasks(ctx context.Context, completedTasks []*ta
 	writeChannelValues := make(map[string]map[string]any)
 	newDependencies := make(map[string][]string)
 	for _, t := range completedTasks {
+		if t.call.errorTo != nil {
+			handled, err := r.resolveErrorEdge(ctx, t, isStream, cm, writeChannelValues, newDependencies)
+			if err != nil {
+				return nil, nil, err
+			}
+			if handled {
+				continue
+			}
+		}
+
 		for _, key := range t.call.controls {
 			newDependencies[key] = append(newDependencies[key], t.nodeKey)
 		}
@@ -857,10 +1022,83 @@ func (r *runner) resolveCompletedTasks(ctx context.Context, completedTasks []*ta
 				writeChannelValues[next][t.nodeKey] = vs[i]
 			}
 		}
+
+		for next, output := range t.subTaskOutputs {
+			value, err := r.toSubTaskInput(next, output, isStream)
+			if err != nil {
+				return nil, nil, fmt.Errorf("node[%s] sub task output to node[%s] fail: %w", t.nodeKey, next, err)
+			}
+			if sr, ok := writeChannelValues[next][t.nodeKey].(streamReader); ok {
+				sr.close()
+			}
+			writeChannelValues[next][t.nodeKey] = value
+		}
 	}
 	return writeChannelValues, newDependencies, nil
 }
 
+// toSubTaskInput converts the output of the sub-task to the value written to the channel of the successor, see RunSubTaskTo.
+func (r *runner) toSubTaskInput(successor string, output any, isStream bool) (any, error) {
+	if !isStream {
+		return output, nil
+	}
+	return r.chanSubscribeTo[successor].action.inputStreamConvertPair.restoreStream(output)
+}
+
+// resolveErrorEdge routes the failure of the node to the error handler nodes selected by its error branch,
+// and skips the other successors, including the error handler nodes not selected.
+// If the node succeeds, the error handler nodes are skipped instead.
+func (r *runner) resolveErrorEdge(ctx context.Context, t *task, isStream bool, cm *channelManager,
+	writeChannelValues map[string]map[string]any, newDependencies map[string][]string) (bool, error) {
+	if t.err == nil {
+		return false, cm.reportBranch(t.nodeKey, t.call.errorHandlers())
+	}
+	var nodeErr *NodeError
+	if !errors.As(t.err, &nodeErr) {
+		return false, wrapGraphNodeError(t.nodeKey, t.err)
+	}
+
+	// the condition receives the *NodeError as is in stream mode too
+	handlers, err := t.call.errorTo.invoke(ctx, nodeErr)
+	if err != nil {
+		return false, fmt.Errorf("error branch of node[%s] run error: %w", t.nodeKey, err)
+	}
+	recordDryRunBranch(ctx, t.nodeKey, handlers)
+
+	selected := make(map[string]bool, len(handlers))
+	for _, handler := range handlers {
+		if !t.call.errorTo.endNodes[handler] {
+			return false, fmt.Errorf("error branch of node[%s] returns unintended end node: %s", t.nodeKey, handler)
+		}
+		if selected[handler] {
+			continue
+		}
+		selected[handler] = true
+
+		var input any = nodeErr
+		if isStream {
+			sr, err := r.chanSubscribeTo[handler].action.inputStreamConvertPair.restoreStream(nodeErr)
+			if err != nil {
+				return false, fmt.Errorf("node[%s] error to handler[%s] fail: %w", t.nodeKey, handler, err)
+			}
+			input = sr
+		}
+		if _, ok := writeChannelValues[handler]; !ok {
+			writeChannelValues[handler] = make(map[string]any)
+		}
+		writeChannelValues[handler][t.nodeKey] = input
+		newDependencies[handler] = append(newDependencies[handler], t.nodeKey)
+	}
+
+	var skipped []string
+	for _, successor := range r.successors[t.nodeKey] {
+		if !selected[successor] {
+			skipped = append(skipped, successor)
+		}
+	}
+	return true, cm.reportBranch(t.nodeKey, skipped)
+}
+
 func (r *runner) calculateBranch(ctx context.Context, curNodeKey string, startChan *chanCall, input []any, isStream bool, cm *channelManager) ([]string, error) {
 	if len(input) < len(startChan.writeToBranches) {
 		// unreachable
@@ -891,6 +1129,7 @@ func (r *runner) calculateBranch(ctx context.Context, curNodeKey string, startCh
 				return nil, fmt.Errorf("branch invoke run error: %w", err)
 			}
 		}
+		recordDryRunBranch(ctx, curNodeKey, ws)
 
 		for node := range branch.endNodes {
 			skipped := true
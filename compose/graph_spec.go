@@ -0,0 +1,569 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+const (
+	// GraphSpecTypeGraph declares the spec to be built as a Graph, which is the default.
+	GraphSpecTypeGraph = "graph"
+	// GraphSpecTypeWorkflow declares the spec to be built as a Workflow.
+	GraphSpecTypeWorkflow = "workflow"
+
+	// SpecNodeTypePassthrough is the built-in node type for passthrough nodes, no factory is needed.
+	SpecNodeTypePassthrough = "passthrough"
+)
+
+// GraphSpec is the declarative definition of a Graph or a Workflow.
+// It's usually parsed from a YAML or JSON document by ParseGraphSpec, e.g.
+//
+//	name: rag
+//	type: workflow
+//	nodes:
+//	  - key: retriever
+//	    type: my_retriever
+//	    config:
+//	      top_k: 3
+//	  - key: model
+//	    type: my_chat_model
+//	edges:
+//	  - from: start
+//	    to: retriever
+//	    mappings:
+//	      - from: query
+//	  - from: retriever
+//	    to: model
+//	  - from: model
+//	    to: end
+//
+// The type of each node refers to a factory registered in ComponentRegistry.
+type GraphSpec struct {
+	// Name is used as the graph name, see WithGraphName.
+	Name string `yaml:"name"`
+	// Type is either GraphSpecTypeGraph or GraphSpecTypeWorkflow, defaults to GraphSpecTypeGraph.
+	Type     string        `yaml:"type"`
+	Nodes    []*NodeSpec   `yaml:"nodes"`
+	Edges    []*EdgeSpec   `yaml:"edges"`
+	Branches []*BranchSpec `yaml:"branches"`
+	Compile  *CompileSpec  `yaml:"compile"`
+}
+
+// NodeSpec declares a node.
+type NodeSpec struct {
+	Key string `yaml:"key"`
+	// Type is the registered component type, or SpecNodeTypePassthrough.
+	Type      string      `yaml:"type"`
+	Name      string      `yaml:"name"`
+	InputKey  string      `yaml:"input_key"`
+	OutputKey string      `yaml:"output_key"`
+	Config    *SpecConfig `yaml:"config"`
+
+	line int
+}
+
+// EdgeSpec declares an edge between two nodes.
+// Mappings, NoDirectDependency and DependencyOnly are only supported by workflows,
+// they correspond to WorkflowNode.AddInputWithOptions and WorkflowNode.AddDependency respectively.
+type EdgeSpec struct {
+	From     string         `yaml:"from"`
+	To       string         `yaml:"to"`
+	Mappings []*MappingSpec `yaml:"mappings"`
+
+	NoDirectDependency bool `yaml:"no_direct_dependency"`
+	DependencyOnly     bool `yaml:"dependency_only"`
+
+	line int
+}
+
+// MappingSpec declares a field mapping of a workflow edge.
+// From and To are field paths separated by '.', an empty path refers to the whole input or output.
+type MappingSpec struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// BranchSpec declares a branch, whose condition is created by the BranchFactory registered for Type.
+type BranchSpec struct {
+	From     string      `yaml:"from"`
+	Type     string      `yaml:"type"`
+	EndNodes []string    `yaml:"end_nodes"`
+	Config   *SpecConfig `yaml:"config"`
+
+	line int
+}
+
+// CompileSpec declares the compile options of the graph.
+type CompileSpec struct {
+	MaxRunSteps int `yaml:"max_run_steps"`
+	// NodeTriggerMode is either "any_predecessor" or "all_predecessor".
+	NodeTriggerMode      string   `yaml:"node_trigger_mode"`
+	InterruptBeforeNodes []string `yaml:"interrupt_before_nodes"`
+	InterruptAfterNodes  []string `yaml:"interrupt_after_nodes"`
+	// NodeTimeouts maps node keys to durations in the format of time.ParseDuration, e.g. "30s".
+	NodeTimeouts map[string]string `yaml:"node_timeouts"`
+
+	line int
+}
+
+// SpecConfig holds the raw config of a node or branch, which is decoded by its factory.
+type SpecConfig struct {
+	node *yaml.Node
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *SpecConfig) UnmarshalYAML(value *yaml.Node) error {
+	c.node = value
+	return nil
+}
+
+// Decode decodes the config into v, which is usually a pointer to the config struct of the component.
+// Decoding an absent config leaves v untouched.
+func (c *SpecConfig) Decode(v any) error {
+	if c == nil || c.node == nil {
+		return nil
+	}
+	return c.node.Decode(v)
+}
+
+// SpecError is returned when a GraphSpec fails to be built or compiled,
+// Line is the line number of the offending item in the source document, 0 if unknown.
+type SpecError struct {
+	Line int
+	Err  error
+}
+
+func (e *SpecError) Error() string {
+	if e.Line <= 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+func specErrorf(line int, format string, a ...any) error {
+	return &SpecError{Line: line, Err: fmt.Errorf(format, a...)}
+}
+
+// ComponentFactory creates a component from its config.
+// The returned instance must be one of:
+// *Lambda, *ToolsNode, AnyGraph, model.BaseChatModel, prompt.ChatTemplate, retriever.Retriever,
+// embedding.Embedder, indexer.Indexer, document.Loader or document.Transformer.
+type ComponentFactory func(ctx context.Context, config *SpecConfig) (any, error)
+
+// BranchFactory creates a branch from its config and the end nodes declared in the spec.
+type BranchFactory func(ctx context.Context, config *SpecConfig, endNodes map[string]bool) (*GraphBranch, error)
+
+// ComponentRegistry maps type names used in GraphSpec to factories.
+type ComponentRegistry struct {
+	components map[string]ComponentFactory
+	branches   map[string]BranchFactory
+}
+
+// NewComponentRegistry creates an empty ComponentRegistry.
+func NewComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{
+		components: make(map[string]ComponentFactory),
+		branches:   make(map[string]BranchFactory),
+	}
+}
+
+// RegisterComponent registers the factory of a node type.
+func (r *ComponentRegistry) RegisterComponent(typ string, factory ComponentFactory) error {
+	if typ == SpecNodeTypePassthrough {
+		return fmt.Errorf("component type '%s' is reserved", typ)
+	}
+	if _, ok := r.components[typ]; ok {
+		return fmt.Errorf("component type '%s' has been registered", typ)
+	}
+	r.components[typ] = factory
+	return nil
+}
+
+// RegisterBranch registers the factory of a branch type.
+func (r *ComponentRegistry) RegisterBranch(typ string, factory BranchFactory) error {
+	if _, ok := r.branches[typ]; ok {
+		return fmt.Errorf("branch type '%s' has been registered", typ)
+	}
+	r.branches[typ] = factory
+	return nil
+}
+
+// ParseGraphSpec parses a GraphSpec from a YAML or JSON document.
+// Unknown fields are rejected, and the line numbers of nodes, edges and branches are kept for error reporting.
+func ParseGraphSpec(data []byte) (*GraphSpec, error) {
+	spec := &GraphSpec{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(spec); err != nil {
+		return nil, fmt.Errorf("parse graph spec fail: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse graph spec fail: %w", err)
+	}
+	if len(doc.Content) > 0 {
+		spec.recordLines(doc.Content[0])
+	}
+
+	return spec, nil
+}
+
+func (s *GraphSpec) recordLines(root *yaml.Node) {
+	if root.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		switch key {
+		case "nodes":
+			for j, item := range value.Content {
+				if j < len(s.Nodes) && s.Nodes[j] != nil {
+					s.Nodes[j].line = item.Line
+				}
+			}
+		case "edges":
+			for j, item := range value.Content {
+				if j < len(s.Edges) && s.Edges[j] != nil {
+					s.Edges[j].line = item.Line
+				}
+			}
+		case "branches":
+			for j, item := range value.Content {
+				if j < len(s.Branches) && s.Branches[j] != nil {
+					s.Branches[j].line = item.Line
+				}
+			}
+		case "compile":
+			if s.Compile != nil {
+				s.Compile.line = value.Line
+			}
+		}
+	}
+}
+
+// CompileGraphSpec parses the YAML or JSON document, builds the graph with components created from the registry,
+// and compiles it. opts are applied after the compile options declared in the spec.
+func CompileGraphSpec[I, O any](ctx context.Context, data []byte, registry *ComponentRegistry, opts ...GraphCompileOption) (Runnable[I, O], error) {
+	spec, err := ParseGraphSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g, specOpts, err := NewGraphFromSpec[I, O](ctx, spec, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	return compileAnyGraph[I, O](ctx, g, append(specOpts, opts...)...)
+}
+
+// NewGraphFromSpec builds a *Graph[I, O] or a *Workflow[I, O] according to the spec,
+// and returns it along with the compile options declared in the spec.
+// Node and edge types are checked while building, errors are reported as *SpecError if the source line is known.
+func NewGraphFromSpec[I, O any](ctx context.Context, spec *GraphSpec, registry *ComponentRegistry) (AnyGraph, []GraphCompileOption, error) {
+	if registry == nil {
+		registry = NewComponentRegistry()
+	}
+
+	opts, err := spec.compileOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch spec.Type {
+	case "", GraphSpecTypeGraph:
+		g := NewGraph[I, O]()
+		if err = buildGraphFromSpec(ctx, spec, registry, g); err != nil {
+			return nil, nil, err
+		}
+		return g, opts, nil
+	case GraphSpecTypeWorkflow:
+		wf := NewWorkflow[I, O]()
+		if err = buildWorkflowFromSpec(ctx, spec, registry, wf); err != nil {
+			return nil, nil, err
+		}
+		return wf, opts, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown graph spec type: %s", spec.Type)
+	}
+}
+
+func (s *GraphSpec) compileOptions() ([]GraphCompileOption, error) {
+	var opts []GraphCompileOption
+	if s.Name != "" {
+		opts = append(opts, WithGraphName(s.Name))
+	}
+
+	c := s.Compile
+	if c == nil {
+		return opts, nil
+	}
+
+	if c.MaxRunSteps > 0 {
+		opts = append(opts, WithMaxRunSteps(c.MaxRunSteps))
+	}
+	switch NodeTriggerMode(c.NodeTriggerMode) {
+	case "":
+	case AnyPredecessor, AllPredecessor:
+		opts = append(opts, WithNodeTriggerMode(NodeTriggerMode(c.NodeTriggerMode)))
+	default:
+		return nil, specErrorf(c.line, "unknown node trigger mode: %s", c.NodeTriggerMode)
+	}
+	if len(c.InterruptBeforeNodes) > 0 {
+		opts = append(opts, WithInterruptBeforeNodes(c.InterruptBeforeNodes))
+	}
+	if len(c.InterruptAfterNodes) > 0 {
+		opts = append(opts, WithInterruptAfterNodes(c.InterruptAfterNodes))
+	}
+	if len(c.NodeTimeouts) > 0 {
+		timeouts := make(map[string]time.Duration, len(c.NodeTimeouts))
+		for key, v := range c.NodeTimeouts {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, specErrorf(c.line, "invalid timeout of node '%s': %w", key, err)
+			}
+			timeouts[key] = d
+		}
+		opts = append(opts, WithNodeTimeouts(timeouts))
+	}
+
+	return opts, nil
+}
+
+func buildGraphFromSpec[I, O any](ctx context.Context, spec *GraphSpec, registry *ComponentRegistry, g *Graph[I, O]) error {
+	for _, ns := range spec.Nodes {
+		if err := addNodeFromSpec(ctx, g.graph, registry, ns); err != nil {
+			return err
+		}
+	}
+
+	for _, es := range spec.Edges {
+		if len(es.Mappings) > 0 || es.NoDirectDependency || es.DependencyOnly {
+			return specErrorf(es.line, "edge[%s]-[%s]: mappings and dependency options are only supported by workflow", es.From, es.To)
+		}
+		if err := g.AddEdge(es.From, es.To); err != nil {
+			return &SpecError{Line: es.line, Err: err}
+		}
+	}
+
+	for _, bs := range spec.Branches {
+		branch, err := newBranchFromSpec(ctx, registry, bs)
+		if err != nil {
+			return err
+		}
+		if err = g.AddBranch(bs.From, branch); err != nil {
+			return &SpecError{Line: bs.line, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func buildWorkflowFromSpec[I, O any](ctx context.Context, spec *GraphSpec, registry *ComponentRegistry, wf *Workflow[I, O]) error {
+	for _, ns := range spec.Nodes {
+		if err := addNodeFromSpec(ctx, wf.g, registry, ns); err != nil {
+			return err
+		}
+		wf.initNode(ns.Key)
+	}
+
+	for _, es := range spec.Edges {
+		var n *WorkflowNode
+		if es.To == END {
+			n = wf.End()
+		} else if n = wf.workflowNodes[es.To]; n == nil {
+			return specErrorf(es.line, "edge end node '%s' needs to be added to workflow first", es.To)
+		}
+
+		switch {
+		case es.DependencyOnly:
+			if len(es.Mappings) > 0 || es.NoDirectDependency {
+				return specErrorf(es.line, "edge[%s]-[%s]: dependency only edge cannot have mappings or no direct dependency", es.From, es.To)
+			}
+			n.AddDependency(es.From)
+		case es.NoDirectDependency:
+			n.AddInputWithOptions(es.From, mappingsFromSpec(es.Mappings), WithNoDirectDependency())
+		default:
+			n.AddInput(es.From, mappingsFromSpec(es.Mappings)...)
+		}
+
+		// inputs are added when the workflow compiles, annotate the error with the line of the edge then
+		addInput := n.addInputs[len(n.addInputs)-1]
+		line := es.line
+		n.addInputs[len(n.addInputs)-1] = func() error {
+			if wf.g.buildError != nil {
+				return addInput()
+			}
+			if err := addInput(); err != nil {
+				return &SpecError{Line: line, Err: err}
+			}
+			return nil
+		}
+	}
+
+	for _, bs := range spec.Branches {
+		branch, err := newBranchFromSpec(ctx, registry, bs)
+		if err != nil {
+			return err
+		}
+
+		// workflow adds branches when compiling, check what graph.addBranch would check in advance
+		if bs.From == END {
+			return specErrorf(bs.line, "END cannot be a start node")
+		}
+		if _, ok := wf.g.nodes[bs.From]; !ok && bs.From != START {
+			return specErrorf(bs.line, "branch start node '%s' needs to be added to workflow first", bs.From)
+		}
+		for endNode := range branch.endNodes {
+			if _, ok := wf.workflowNodes[endNode]; !ok && endNode != END {
+				return specErrorf(bs.line, "branch end node '%s' needs to be added to workflow first", endNode)
+			}
+		}
+		if outputType := wf.g.getNodeOutputType(bs.From); outputType != nil &&
+			checkAssignable(outputType, branch.inputType) == assignableTypeMustNot {
+			return specErrorf(bs.line, "condition inputType[%s] and start node[%s]'s outputType[%s] are mismatch",
+				branch.inputType, bs.From, outputType)
+		}
+
+		wf.AddBranch(bs.From, branch)
+	}
+
+	return nil
+}
+
+func addNodeFromSpec(ctx context.Context, g *graph, registry *ComponentRegistry, ns *NodeSpec) error {
+	if ns.Key == "" {
+		return specErrorf(ns.line, "node key is empty")
+	}
+
+	var opts []GraphAddNodeOpt
+	if ns.Name != "" {
+		opts = append(opts, WithNodeName(ns.Name))
+	}
+	if ns.InputKey != "" {
+		opts = append(opts, WithInputKey(ns.InputKey))
+	}
+	if ns.OutputKey != "" {
+		opts = append(opts, WithOutputKey(ns.OutputKey))
+	}
+
+	if ns.Type == SpecNodeTypePassthrough {
+		if err := g.AddPassthroughNode(ns.Key, opts...); err != nil {
+			return &SpecError{Line: ns.line, Err: err}
+		}
+		return nil
+	}
+
+	factory, ok := registry.components[ns.Type]
+	if !ok {
+		return specErrorf(ns.line, "node '%s': unknown component type '%s'", ns.Key, ns.Type)
+	}
+
+	instance, err := factory(ctx, ns.Config)
+	if err != nil {
+		return specErrorf(ns.line, "node '%s': create component of type '%s' fail: %w", ns.Key, ns.Type, err)
+	}
+
+	switch c := instance.(type) {
+	case *Lambda:
+		err = g.AddLambdaNode(ns.Key, c, opts...)
+	case *ToolsNode:
+		err = g.AddToolsNode(ns.Key, c, opts...)
+	case AnyGraph:
+		err = g.AddGraphNode(ns.Key, c, opts...)
+	case model.BaseChatModel:
+		err = g.AddChatModelNode(ns.Key, c, opts...)
+	case prompt.ChatTemplate:
+		err = g.AddChatTemplateNode(ns.Key, c, opts...)
+	case retriever.Retriever:
+		err = g.AddRetrieverNode(ns.Key, c, opts...)
+	case embedding.Embedder:
+		err = g.AddEmbeddingNode(ns.Key, c, opts...)
+	case indexer.Indexer:
+		err = g.AddIndexerNode(ns.Key, c, opts...)
+	case document.Loader:
+		err = g.AddLoaderNode(ns.Key, c, opts...)
+	case document.Transformer:
+		err = g.AddDocumentTransformerNode(ns.Key, c, opts...)
+	default:
+		err = fmt.Errorf("node '%s': unsupported component %T created by type '%s'", ns.Key, instance, ns.Type)
+	}
+	if err != nil {
+		return &SpecError{Line: ns.line, Err: err}
+	}
+
+	return nil
+}
+
+func newBranchFromSpec(ctx context.Context, registry *ComponentRegistry, bs *BranchSpec) (*GraphBranch, error) {
+	factory, ok := registry.branches[bs.Type]
+	if !ok {
+		return nil, specErrorf(bs.line, "branch from '%s': unknown branch type '%s'", bs.From, bs.Type)
+	}
+	if len(bs.EndNodes) == 0 {
+		return nil, specErrorf(bs.line, "branch from '%s': end nodes are empty", bs.From)
+	}
+
+	endNodes := make(map[string]bool, len(bs.EndNodes))
+	for _, endNode := range bs.EndNodes {
+		endNodes[endNode] = true
+	}
+
+	branch, err := factory(ctx, bs.Config, endNodes)
+	if err != nil {
+		return nil, specErrorf(bs.line, "branch from '%s': create branch of type '%s' fail: %w", bs.From, bs.Type, err)
+	}
+	if branch == nil {
+		return nil, specErrorf(bs.line, "branch from '%s': branch of type '%s' is nil", bs.From, bs.Type)
+	}
+
+	return branch, nil
+}
+
+func mappingsFromSpec(specs []*MappingSpec) []*FieldMapping {
+	mappings := make([]*FieldMapping, 0, len(specs))
+	for _, m := range specs {
+		mappings = append(mappings, MapFieldPaths(splitSpecFieldPath(m.From), splitSpecFieldPath(m.To)))
+	}
+	return mappings
+}
+
+func splitSpecFieldPath(path string) FieldPath {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSpecRegistry(t *testing.T) *ComponentRegistry {
+	r := NewComponentRegistry()
+	assert.NoError(t, r.RegisterComponent("suffix", func(ctx context.Context, config *SpecConfig) (any, error) {
+		var conf struct {
+			Suffix string `yaml:"suffix"`
+		}
+		if err := config.Decode(&conf); err != nil {
+			return nil, err
+		}
+		return InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input + conf.Suffix, nil
+		}), nil
+	}))
+	assert.NoError(t, r.RegisterComponent("length", func(ctx context.Context, config *SpecConfig) (any, error) {
+		return InvokableLambda(func(ctx context.Context, input string) (int, error) {
+			return len(input), nil
+		}), nil
+	}))
+	assert.NoError(t, r.RegisterBranch("by_prefix", func(ctx context.Context, config *SpecConfig, endNodes map[string]bool) (*GraphBranch, error) {
+		var conf struct {
+			Routes map[string]string `yaml:"routes"`
+		}
+		if err := config.Decode(&conf); err != nil {
+			return nil, err
+		}
+		return NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+			for prefix, to := range conf.Routes {
+				if strings.HasPrefix(in, prefix) {
+					return to, nil
+				}
+			}
+			return END, nil
+		}, endNodes), nil
+	}))
+	return r
+}
+
+func TestGraphSpec(t *testing.T) {
+	ctx := context.Background()
+	registry := newTestSpecRegistry(t)
+
+	t.Run("graph", func(t *testing.T) {
+		doc := `
+name: spec_graph
+nodes:
+  - key: a
+    type: suffix
+    config:
+      suffix: _a
+  - key: b
+    type: suffix
+    config:
+      suffix: _b
+  - key: p
+    type: passthrough
+edges:
+  - from: start
+    to: p
+  - from: a
+    to: end
+  - from: b
+    to: end
+branches:
+  - from: p
+    type: by_prefix
+    end_nodes: [a, b]
+    config:
+      routes:
+        x: a
+        y: b
+compile:
+  max_run_steps: 10
+`
+		r, err := CompileGraphSpec[string, string](ctx, []byte(doc), registry)
+		assert.NoError(t, err)
+		out, err := r.Invoke(ctx, "x")
+		assert.NoError(t, err)
+		assert.Equal(t, "x_a", out)
+		out, err = r.Invoke(ctx, "y")
+		assert.NoError(t, err)
+		assert.Equal(t, "y_b", out)
+	})
+
+	t.Run("workflow in json", func(t *testing.T) {
+		doc := `{
+  "type": "workflow",
+  "nodes": [
+    {"key": "s", "type": "suffix", "config": {"suffix": "!"}},
+    {"key": "l", "type": "length"}
+  ],
+  "edges": [
+    {"from": "start", "to": "s", "mappings": [{"from": "text"}]},
+    {"from": "s", "to": "l"},
+    {"from": "s", "to": "end", "mappings": [{"to": "text"}]},
+    {"from": "l", "to": "end", "mappings": [{"to": "length"}]}
+  ]
+}`
+		r, err := CompileGraphSpec[map[string]any, map[string]any](ctx, []byte(doc), registry)
+		assert.NoError(t, err)
+		out, err := r.Invoke(ctx, map[string]any{"text": "hi"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"text": "hi!", "length": 3}, out)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := ParseGraphSpec([]byte("nodes:\n  - key: a\n    kind: suffix\n"))
+		assert.ErrorContains(t, err, "line 3")
+	})
+
+	t.Run("unknown component type", func(t *testing.T) {
+		doc := `
+nodes:
+  - key: a
+    type: suffix
+  - key: b
+    type: unknown
+`
+		_, err := CompileGraphSpec[string, string](ctx, []byte(doc), registry)
+		var specErr *SpecError
+		assert.True(t, errors.As(err, &specErr))
+		assert.Equal(t, 5, specErr.Line)
+		assert.ErrorContains(t, err, "unknown component type 'unknown'")
+	})
+
+	t.Run("graph type mismatch", func(t *testing.T) {
+		doc := `
+nodes:
+  - key: l
+    type: length
+  - key: s
+    type: suffix
+edges:
+  - from: start
+    to: l
+  - from: l
+    to: s
+`
+		_, err := CompileGraphSpec[string, string](ctx, []byte(doc), registry)
+		var specErr *SpecError
+		assert.True(t, errors.As(err, &specErr))
+		assert.Equal(t, 10, specErr.Line)
+	})
+
+	t.Run("workflow mapping mismatch", func(t *testing.T) {
+		doc := `
+type: workflow
+nodes:
+  - key: s
+    type: suffix
+edges:
+  - from: start
+    to: s
+    mappings:
+      - from: text
+  - from: s
+    to: end
+    mappings:
+      - from: missing
+        to: text
+`
+		_, err := CompileGraphSpec[map[string]any, map[string]any](ctx, []byte(doc), registry)
+		var specErr *SpecError
+		assert.True(t, errors.As(err, &specErr))
+		assert.Equal(t, 11, specErr.Line)
+	})
+
+	t.Run("workflow branch to unknown node", func(t *testing.T) {
+		doc := `
+type: workflow
+nodes:
+  - key: s
+    type: suffix
+edges:
+  - from: start
+    to: s
+  - from: s
+    to: end
+branches:
+  - from: s
+    type: by_prefix
+    end_nodes: [s, x]
+`
+		_, err := CompileGraphSpec[string, string](ctx, []byte(doc), registry)
+		var specErr *SpecError
+		assert.True(t, errors.As(err, &specErr))
+		assert.Equal(t, 12, specErr.Line)
+	})
+
+	t.Run("invalid node timeout", func(t *testing.T) {
+		doc := `
+nodes:
+  - key: s
+    type: suffix
+compile:
+  node_timeouts:
+    s: soon
+`
+		_, err := CompileGraphSpec[string, string](ctx, []byte(doc), registry)
+		assert.ErrorContains(t, err, "invalid timeout of node 's'")
+	})
+}
@@ -18,13 +18,16 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -2069,3 +2072,141 @@ func TestUniqueSlice(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c"}, uniqueSlice([]string{"a", "b", "a", "c", "b"}))
 	assert.Equal(t, []string{}, uniqueSlice([]string{}))
 }
+
+func TestNodeTimeout(t *testing.T) {
+	ctx := context.Background()
+	blocking := InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	ignoring := InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		time.Sleep(300 * time.Millisecond)
+		return input, nil
+	})
+
+	subG := NewGraph[string, string]()
+	assert.NoError(t, subG.AddLambdaNode("retriever", blocking))
+	assert.NoError(t, subG.AddEdge(START, "retriever"))
+	assert.NoError(t, subG.AddEdge("retriever", END))
+
+	g := NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("slow", ignoring))
+	assert.NoError(t, g.AddGraphNode("sub", subG))
+	assert.NoError(t, g.AddEdge(START, "slow"))
+	assert.NoError(t, g.AddEdge("slow", "sub"))
+	assert.NoError(t, g.AddEdge("sub", END))
+
+	_, err := g.Compile(ctx, WithNodeTimeouts(map[string]time.Duration{"unknown": time.Second}))
+	assert.ErrorContains(t, err, "unknown node: unknown")
+
+	r, err := g.Compile(ctx, WithNodeTimeouts(map[string]time.Duration{"slow": 50 * time.Millisecond}))
+	assert.NoError(t, err)
+
+	// node ignoring its context is still bounded
+	start := time.Now()
+	_, err = r.Invoke(ctx, "input")
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+	var te *NodeTimeoutError
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, []string{"slow"}, te.NodePath.GetPath())
+	assert.Equal(t, 50*time.Millisecond, te.Timeout)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// override per call, designate node in subgraph
+	_, err = r.Invoke(ctx, "input",
+		WithNodeTimeout(0).DesignateNode("slow"),
+		WithNodeTimeout(20*time.Millisecond).DesignateNodeWithPath(NewNodePath("sub", "retriever")))
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, []string{"sub", "retriever"}, te.NodePath.GetPath())
+	assert.Equal(t, 20*time.Millisecond, te.Timeout)
+}
+
+func TestNodeTimeoutStream(t *testing.T) {
+	ctx := context.Background()
+	g := NewGraph[string, string]()
+	var nodeCtx context.Context
+	assert.NoError(t, g.AddLambdaNode("1", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+		nodeCtx = ctx
+		sr, sw := schema.Pipe[string](0)
+		go func() {
+			defer sw.Close()
+			for i := 0; i < 3; i++ {
+				sw.Send(strconv.Itoa(i), nil)
+			}
+		}()
+		return sr, nil
+	})))
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", END))
+
+	r, err := g.Compile(ctx, WithNodeTimeouts(map[string]time.Duration{"1": time.Hour}))
+	assert.NoError(t, err)
+	sr, err := r.Stream(ctx, "input")
+	assert.NoError(t, err)
+	out, err := concatStreamReader(sr)
+	assert.NoError(t, err)
+	assert.Equal(t, "012", out)
+	select {
+	case <-nodeCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context of the node isn't canceled after its output stream ends")
+	}
+
+	t.Run("timeout while streaming", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+			sr, sw := schema.Pipe[string](0)
+			go func() {
+				defer sw.Close()
+				sw.Send("0", nil)
+				<-ctx.Done()
+				sw.Send("", ctx.Err())
+			}()
+			return sr, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		r, err := g.Compile(ctx, WithNodeTimeouts(map[string]time.Duration{"1": 50 * time.Millisecond}))
+		assert.NoError(t, err)
+		sr, err := r.Stream(ctx, "input")
+		assert.NoError(t, err)
+		_, err = concatStreamReader(sr)
+		var te *NodeTimeoutError
+		assert.True(t, errors.As(err, &te), err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestDeadlineSplit(t *testing.T) {
+	var mu sync.Mutex
+	budgets := map[string]time.Duration{}
+	record := func(key string) *Lambda {
+		return InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			mu.Lock()
+			budgets[key] = time.Until(deadline)
+			mu.Unlock()
+			return input, nil
+		})
+	}
+
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("a", record("a")).AddInput(START)
+	wf.AddLambdaNode("b", record("b")).AddInput("a")
+	wf.AddLambdaNode("c", record("c")).AddInput("b")
+	wf.End().AddInput("c")
+
+	r, err := wf.Compile(context.Background(), WithDeadlineSplit())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err = r.Invoke(ctx, "input")
+	assert.NoError(t, err)
+
+	assert.LessOrEqual(t, budgets["a"], time.Second)
+	assert.Greater(t, budgets["a"], 900*time.Millisecond)
+	assert.Greater(t, budgets["c"], 2*time.Second)
+}
@@ -43,6 +43,8 @@ type GraphInfo struct {
 	Nodes                 map[string]GraphNodeInfo // node key -> node info
 	Edges                 map[string][]string      // edge start node key -> edge end node key, control edges
 	DataEdges             map[string][]string
+	ErrorEdges            map[string]string        // failing node key -> error handler node key, see AddErrorEdge
+	ErrorBranches         map[string]GraphBranch   // failing node key -> error branch, see AddErrorBranch
 	Branches              map[string][]GraphBranch // branch start node key -> branch
 	InputType, OutputType reflect.Type
 	Name                  string
@@ -0,0 +1,369 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiagnosticSeverity is the severity of a Diagnostic.
+type DiagnosticSeverity string
+
+const (
+	// DiagnosticError means the graph fails to compile, or fails whenever the problem is hit at runtime.
+	DiagnosticError DiagnosticSeverity = "error"
+	// DiagnosticWarning means the graph may fail or behave unexpectedly at runtime.
+	DiagnosticWarning DiagnosticSeverity = "warning"
+)
+
+// DiagnosticCode identifies the kind of problem reported by a Diagnostic.
+type DiagnosticCode string
+
+const (
+	// DiagnosticBuildError is an error returned when building the graph, e.g. by AddEdge, other than the ones of the codes below.
+	DiagnosticBuildError DiagnosticCode = "build_error"
+	// DiagnosticStateNotEnabled is a node with state handlers added to a graph without WithGenLocalState.
+	DiagnosticStateNotEnabled DiagnosticCode = "state_not_enabled"
+	// DiagnosticTypeMismatch is an edge, branch or field mapping whose types are never assignable.
+	DiagnosticTypeMismatch DiagnosticCode = "type_mismatch"
+	// DiagnosticUninferredType is an edge between passthrough nodes whose types can't be inferred.
+	DiagnosticUninferredType DiagnosticCode = "uninferred_type"
+	// DiagnosticRuntimeTypeCheck is an edge, branch or field mapping whose types can only be checked at runtime,
+	// e.g. from an interface to a concrete type, which fails at runtime if the actual value isn't assignable.
+	DiagnosticRuntimeTypeCheck DiagnosticCode = "runtime_type_check"
+	// DiagnosticMissingStartOrEnd is a graph without any edge from START or to END.
+	DiagnosticMissingStartOrEnd DiagnosticCode = "missing_start_or_end"
+	// DiagnosticUnreachableNode is a node which can't be reached from START.
+	DiagnosticUnreachableNode DiagnosticCode = "unreachable_node"
+	// DiagnosticUnreachableBranch is a branch whose start node can't be reached from START, so its end nodes are never reached through it.
+	DiagnosticUnreachableBranch DiagnosticCode = "unreachable_branch"
+	// DiagnosticDeadEnd is a node reachable from START which can't reach END.
+	DiagnosticDeadEnd DiagnosticCode = "dead_end"
+	// DiagnosticUnboundedLoop is a loop in a graph compiled without WithMaxRunSteps, bounded only by the default max run steps.
+	DiagnosticUnboundedLoop DiagnosticCode = "unbounded_loop"
+	// DiagnosticLoopInDAG is a loop in a graph running in AllPredecessor mode, e.g. a Workflow.
+	DiagnosticLoopInDAG DiagnosticCode = "loop_in_dag"
+	// DiagnosticUnusedFieldMapping is a field mapping that never takes effect, e.g. its end node is unreachable.
+	DiagnosticUnusedFieldMapping DiagnosticCode = "unused_field_mapping"
+	// DiagnosticFieldMappingConflict is a field mapped more than once to the same node.
+	DiagnosticFieldMappingConflict DiagnosticCode = "field_mapping_conflict"
+)
+
+// Diagnostic is a problem of a graph found by Lint.
+type Diagnostic struct {
+	Severity DiagnosticSeverity
+	Code     DiagnosticCode
+	// GraphPath is the path of the subgraph node from the root graph, empty for the root graph.
+	GraphPath []string
+	// Nodes are the keys of the nodes involved in the graph of GraphPath, e.g. the start and end node of an edge.
+	Nodes   []string
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("[%s] %s", d.Severity, d.Code))
+	if len(d.GraphPath) > 0 {
+		sb.WriteString(fmt.Sprintf(" in subgraph %s", strings.Join(d.GraphPath, "/")))
+	}
+	sb.WriteString(": ")
+	sb.WriteString(d.Message)
+	return sb.String()
+}
+
+// diagnosticError is an error returned when building a graph, which Lint reports as a diagnostic of its code.
+type diagnosticError struct {
+	code  DiagnosticCode
+	nodes []string
+	err   error
+}
+
+func newDiagnosticError(code DiagnosticCode, nodes []string, err error) error {
+	return &diagnosticError{code: code, nodes: nodes, err: err}
+}
+
+func (e *diagnosticError) Error() string {
+	return e.err.Error()
+}
+
+func (e *diagnosticError) Unwrap() error {
+	return e.err
+}
+
+func (g *graph) addRuntimeTypeCheck(nodes []string, format string, a ...any) {
+	g.runtimeTypeChecks = append(g.runtimeTypeChecks, &Diagnostic{
+		Severity: DiagnosticWarning,
+		Code:     DiagnosticRuntimeTypeCheck,
+		Nodes:    nodes,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// lintable is implemented by Graph, Chain and Workflow.
+type lintable interface {
+	// graphToLint returns the underlying graph, adding the edges declared by Chain or Workflow if not yet.
+	graphToLint() (*graph, error)
+}
+
+func (g *graph) graphToLint() (*graph, error) {
+	return g, g.buildError
+}
+
+func (c *Chain[I, O]) graphToLint() (*graph, error) {
+	if err := c.addEndIfNeeded(); err != nil {
+		return nil, err
+	}
+	return c.gg.graphToLint()
+}
+
+func (wf *Workflow[I, O]) graphToLint() (*graph, error) {
+	if err := wf.build(); err != nil {
+		return nil, err
+	}
+	return wf.g, nil
+}
+
+// Lint inspects the graph and its subgraphs before compilation, and returns the problems found,
+// which is empty if none found. opts should be the ones to compile the graph with.
+// Problems that fail the compilation are reported with DiagnosticError, and the ones that may fail at runtime with DiagnosticWarning.
+// The graph can still be compiled after Lint, so Lint can be run in unit tests against the graphs to be compiled, e.g.
+//
+//	for _, d := range compose.Lint(g, compose.WithMaxRunSteps(20)) {
+//		if d.Severity == compose.DiagnosticError {
+//			t.Error(d)
+//		}
+//	}
+//
+// As the graph isn't modifiable once it fails to build, only the first error returned when building the graph is reported.
+func Lint(g AnyGraph, opts ...GraphCompileOption) []*Diagnostic {
+	diagnostics := lintGraph(g, newGraphCompileOptions(opts...), nil)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		pi, pj := strings.Join(diagnostics[i].GraphPath, "/"), strings.Join(diagnostics[j].GraphPath, "/")
+		if pi != pj {
+			return pi < pj
+		}
+		return diagnostics[i].Severity == DiagnosticError && diagnostics[j].Severity != DiagnosticError
+	})
+	return diagnostics
+}
+
+func lintGraph(ag AnyGraph, opt *graphCompileOptions, graphPath []string) []*Diagnostic {
+	l, ok := ag.(lintable)
+	if !ok {
+		return nil
+	}
+	g, err := l.graphToLint()
+	if err != nil {
+		d := &Diagnostic{Severity: DiagnosticError, Code: DiagnosticBuildError, Message: err.Error()}
+		var dErr *diagnosticError
+		if errors.As(err, &dErr) {
+			d.Code, d.Nodes = dErr.code, dErr.nodes
+		}
+		return []*Diagnostic{withGraphPath(d, graphPath)}
+	}
+
+	ln := &graphLinter{g: g, opt: opt}
+	ln.lint()
+
+	for _, key := range sortedKeys(g.nodes) {
+		node := g.nodes[key]
+		if node.g == nil {
+			continue
+		}
+		subOpt := node.nodeInfo.compileOption
+		if subOpt == nil {
+			subOpt = newGraphCompileOptions()
+		}
+		ln.diagnostics = append(ln.diagnostics, lintGraph(node.g, subOpt, append(append([]string{}, graphPath...), key))...)
+	}
+
+	for _, d := range ln.diagnostics {
+		withGraphPath(d, graphPath)
+	}
+	return ln.diagnostics
+}
+
+func withGraphPath(d *Diagnostic, graphPath []string) *Diagnostic {
+	if len(d.GraphPath) == 0 && len(graphPath) > 0 {
+		d.GraphPath = graphPath
+	}
+	return d
+}
+
+type graphLinter struct {
+	g           *graph
+	opt         *graphCompileOptions
+	diagnostics []*Diagnostic
+}
+
+func (l *graphLinter) report(severity DiagnosticSeverity, code DiagnosticCode, nodes []string, format string, a ...any) {
+	l.diagnostics = append(l.diagnostics, &Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Nodes:    nodes,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (l *graphLinter) lint() {
+	g := l.g
+
+	for _, start := range sortedKeys(g.toValidateMap) {
+		for _, end := range g.toValidateMap[start] {
+			l.report(DiagnosticError, DiagnosticUninferredType, []string{start, end.endNode},
+				"graph edge[%s]-[%s]: types of the passthrough nodes cannot be inferred", start, end.endNode)
+		}
+	}
+	for _, d := range g.runtimeTypeChecks {
+		cp := *d
+		l.diagnostics = append(l.diagnostics, &cp)
+	}
+
+	if len(g.startNodes) == 0 || len(g.endNodes) == 0 {
+		l.report(DiagnosticError, DiagnosticMissingStartOrEnd, nil, "start node or end node not set")
+		return
+	}
+
+	successors := l.controlSuccessors()
+	reachable := reachableFrom(START, successors)
+	predecessors := make(map[string][]string)
+	for from, tos := range successors {
+		for _, to := range tos {
+			predecessors[to] = append(predecessors[to], from)
+		}
+	}
+	reachEnd := reachableFrom(END, predecessors)
+
+	for _, key := range sortedKeys(g.nodes) {
+		if !reachable[key] {
+			l.report(DiagnosticWarning, DiagnosticUnreachableNode, []string{key}, "node[%s] can't be reached from START", key)
+		} else if !reachEnd[key] {
+			l.report(DiagnosticWarning, DiagnosticDeadEnd, []string{key}, "node[%s] can't reach END", key)
+		}
+	}
+	for _, start := range sortedKeys(g.branches) {
+		if start == START || reachable[start] {
+			continue
+		}
+		for _, b := range g.branches[start] {
+			ends := sortedKeys(b.endNodes)
+			l.report(DiagnosticWarning, DiagnosticUnreachableBranch, append([]string{start}, ends...),
+				"branch of node[%s] is never run, its end nodes %v are never reached through it", start, ends)
+		}
+	}
+
+	l.lintLoops()
+	l.lintFieldMappings(reachable)
+}
+
+// controlSuccessors returns the nodes triggered by each node, the same as the ones by which findLoops searches loops.
+func (l *graphLinter) controlSuccessors() map[string][]string {
+	g := l.g
+	successors := make(map[string][]string)
+	for from, tos := range g.controlEdges {
+		successors[from] = append(successors[from], tos...)
+	}
+	for from, b := range g.errorRoutes {
+		for to := range b.endNodes {
+			successors[from] = append(successors[from], to)
+		}
+	}
+	for from, branches := range g.branches {
+		for _, b := range branches {
+			for to := range b.endNodes {
+				successors[from] = append(successors[from], to)
+			}
+		}
+	}
+	return successors
+}
+
+func reachableFrom(from string, successors map[string][]string) map[string]bool {
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[node] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
+
+func (l *graphLinter) lintLoops() {
+	g := l.g
+	chanCalls := make(map[string]*chanCall, len(g.nodes))
+	for key := range g.nodes {
+		chanCalls[key] = &chanCall{
+			controls:        g.controlEdges[key],
+			errorTo:         g.errorRoutes[key],
+			writeToBranches: g.branches[key],
+		}
+	}
+	startNodes := uniqueSlice(append([]string{}, g.startNodes...))
+	sort.Strings(startNodes)
+
+	dag := isWorkflow(g.cmp) || l.opt.nodeTriggerMode == AllPredecessor
+	if !dag && l.opt.maxRunSteps > 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, loop := range findLoops(startNodes, chanCalls) {
+		nodes := uniqueSlice(append([]string{}, loop...))
+		sorted := append([]string{}, nodes...)
+		sort.Strings(sorted)
+		key := strings.Join(sorted, "\x1F")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if dag {
+			l.report(DiagnosticError, DiagnosticLoopInDAG, nodes, "loop %s is not allowed in AllPredecessor mode", formatLoops([][]string{loop}))
+		} else {
+			l.report(DiagnosticWarning, DiagnosticUnboundedLoop, nodes,
+				"loop %s is bounded only by the default max run steps[%d], set WithMaxRunSteps to bound it", formatLoops([][]string{loop}), len(g.nodes)+10)
+		}
+	}
+}
+
+func (l *graphLinter) lintFieldMappings(reachable map[string]bool) {
+	g := l.g
+	for _, key := range sortedKeys(g.fieldMappingRecords) {
+		targets := make(map[string]bool)
+		for _, mapping := range g.fieldMappingRecords[key] {
+			if targets[mapping.to] {
+				l.report(DiagnosticError, DiagnosticFieldMappingConflict, []string{key},
+					"duplicate mapping target field: %s of node[%s]", mapping.to, key)
+			}
+			targets[mapping.to] = true
+
+			if key != END && !reachable[key] {
+				l.report(DiagnosticWarning, DiagnosticUnusedFieldMapping, []string{mapping.fromNodeKey, key},
+					"mapping %s is never used since node[%s] can't be reached from START", mapping, key)
+			}
+		}
+	}
+}
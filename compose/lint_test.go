@@ -0,0 +1,182 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	ctx := context.Background()
+	strLambda := func() *Lambda {
+		return InvokableLambda(func(ctx context.Context, input string) (string, error) { return input, nil })
+	}
+	codes := func(diagnostics []*Diagnostic) []DiagnosticCode {
+		ret := make([]DiagnosticCode, 0, len(diagnostics))
+		for _, d := range diagnostics {
+			ret = append(ret, d.Code)
+		}
+		return ret
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", strLambda())
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", END)
+		assert.Empty(t, Lint(g))
+		_, err := g.Compile(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reachability", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", strLambda())
+		_ = g.AddLambdaNode("dead_end", strLambda())
+		_ = g.AddLambdaNode("orphan", strLambda())
+		_ = g.AddLambdaNode("b", strLambda())
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge(START, "dead_end")
+		_ = g.AddEdge("a", END)
+		_ = g.AddBranch("orphan", NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+			return "b", nil
+		}, map[string]bool{"b": true}))
+		_ = g.AddEdge("b", END)
+
+		diagnostics := Lint(g)
+		assert.Equal(t, []*Diagnostic{
+			{Severity: DiagnosticWarning, Code: DiagnosticUnreachableNode, Nodes: []string{"b"}, Message: "node[b] can't be reached from START"},
+			{Severity: DiagnosticWarning, Code: DiagnosticDeadEnd, Nodes: []string{"dead_end"}, Message: "node[dead_end] can't reach END"},
+			{Severity: DiagnosticWarning, Code: DiagnosticUnreachableNode, Nodes: []string{"orphan"}, Message: "node[orphan] can't be reached from START"},
+			{Severity: DiagnosticWarning, Code: DiagnosticUnreachableBranch, Nodes: []string{"orphan", "b"},
+				Message: "branch of node[orphan] is never run, its end nodes [b] are never reached through it"},
+		}, diagnostics)
+	})
+
+	t.Run("state not enabled", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", strLambda(), WithStatePreHandler(func(ctx context.Context, in string, state *testStruct) (string, error) {
+			return in, nil
+		}))
+		diagnostics := Lint(g)
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, DiagnosticError, diagnostics[0].Severity)
+			assert.Equal(t, DiagnosticStateNotEnabled, diagnostics[0].Code)
+			assert.Equal(t, []string{"a"}, diagnostics[0].Nodes)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", strLambda())
+		_ = g.AddLambdaNode("b", InvokableLambda(func(ctx context.Context, input int) (string, error) { return "", nil }))
+		_ = g.AddEdge("a", "b")
+		diagnostics := Lint(g)
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, DiagnosticTypeMismatch, diagnostics[0].Code)
+			assert.Equal(t, []string{"a", "b"}, diagnostics[0].Nodes)
+		}
+	})
+
+	t.Run("runtime type check", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, input string) (any, error) { return input, nil }))
+		_ = g.AddLambdaNode("b", strLambda())
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", "b")
+		_ = g.AddEdge("b", END)
+		diagnostics := Lint(g)
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, DiagnosticWarning, diagnostics[0].Severity)
+			assert.Equal(t, DiagnosticRuntimeTypeCheck, diagnostics[0].Code)
+			assert.Equal(t, []string{"a", "b"}, diagnostics[0].Nodes)
+		}
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", strLambda())
+		_ = g.AddLambdaNode("b", strLambda())
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", "b")
+		_ = g.AddBranch("b", NewGraphBranch(func(ctx context.Context, in string) (string, error) {
+			return END, nil
+		}, map[string]bool{"a": true, END: true}))
+
+		diagnostics := Lint(g)
+		assert.Equal(t, []DiagnosticCode{DiagnosticUnboundedLoop}, codes(diagnostics))
+		assert.Equal(t, []string{"a", "b"}, diagnostics[0].Nodes)
+		assert.Empty(t, Lint(g, WithMaxRunSteps(5)))
+		assert.Equal(t, []DiagnosticCode{DiagnosticLoopInDAG}, codes(Lint(g, WithNodeTriggerMode(AllPredecessor))))
+	})
+
+	t.Run("subgraph", func(t *testing.T) {
+		sub := NewGraph[string, string]()
+		_ = sub.AddLambdaNode("a", strLambda())
+		_ = sub.AddLambdaNode("orphan", strLambda())
+		_ = sub.AddEdge(START, "a")
+		_ = sub.AddEdge("a", END)
+
+		g := NewGraph[string, string]()
+		_ = g.AddGraphNode("sub", sub)
+		_ = g.AddEdge(START, "sub")
+		_ = g.AddEdge("sub", END)
+		diagnostics := Lint(g)
+		if assert.Len(t, diagnostics, 1) {
+			assert.Equal(t, []string{"sub"}, diagnostics[0].GraphPath)
+			assert.Equal(t, []string{"orphan"}, diagnostics[0].Nodes)
+			assert.Equal(t, "[warning] unreachable_node in subgraph sub: node[orphan] can't be reached from START", diagnostics[0].String())
+		}
+	})
+
+	t.Run("workflow", func(t *testing.T) {
+		type in struct {
+			A string
+			B string
+		}
+		wf := NewWorkflow[in, string]()
+		wf.AddLambdaNode("a", strLambda()).AddInput(START, FromField("A"))
+		wf.AddLambdaNode("orphan", strLambda())
+		wf.AddLambdaNode("b", InvokableLambda(func(ctx context.Context, input map[string]any) (string, error) {
+			return "", nil
+		})).AddInput("orphan", ToField("x"))
+		wf.End().AddInput("a")
+
+		diagnostics := Lint(wf)
+		assert.ElementsMatch(t, []DiagnosticCode{
+			DiagnosticUnreachableNode, DiagnosticUnreachableNode, DiagnosticUnusedFieldMapping,
+		}, codes(diagnostics))
+
+		// lint doesn't affect the compilation
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+		out, err := r.Invoke(ctx, in{A: "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, "x", out)
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		c := NewChain[string, string]()
+		c.AppendLambda(strLambda())
+		assert.Empty(t, Lint(c))
+		_, err := c.Compile(ctx)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/internal/safe"
+	"github.com/cloudwego/eino/schema"
+)
+
+// AddressSegmentMapElement represents a segment of an address that corresponds to an element processed by a map node,
+// the ID of the segment is the index of the element, see NewMapLambda.
+const AddressSegmentMapElement AddressSegmentType = "map_element"
+
+// MapErrorPolicy decides how a map node handles the elements that fail.
+type MapErrorPolicy int
+
+const (
+	// MapFailFast cancels the elements still running on the first failure, and the node fails with a *MapElementError.
+	MapFailFast MapErrorPolicy = iota
+	// MapSkipErrors drops the failed elements from the output, the other results stay in input order.
+	MapSkipErrors
+	// MapCollectErrors waits for every element to finish, and the node fails with a *MapError listing all failed elements.
+	MapCollectErrors
+)
+
+// MapConfig is the config of a map node, see NewMapLambda.
+type MapConfig struct {
+	// MaxConcurrency is the max number of elements processed at the same time, 0 means unlimited.
+	MaxConcurrency int
+	// ErrorPolicy decides how failed elements are handled, MapFailFast by default.
+	ErrorPolicy MapErrorPolicy
+}
+
+// MapElementError is the error of an element processed by a map node.
+type MapElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *MapElementError) Error() string {
+	return fmt.Sprintf("map element[%d] failed: %v", e.Index, e.Err)
+}
+
+func (e *MapElementError) Unwrap() error {
+	return e.Err
+}
+
+// newMapElementError drops the node path of the runnable's own nodes from err,
+// so that the *MapElementError is kept when the graph running the map node adds its node path.
+func newMapElementError(index int, err error) *MapElementError {
+	for {
+		ie, ok := err.(*internalError)
+		if !ok {
+			break
+		}
+		err = ie.origError
+	}
+	return &MapElementError{Index: index, Err: err}
+}
+
+// MapError is returned by a map node using MapCollectErrors, Errors are ordered by the index of the elements.
+type MapError struct {
+	Errors []*MapElementError
+}
+
+func (e *MapError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%d map elements failed", len(e.Errors)))
+	for _, err := range e.Errors {
+		sb.WriteString("\n")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func init() {
+	schema.RegisterName[*mapInterruptState]("_eino_compose_map_interrupt_state")
+}
+
+// mapInterruptState is saved when elements of a map node are interrupted,
+// so that on resume only the interrupted elements are processed again.
+type mapInterruptState struct {
+	Input any
+	// Results are the outputs of the finished elements, keyed by the index of the elements.
+	Results map[string]any
+	// Errors are the errors of the failed elements kept by MapSkipErrors and MapCollectErrors.
+	Errors map[string]string
+}
+
+type mapRunner[T, R any] struct {
+	r      Runnable[T, R]
+	config MapConfig
+}
+
+// NewMapLambda creates a Lambda that takes a slice and runs r on each element of it,
+// e.g. a compiled Graph processing a retrieved document, the outputs are collected in input order.
+// The Lambda accepts Option as its lambda option, which is passed to every run of r, see WithLambdaOption.
+//
+// Each element runs with its own address segment of type AddressSegmentMapElement,
+// so when elements are interrupted, e.g. by an interrupt node of the Graph, the node interrupts as a composite node.
+// On resume, the finished elements are not run again, only the interrupted elements are.
+//
+// e.g.
+//
+//	summarize, err := summarizeGraph.Compile(ctx) // Runnable[*schema.Document, string]
+//	mapNode := compose.NewMapLambda(summarize, &compose.MapConfig{MaxConcurrency: 5})
+//	err = graph.AddLambdaNode("summarize_all", mapNode) // []*schema.Document -> []string
+func NewMapLambda[T, R any](r Runnable[T, R], config *MapConfig, opts ...LambdaOpt) *Lambda {
+	m := &mapRunner[T, R]{r: r}
+	if config != nil {
+		m.config = *config
+	}
+	return InvokableLambdaWithOption(m.invoke, opts...)
+}
+
+func (m *mapRunner[T, R]) invoke(ctx context.Context, input []T, opts ...Option) ([]R, error) {
+	n := len(input)
+	results := make([]R, n)
+	done := make([]bool, n)
+	errs := make([]error, n)
+
+	if wasInterrupted, hasState, state := GetInterruptState[*mapInterruptState](ctx); wasInterrupted && hasState {
+		if in, ok := state.Input.([]T); ok {
+			input = in
+			n = len(input)
+			results, done, errs = make([]R, n), make([]bool, n), make([]error, n)
+		}
+		for k, v := range state.Results {
+			i, err := strconv.Atoi(k)
+			if err != nil || i >= n {
+				continue
+			}
+			if r, ok := v.(R); ok || v == nil {
+				results[i], done[i] = r, true
+			}
+		}
+		for k, msg := range state.Errors {
+			if i, err := strconv.Atoi(k); err == nil && i < n {
+				errs[i] = errors.New(msg)
+			}
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem chan struct{}
+	if m.config.MaxConcurrency > 0 {
+		sem = make(chan struct{}, m.config.MaxConcurrency)
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr *MapElementError
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		if done[i] || errs[i] != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					errs[i] = safe.NewPanicErr(p, debug.Stack())
+				}
+			}()
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-runCtx.Done():
+					errs[i] = runCtx.Err()
+					return
+				}
+			}
+
+			out, err := m.r.Invoke(AppendAddressSegment(runCtx, AddressSegmentMapElement, strconv.Itoa(i)), input[i], opts...)
+			if err != nil {
+				errs[i] = err
+				if m.config.ErrorPolicy == MapFailFast && !isInterruptError(err) {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = newMapElementError(i, err)
+						cancel()
+					}
+					mu.Unlock()
+				}
+				return
+			}
+			results[i], done[i] = out, true
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var (
+		interruptErrs []error
+		failed        []*MapElementError
+	)
+	for i := 0; i < n; i++ {
+		if errs[i] == nil {
+			continue
+		}
+		if !isInterruptError(errs[i]) {
+			if m.config.ErrorPolicy == MapFailFast {
+				// e.g. a recovered panic, which doesn't cancel the other elements
+				return nil, newMapElementError(i, errs[i])
+			}
+			failed = append(failed, newMapElementError(i, errs[i]))
+			continue
+		}
+
+		if _, ok := ExtractInterruptInfo(errs[i]); ok {
+			interruptErrs = append(interruptErrs, errs[i])
+		} else {
+			interruptErrs = append(interruptErrs, WrapInterruptAndRerunIfNeeded(ctx,
+				AddressSegment{ID: strconv.Itoa(i), Type: AddressSegmentMapElement}, errs[i]))
+		}
+	}
+
+	if len(interruptErrs) > 0 {
+		state := &mapInterruptState{
+			Input:   input,
+			Results: make(map[string]any),
+			Errors:  make(map[string]string),
+		}
+		for i := 0; i < n; i++ {
+			if done[i] {
+				state.Results[strconv.Itoa(i)] = results[i]
+			}
+		}
+		for _, f := range failed {
+			state.Errors[strconv.Itoa(f.Index)] = f.Err.Error()
+		}
+		return nil, CompositeInterrupt(ctx, nil, state, interruptErrs...)
+	}
+
+	if len(failed) == 0 {
+		return results, nil
+	}
+
+	if m.config.ErrorPolicy == MapCollectErrors {
+		return nil, &MapError{Errors: failed}
+	}
+
+	output := make([]R, 0, n-len(failed))
+	for i := 0; i < n; i++ {
+		if done[i] {
+			output = append(output, results[i])
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMapTestGraph(t *testing.T, elem Runnable[int, string], config *MapConfig, opts ...GraphCompileOption) Runnable[[]int, []string] {
+	g := NewGraph[[]int, []string]()
+	assert.NoError(t, g.AddLambdaNode("map", NewMapLambda(elem, config)))
+	assert.NoError(t, g.AddEdge(START, "map"))
+	assert.NoError(t, g.AddEdge("map", END))
+	r, err := g.Compile(context.Background(), opts...)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestMapLambda(t *testing.T) {
+	ctx := context.Background()
+	errOdd := errors.New("odd")
+
+	failOdd, err := NewChain[int, string]().AppendLambda(InvokableLambda(func(ctx context.Context, in int) (string, error) {
+		if in%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(in), nil
+	})).Compile(ctx)
+	assert.NoError(t, err)
+
+	t.Run("ordered with bounded concurrency", func(t *testing.T) {
+		var running, maxRunning int32
+		elem, err := NewChain[int, string]().AppendLambda(InvokableLambda(func(ctx context.Context, in int) (string, error) {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if cur <= m || atomic.CompareAndSwapInt32(&maxRunning, m, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(10-in) * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return strconv.Itoa(in), nil
+		})).Compile(ctx)
+		assert.NoError(t, err)
+
+		out, err := newMapTestGraph(t, elem, &MapConfig{MaxConcurrency: 2}).Invoke(ctx, []int{0, 1, 2, 3, 4, 5})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "1", "2", "3", "4", "5"}, out)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+
+		out, err = newMapTestGraph(t, elem, nil).Invoke(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		_, err := newMapTestGraph(t, failOdd, &MapConfig{MaxConcurrency: 1}).Invoke(ctx, []int{0, 1, 2, 3})
+		var elemErr *MapElementError
+		assert.True(t, errors.As(err, &elemErr))
+		assert.Equal(t, 1, elemErr.Index%2)
+		assert.True(t, errors.Is(err, errOdd))
+		// the node path is that of the map node, the nodes of the element runnable are dropped
+		var ie *internalError
+		assert.True(t, errors.As(err, &ie))
+		assert.Equal(t, []string{"map"}, ie.nodePath.path)
+		assert.Same(t, elemErr, ie.origError)
+	})
+
+	t.Run("skip errors", func(t *testing.T) {
+		out, err := newMapTestGraph(t, failOdd, &MapConfig{ErrorPolicy: MapSkipErrors}).Invoke(ctx, []int{0, 1, 2, 3, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "2", "4"}, out)
+	})
+
+	t.Run("collect errors", func(t *testing.T) {
+		_, err := newMapTestGraph(t, failOdd, &MapConfig{ErrorPolicy: MapCollectErrors}).Invoke(ctx, []int{0, 1, 2, 3})
+		var mapErr *MapError
+		assert.True(t, errors.As(err, &mapErr))
+		assert.Len(t, mapErr.Errors, 2)
+		assert.Equal(t, 1, mapErr.Errors[0].Index)
+		assert.Equal(t, 3, mapErr.Errors[1].Index)
+	})
+
+	t.Run("interrupt and resume per element", func(t *testing.T) {
+		var calls [4]int32
+		elemGraph := NewGraph[int, string]()
+		assert.NoError(t, elemGraph.AddLambdaNode("approve", InvokableLambda(func(ctx context.Context, in int) (string, error) {
+			atomic.AddInt32(&calls[in], 1)
+			if in%2 == 1 {
+				isResumeTarget, hasData, data := GetResumeContext[string](ctx)
+				if !isResumeTarget {
+					return "", Interrupt(ctx, fmt.Sprintf("approve %d", in))
+				}
+				if hasData {
+					return data, nil
+				}
+			}
+			return strconv.Itoa(in), nil
+		})))
+		assert.NoError(t, elemGraph.AddEdge(START, "approve"))
+		assert.NoError(t, elemGraph.AddEdge("approve", END))
+		elem, err := elemGraph.Compile(ctx)
+		assert.NoError(t, err)
+
+		r := newMapTestGraph(t, elem, &MapConfig{ErrorPolicy: MapSkipErrors}, WithCheckPointStore(newInMemoryStore()))
+		_, err = r.Invoke(ctx, []int{0, 1, 2, 3}, WithCheckPointID("map"))
+		info, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+
+		ids := make(map[string]string)
+		for _, ic := range info.InterruptContexts {
+			assert.True(t, ic.IsRootCause)
+			ids[ic.Info.(string)] = ic.ID
+		}
+		assert.Len(t, ids, 2)
+
+		// resume element 1 only, element 3 interrupts again
+		_, err = r.Invoke(ResumeWithData(ctx, ids["approve 1"], "approved 1"), nil, WithCheckPointID("map"))
+		info, ok = ExtractInterruptInfo(err)
+		assert.True(t, ok)
+		assert.Len(t, info.InterruptContexts, 1)
+		assert.Equal(t, "approve 3", info.InterruptContexts[0].Info)
+
+		out, err := r.Invoke(ResumeWithData(ctx, info.InterruptContexts[0].ID, "approved 3"), nil, WithCheckPointID("map"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "approved 1", "2", "approved 3"}, out)
+
+		// the finished elements are not run again
+		assert.Equal(t, [4]int32{1, 2, 1, 3}, calls)
+	})
+}
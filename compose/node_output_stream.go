@@ -0,0 +1,270 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/eino/internal"
+	"github.com/cloudwego/eino/schema"
+)
+
+// NodeOutputEvent is an event of the stream created by WithNodeOutputStream.
+type NodeOutputEvent struct {
+	// NodeKey is the key of the node within the graph it's added to.
+	NodeKey string
+	// NodePath is the path of the node from the root graph.
+	NodePath *NodePath
+
+	// IsChunk reports whether Output is a chunk of the output stream of the node.
+	// When the node outputs a stream, i.e. the graph is run by Stream or Transform,
+	// its chunks are followed by an event of the concatenated output.
+	IsChunk bool
+	// Output is the output of the node, or a chunk of it if IsChunk.
+	Output any
+	// Err is the error returned by the node, or received from its output stream.
+	// Interrupts aren't reported as errors.
+	Err error
+}
+
+// WithNodeOutputStream makes the graph report the outputs of the designated nodes as they complete,
+// in a stream multiplexed with the outputs of all the designated nodes, while the final output is returned as usual.
+// Nodes in subgraphs are designated by their paths from the root graph, e.g. NewNodePath("sub_graph", "chat_model").
+// If no path is specified, all the nodes of the root graph are designated.
+// The stream is closed when the run finishes, successfully or not, and the output streams of the designated nodes are finished,
+// so the final output stream should be consumed or closed as well.
+// Events are only produced by the first run the option is passed to.
+// Only works on the root graph, which must be compiled with WithNodeOutputStreamSupport, e.g.
+//
+//	runnable, err := graph.Compile(ctx, compose.WithNodeOutputStreamSupport())
+//	opt, events := compose.WithNodeOutputStream(compose.NewNodePath("retriever"), compose.NewNodePath("sub_graph", "chat_model"))
+//	sr, err := runnable.Stream(ctx, input, opt)
+//	go func() {
+//		defer sr.Close()
+//		// consume the final output
+//	}()
+//	for {
+//		event, err := events.Recv()
+//		if err == io.EOF {
+//			break
+//		}
+//		// handle event
+//	}
+func WithNodeOutputStream(nodePaths ...*NodePath) (Option, *schema.StreamReader[*NodeOutputEvent]) {
+	sr, sw := schema.Pipe[*NodeOutputEvent](0)
+	e := &nodeOutputEmitter{
+		nodePaths: nodePaths,
+		ch:        internal.NewUnboundedChan[*NodeOutputEvent](),
+		sw:        sw,
+	}
+
+	return Option{nodeOutputEmitter: e}, sr
+}
+
+type nodeOutputEmitterKey struct{}
+
+// nodeOutputEmitter buffers the events without limit, so that the graph isn't blocked by the consumer of the stream.
+type nodeOutputEmitter struct {
+	nodePaths []*NodePath
+	ch        *internal.UnboundedChan[*NodeOutputEvent]
+	// sw is written by the pump, which is started by the run the emitter is attached to
+	sw *schema.StreamWriter[*NodeOutputEvent]
+
+	mu       sync.Mutex
+	started  bool
+	finished bool
+	closed   bool
+	// number of the output streams being reported
+	pending int
+}
+
+// withNodeOutputEmitter sets the emitter of the options into ctx and starts pumping its events to the stream,
+// and returns it if it's set by this call, which means the graph is the root graph and should finish the emitter when it ends.
+func withNodeOutputEmitter(ctx context.Context, opts []Option) (context.Context, *nodeOutputEmitter) {
+	if ctx.Value(nodeOutputEmitterKey{}) != nil {
+		return ctx, nil
+	}
+
+	var e *nodeOutputEmitter
+	for _, opt := range opts {
+		if opt.nodeOutputEmitter != nil {
+			e = opt.nodeOutputEmitter
+		}
+	}
+	if e == nil {
+		return ctx, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.started {
+		// the stream belongs to the first run the option is passed to
+		return ctx, nil
+	}
+	e.started = true
+	go e.pump()
+	return context.WithValue(ctx, nodeOutputEmitterKey{}, e), e
+}
+
+func getNodeOutputEmitter(ctx context.Context) (*nodeOutputEmitter, *NodePath, bool) {
+	e, ok := ctx.Value(nodeOutputEmitterKey{}).(*nodeOutputEmitter)
+	if !ok {
+		return nil, nil, false
+	}
+	path, ok := getNodePath(ctx)
+	if !ok || !e.selected(path) {
+		return nil, nil, false
+	}
+	return e, path, true
+}
+
+func (e *nodeOutputEmitter) selected(path *NodePath) bool {
+	if len(e.nodePaths) == 0 {
+		return len(path.path) == 1
+	}
+	for _, p := range e.nodePaths {
+		if nodePathKey(p.GetPath()) == nodePathKey(path.GetPath()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *nodeOutputEmitter) emit(path *NodePath, isChunk bool, output any, err error) {
+	if err != nil && isInterruptError(err) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.ch.Send(&NodeOutputEvent{
+		NodeKey:  path.path[len(path.path)-1],
+		NodePath: path,
+		IsChunk:  isChunk,
+		Output:   output,
+		Err:      err,
+	})
+}
+
+// emitStream reports the chunks read from sr, followed by the output concatenated from concatSR,
+// which is a copy of the same stream.
+func (e *nodeOutputEmitter) emitStream(path *NodePath, sr, concatSR streamReader, helper *genericHelper) {
+	e.mu.Lock()
+	e.pending++
+	e.mu.Unlock()
+
+	go func() {
+		defer func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			e.pending--
+			e.closeIfDone()
+		}()
+
+		chunks := sr.toAnyStreamReader()
+		defer chunks.Close()
+		for {
+			chunk, err := chunks.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				concatSR.close()
+				e.emit(path, false, nil, err)
+				return
+			}
+			e.emit(path, true, chunk, nil)
+		}
+
+		output, err := helper.outputStreamConvertPair.concatStream(concatSR)
+		e.emit(path, false, output, err)
+	}()
+}
+
+// finish closes the stream once the output streams of the nodes are finished.
+func (e *nodeOutputEmitter) finish() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.finished = true
+	e.closeIfDone()
+}
+
+func (e *nodeOutputEmitter) closeIfDone() {
+	if e.finished && e.pending == 0 && !e.closed {
+		e.closed = true
+		e.ch.Close()
+	}
+}
+
+func (e *nodeOutputEmitter) pump() {
+	sw := e.sw
+	defer sw.Close()
+	for {
+		event, ok := e.ch.Receive()
+		if !ok {
+			return
+		}
+		if closed := sw.Send(event, nil); closed {
+			// the consumer has closed the stream, drop the rest
+			for _, ok = e.ch.Receive(); ok; _, ok = e.ch.Receive() {
+			}
+			return
+		}
+	}
+}
+
+// nodeOutputStreamedComposableRunnable wraps the node's runnable so that its output is reported
+// to the stream of WithNodeOutputStream if the node is designated.
+func nodeOutputStreamedComposableRunnable(r *composableRunnable) *composableRunnable {
+	wrapper := *r
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (any, error) {
+		e, path, ok := getNodeOutputEmitter(ctx)
+		if !ok {
+			return i(ctx, input, opts...)
+		}
+
+		output, err := i(ctx, input, opts...)
+		e.emit(path, false, output, err)
+		return output, err
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (streamReader, error) {
+		e, path, ok := getNodeOutputEmitter(ctx)
+		if !ok {
+			return t(ctx, input, opts...)
+		}
+
+		output, err := t(ctx, input, opts...)
+		if err != nil {
+			e.emit(path, false, nil, err)
+			return nil, err
+		}
+		copies := output.copy(3)
+		e.emitStream(path, copies[1], copies[2], r.genericHelper)
+		return copies[0], nil
+	}
+
+	return &wrapper
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestNodeOutputStream(t *testing.T) {
+	ctx := context.Background()
+
+	sub := NewGraph[string, string]()
+	_ = sub.AddLambdaNode("b", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+		return schema.StreamReaderFromArray([]string{input, "b1", "b2"}), nil
+	}))
+	_ = sub.AddEdge(START, "b")
+	_ = sub.AddEdge("b", END)
+
+	g := NewGraph[string, string]()
+	_ = g.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "a", nil
+	}))
+	_ = g.AddGraphNode("sub", sub)
+	_ = g.AddLambdaNode("c", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		return input + "c", nil
+	}))
+	_ = g.AddEdge(START, "a")
+	_ = g.AddEdge("a", "sub")
+	_ = g.AddEdge("sub", "c")
+	_ = g.AddEdge("c", END)
+	r, err := g.Compile(ctx, WithNodeOutputStreamSupport())
+	assert.NoError(t, err)
+
+	collect := func(sr *schema.StreamReader[*NodeOutputEvent]) []*NodeOutputEvent {
+		var events []*NodeOutputEvent
+		for {
+			event, err := sr.Recv()
+			if err == io.EOF {
+				return events
+			}
+			assert.NoError(t, err)
+			events = append(events, event)
+		}
+	}
+
+	t.Run("invoke", func(t *testing.T) {
+		opt, events := WithNodeOutputStream(NewNodePath("a"), NewNodePath("sub", "b"))
+		out, err := r.Invoke(ctx, "x", opt)
+		assert.NoError(t, err)
+		assert.Equal(t, "xab1b2c", out)
+		assert.Equal(t, []*NodeOutputEvent{
+			{NodeKey: "a", NodePath: NewNodePath("a"), Output: "xa"},
+			{NodeKey: "b", NodePath: NewNodePath("sub", "b"), Output: "xab1b2"},
+		}, collect(events))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		opt, events := WithNodeOutputStream(NewNodePath("sub", "b"))
+		sr, err := r.Stream(ctx, "x", opt)
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "xab1b2c", out)
+		assert.Equal(t, []*NodeOutputEvent{
+			{NodeKey: "b", NodePath: NewNodePath("sub", "b"), IsChunk: true, Output: "xa"},
+			{NodeKey: "b", NodePath: NewNodePath("sub", "b"), IsChunk: true, Output: "b1"},
+			{NodeKey: "b", NodePath: NewNodePath("sub", "b"), IsChunk: true, Output: "b2"},
+			{NodeKey: "b", NodePath: NewNodePath("sub", "b"), Output: "xab1b2"},
+		}, collect(events))
+	})
+
+	t.Run("root nodes by default", func(t *testing.T) {
+		opt, events := WithNodeOutputStream()
+		_, err := r.Invoke(ctx, "x", opt)
+		assert.NoError(t, err)
+		var keys []string
+		for _, e := range collect(events) {
+			keys = append(keys, e.NodeKey)
+		}
+		assert.Equal(t, []string{"a", "sub", "c"}, keys)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return "", errors.New("failed")
+		}))
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", END)
+		r, err := g.Compile(ctx, WithNodeOutputStreamSupport())
+		assert.NoError(t, err)
+
+		opt, events := WithNodeOutputStream()
+		_, err = r.Invoke(ctx, "x", opt)
+		assert.Error(t, err)
+		result := collect(events)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "a", result[0].NodeKey)
+			assert.EqualError(t, result[0].Err, "failed")
+		}
+	})
+	t.Run("run failing before any node", func(t *testing.T) {
+		opt, events := WithNodeOutputStream()
+		_, err := r.Invoke(ctx, "x", opt, WithCallbacks(callbacks.NewHandlerBuilder().Build()).DesignateNode("not_exist"))
+		assert.Error(t, err)
+
+		done := make(chan []*NodeOutputEvent)
+		go func() { done <- collect(events) }()
+		select {
+		case result := <-done:
+			assert.Empty(t, result)
+		case <-time.After(time.Second):
+			t.Fatal("the stream isn't closed after the run fails")
+		}
+	})
+	t.Run("requires compile option", func(t *testing.T) {
+		g := NewGraph[string, string]()
+		_ = g.AddLambdaNode("a", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		}))
+		_ = g.AddEdge(START, "a")
+		_ = g.AddEdge("a", END)
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+
+		opt, events := WithNodeOutputStream()
+		_, err = r.Invoke(ctx, "x", opt)
+		assert.ErrorContains(t, err, "WithNodeOutputStreamSupport")
+		assert.Empty(t, collect(events))
+	})
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/internal/core"
+	"github.com/cloudwego/eino/internal/serialization"
+)
+
+// PendingInterrupts describes what an interrupted run stored in a checkpoint is waiting on.
+type PendingInterrupts = core.PendingInterrupts
+
+// InterruptEdit describes the changes to the pending interrupts of a checkpoint, see EditPendingInterrupts.
+type InterruptEdit = core.InterruptEdit
+
+// GetPendingInterrupts loads the checkpoint written by an interrupted graph, and returns its pending interrupts,
+// the same InterruptContexts as the ones returned by ExtractInterruptInfo, along with the saved states, without running anything.
+// store and serializer should be the ones set by WithCheckPointStore and WithSerializer, nil serializer for the default one.
+func GetPendingInterrupts(ctx context.Context, store CheckPointStore, serializer Serializer, checkPointID string) (*PendingInterrupts, error) {
+	cp, err := loadCheckPoint(ctx, store, serializer, checkPointID)
+	if err != nil {
+		return nil, err
+	}
+	return core.ToPendingInterrupts(checkPointID, cp.InterruptID2Addr, cp.InterruptID2State,
+		cp.InterruptID2Point, cp.InterruptID2ResumeData, nil), nil
+}
+
+// EditPendingInterrupts replaces the saved states of the interrupt points and stores the resume targets,
+// then writes the checkpoint back, without running anything.
+// Like the state of GenLocalState, the types of the states and the resume data should be registered by schema.RegisterName.
+// The stored resume targets take effect on the next run with the checkpoint ID, e.g.
+//
+//	err := compose.EditPendingInterrupts(ctx, store, nil, "run-1", &compose.InterruptEdit{
+//		ResumeData: map[string]any{interruptID: &ApprovalResult{Approved: true}},
+//	})
+//	// later, possibly in another process
+//	out, err := runnable.Invoke(ctx, input, compose.WithCheckPointID("run-1"))
+func EditPendingInterrupts(ctx context.Context, store CheckPointStore, serializer Serializer, checkPointID string, edit *InterruptEdit) error {
+	if serializer == nil {
+		serializer = &serialization.InternalSerializer{}
+	}
+	cp, err := loadCheckPoint(ctx, store, serializer, checkPointID)
+	if err != nil {
+		return err
+	}
+	if cp.InterruptID2State == nil {
+		cp.InterruptID2State = make(map[string]core.InterruptState)
+	}
+	cp.InterruptID2ResumeData, err = core.ApplyInterruptEdit(edit, cp.InterruptID2Addr, cp.InterruptID2State, cp.InterruptID2ResumeData)
+	if err != nil {
+		return fmt.Errorf("edit checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if edit != nil {
+		editSubGraphStates(cp.SubGraphs, edit.States)
+	}
+
+	data, err := serializer.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	return store.Set(ctx, checkPointID, data)
+}
+
+// editSubGraphStates replaces the states saved in the checkpoints of the subgraphs as well,
+// which are the ones restored when the subgraphs resume.
+func editSubGraphStates(subGraphs map[string]*checkpoint, states map[string]any) {
+	for _, sub := range subGraphs {
+		for id, state := range states {
+			if _, ok := sub.InterruptID2Addr[id]; !ok {
+				continue
+			}
+			s := sub.InterruptID2State[id]
+			s.State = state
+			if sub.InterruptID2State == nil {
+				sub.InterruptID2State = make(map[string]core.InterruptState)
+			}
+			sub.InterruptID2State[id] = s
+		}
+		editSubGraphStates(sub.SubGraphs, states)
+	}
+}
+
+func loadCheckPoint(ctx context.Context, store CheckPointStore, serializer Serializer, checkPointID string) (*checkpoint, error) {
+	if serializer == nil {
+		serializer = &serialization.InternalSerializer{}
+	}
+	cpr := &checkPointer{store: store, serializer: serializer}
+	cp, existed, err := cpr.get(ctx, checkPointID)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint[%s] fail: %w", checkPointID, err)
+	}
+	if !existed {
+		return nil, fmt.Errorf("checkpoint[%s] not exist", checkPointID)
+	}
+	return cp, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type pendingResumeData struct {
+	Message string
+}
+
+func init() {
+	schema.RegisterName[*pendingResumeData]("_eino_test_pending_resume_data")
+}
+
+func TestPendingInterrupts(t *testing.T) {
+	subGraph := NewGraph[string, string]()
+	_ = subGraph.AddLambdaNode("inner_lambda", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		wasInterrupted, _, state := GetInterruptState[*myInterruptState](ctx)
+		if !wasInterrupted {
+			return "", StatefulInterrupt(ctx, map[string]any{"reason": "approval"}, &myInterruptState{OriginalInput: input})
+		}
+		isResume, hasData, data := GetResumeContext[*pendingResumeData](ctx)
+		if !isResume || !hasData {
+			return "", StatefulInterrupt(ctx, map[string]any{"reason": "approval"}, state)
+		}
+		return state.OriginalInput + ":" + data.Message, nil
+	}))
+	_ = subGraph.AddEdge(START, "inner_lambda")
+	_ = subGraph.AddEdge("inner_lambda", END)
+
+	g := NewGraph[string, string]()
+	_ = g.AddGraphNode("sub_graph_node", subGraph)
+	_ = g.AddEdge(START, "sub_graph_node")
+	_ = g.AddEdge("sub_graph_node", END)
+
+	ctx := context.Background()
+	store := newInMemoryStore()
+	r, err := g.Compile(ctx, WithCheckPointStore(store))
+	assert.NoError(t, err)
+
+	_, err = r.Invoke(ctx, "input", WithCheckPointID("1"))
+	info, ok := ExtractInterruptInfo(err)
+	assert.True(t, ok)
+
+	pending, err := GetPendingInterrupts(ctx, store, nil, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", pending.CheckPointID)
+	assert.Equal(t, info.InterruptContexts, pending.InterruptContexts)
+	if assert.Len(t, pending.InterruptContexts, 1) {
+		ic := pending.InterruptContexts[0]
+		assert.Equal(t, "runnable:;node:sub_graph_node;node:inner_lambda", ic.Address.String())
+		assert.Equal(t, map[string]any{"reason": "approval"}, ic.Info)
+		assert.True(t, ic.IsRootCause)
+		assert.NotNil(t, ic.Parent)
+		assert.Equal(t, &myInterruptState{OriginalInput: "input"}, pending.States[ic.ID])
+	}
+	assert.Empty(t, pending.ResumeData)
+
+	id := pending.InterruptContexts[0].ID
+	err = EditPendingInterrupts(ctx, store, nil, "1", &InterruptEdit{States: map[string]any{"unknown": 1}})
+	assert.Error(t, err)
+	err = EditPendingInterrupts(ctx, store, nil, "1", &InterruptEdit{
+		States:     map[string]any{id: &myInterruptState{OriginalInput: "edited"}},
+		ResumeData: map[string]any{id: &pendingResumeData{Message: "approved"}},
+	})
+	assert.NoError(t, err)
+
+	pending, err = GetPendingInterrupts(ctx, store, nil, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, &myInterruptState{OriginalInput: "edited"}, pending.States[id])
+	assert.Equal(t, &pendingResumeData{Message: "approved"}, pending.ResumeData[id])
+
+	// the stored resume data is used without passing it along with the resume
+	out, err := r.Invoke(ctx, "", WithCheckPointID("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "edited:approved", out)
+
+	_, err = GetPendingInterrupts(ctx, store, nil, "2")
+	assert.Error(t, err)
+}
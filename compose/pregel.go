@@ -23,13 +23,16 @@ func pregelChannelBuilder(_ []string, _ []string, _ func() any, _ func() streamR
 }
 
 type pregelChannel struct {
-	Values map[string]any
+	Values   map[string]any
+	Arrivals []string
 
 	mergeConfig FanInMergeConfig
 }
 
 func (ch *pregelChannel) setMergeConfig(cfg FanInMergeConfig) {
 	ch.mergeConfig.StreamMergeWithSourceEOF = cfg.StreamMergeWithSourceEOF
+	ch.mergeConfig.Reducer = cfg.Reducer
+	ch.mergeConfig.StreamInterleave = cfg.StreamInterleave
 }
 
 func (ch *pregelChannel) load(c channel) error {
@@ -38,6 +41,7 @@ func (ch *pregelChannel) load(c channel) error {
 		return fmt.Errorf("load pregel channel fail, got %T, want *pregelChannel", c)
 	}
 	ch.Values = dc.Values
+	ch.Arrivals = dc.Arrivals
 	return nil
 }
 
@@ -49,6 +53,7 @@ func (ch *pregelChannel) reportValues(ins map[string]any) error {
 	for k, v := range ins {
 		ch.Values[k] = v
 	}
+	ch.Arrivals = reportArrivals(ch.Arrivals, ins)
 	return nil
 }
 
@@ -57,12 +62,15 @@ func (ch *pregelChannel) get(isStream bool, name string, edgeHandler *edgeHandle
 	if len(ch.Values) == 0 {
 		return nil, false, nil
 	}
-	defer func() { ch.Values = map[string]any{} }()
+	defer func() {
+		ch.Values = map[string]any{}
+		ch.Arrivals = nil
+	}()
 	values := make([]any, len(ch.Values))
 	names := make([]string, len(ch.Values))
 	i := 0
-	for k, v := range ch.Values {
-		resolvedV, err := edgeHandler.handle(k, name, v, isStream)
+	for _, k := range arrivalOrder(ch.Values, ch.Arrivals) {
+		resolvedV, err := edgeHandler.handle(k, name, ch.Values[k], isStream)
 		if err != nil {
 			return nil, false, err
 		}
@@ -79,6 +87,8 @@ func (ch *pregelChannel) get(isStream bool, name string, edgeHandler *edgeHandle
 	mergeOpts := &mergeOptions{
 		streamMergeWithSourceEOF: ch.mergeConfig.StreamMergeWithSourceEOF,
 		names:                    names,
+		reducer:                  ch.mergeConfig.Reducer,
+		streamInterleave:         ch.mergeConfig.StreamInterleave,
 	}
 	v, err := mergeValues(values, mergeOpts)
 	if err != nil {
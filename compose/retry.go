@@ -0,0 +1,204 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how a graph node is retried when it fails.
+// It can be attached to any node of Graph, Chain or Workflow through WithRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times the node will be executed, including the first attempt.
+	// A value less than or equal to 1 disables retrying.
+	MaxAttempts int
+
+	// IsRetryable decides whether an error returned by the node should trigger another attempt.
+	// If nil, all errors are considered retryable.
+	// Interrupt errors and context cancellation are never retried, regardless of this function.
+	IsRetryable func(ctx context.Context, err error) bool
+
+	// Backoff calculates the delay before the next attempt.
+	// The attempt parameter starts at 1 for the first retry.
+	// If nil, an exponential backoff with jitter is used: 100ms base delay, up to 10s.
+	Backoff func(ctx context.Context, attempt int) time.Duration
+}
+
+// ErrExceedMaxAttempts is matched by the error returned when a node has failed all attempts allowed by its RetryPolicy.
+var ErrExceedMaxAttempts = errors.New("exceeds max attempts")
+
+// RetryExhaustedError is returned when a node has failed all attempts allowed by its RetryPolicy.
+// errors.Is(err, ErrExceedMaxAttempts) reports true for it, and it unwraps to the error of the last attempt.
+type RetryExhaustedError struct {
+	LastErr  error
+	Attempts int
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("exceeds max attempts(%d), last error: %v", e.Attempts, e.LastErr)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.LastErr
+}
+
+func (e *RetryExhaustedError) Is(target error) bool {
+	return target == ErrExceedMaxAttempts
+}
+
+type retryAttemptKey struct{}
+
+// GetRetryAttempt returns the attempt number of the node currently being executed under a RetryPolicy.
+// The first attempt is 0 and the first retry is 1.
+// It's intended to be called in callback handlers or inside the node, to tell attempts apart.
+func GetRetryAttempt(ctx context.Context) (int, bool) {
+	attempt, ok := ctx.Value(retryAttemptKey{}).(int)
+	return attempt, ok
+}
+
+func defaultRetryBackoff(_ context.Context, attempt int) time.Duration {
+	baseDelay := 100 * time.Millisecond
+	maxDelay := 10 * time.Second
+
+	if attempt <= 0 {
+		return baseDelay
+	}
+
+	if attempt > 7 {
+		return maxDelay
+	}
+
+	delay := baseDelay * time.Duration(1<<uint(attempt-1))
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if isInterruptError(err) || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return false
+	}
+	if p.IsRetryable == nil {
+		return true
+	}
+	return p.IsRetryable(ctx, err)
+}
+
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = defaultRetryBackoff
+	}
+
+	d := backoff(ctx, attempt)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryableComposableRunnable wraps the node's runnable so that each attempt runs through the node's own callbacks.
+// In stream mode, an attempt is retried only if it fails before its first chunk is emitted to downstream.
+func retryableComposableRunnable(p *RetryPolicy, r *composableRunnable) *composableRunnable {
+	if p == nil || p.MaxAttempts <= 1 {
+		return r
+	}
+
+	wrapper := *r
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (output any, err error) {
+		for attempt := 0; ; attempt++ {
+			output, err = i(context.WithValue(ctx, retryAttemptKey{}, attempt), input, opts...)
+			if err == nil {
+				return output, nil
+			}
+			if !p.shouldRetry(ctx, err) {
+				return nil, err
+			}
+			if attempt+1 >= p.MaxAttempts {
+				return nil, &RetryExhaustedError{LastErr: err, Attempts: p.MaxAttempts}
+			}
+			if wErr := p.wait(ctx, attempt+1); wErr != nil {
+				return nil, err
+			}
+		}
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (output streamReader, err error) {
+		inputs := input.copy(p.MaxAttempts)
+		defer func() {
+			for _, in := range inputs {
+				in.close()
+			}
+		}()
+
+		for attempt := 0; ; attempt++ {
+			in := inputs[0]
+			inputs = inputs[1:]
+
+			output, err = t(context.WithValue(ctx, retryAttemptKey{}, attempt), in, opts...)
+			if err == nil {
+				output, err = peekFirstChunk(output)
+				if err == nil {
+					return output, nil
+				}
+			}
+			if !p.shouldRetry(ctx, err) {
+				return nil, err
+			}
+			if attempt+1 >= p.MaxAttempts {
+				return nil, &RetryExhaustedError{LastErr: err, Attempts: p.MaxAttempts}
+			}
+			if wErr := p.wait(ctx, attempt+1); wErr != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &wrapper
+}
+
+// peekFirstChunk reads the first chunk of sr without consuming it for the returned stream.
+// it returns the error of the first chunk if any, in which case sr has been closed.
+func peekFirstChunk(sr streamReader) (streamReader, error) {
+	copies := sr.copy(2)
+	checker := copies[0].toAnyStreamReader()
+	_, err := checker.Recv()
+	checker.Close()
+	if err != nil && err != io.EOF {
+		copies[1].close()
+		return nil, err
+	}
+	return copies[1], nil
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+func noBackoff(context.Context, int) time.Duration { return 0 }
+
+func TestRetryPolicyInvoke(t *testing.T) {
+	ctx := context.Background()
+	errTransient := errors.New("transient")
+
+	t.Run("succeed after retries", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", errTransient
+			}
+			return input + "_ok", nil
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: noBackoff})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		var mu sync.Mutex
+		var attempts []int
+		var errCount int
+		cb := callbacks.NewHandlerBuilder().
+			OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+				if attempt, ok := GetRetryAttempt(ctx); ok {
+					mu.Lock()
+					attempts = append(attempts, attempt)
+					mu.Unlock()
+				}
+				return ctx
+			}).
+			OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+				if _, ok := GetRetryAttempt(ctx); ok {
+					mu.Lock()
+					errCount++
+					mu.Unlock()
+				}
+				return ctx
+			}).Build()
+
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		out, err := r.Invoke(ctx, "in", WithCallbacks(cb))
+		assert.NoError(t, err)
+		assert.Equal(t, "in_ok", out)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{0, 1, 2}, attempts)
+		assert.Equal(t, 2, errCount)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			calls++
+			return "", errTransient
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, Backoff: noBackoff})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "in")
+		assert.Equal(t, 2, calls)
+		assert.True(t, errors.Is(err, ErrExceedMaxAttempts))
+		assert.True(t, errors.Is(err, errTransient))
+		var re *RetryExhaustedError
+		assert.True(t, errors.As(err, &re))
+		assert.Equal(t, 2, re.Attempts)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		calls := 0
+		errPermanent := errors.New("permanent")
+		c := NewChain[string, string]()
+		c.AppendLambda(InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			calls++
+			return "", errPermanent
+		}), WithRetryPolicy(&RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     noBackoff,
+			IsRetryable: func(ctx context.Context, err error) bool {
+				return !errors.Is(err, errPermanent)
+			},
+		}))
+
+		r, err := c.Compile(ctx)
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "in")
+		assert.Equal(t, 1, calls)
+		assert.True(t, errors.Is(err, errPermanent))
+		assert.False(t, errors.Is(err, ErrExceedMaxAttempts))
+	})
+
+	t.Run("interrupt is not retried", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			calls++
+			return "", Interrupt(ctx, "need input")
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: noBackoff})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		_, err = r.Invoke(ctx, "in")
+		assert.Equal(t, 1, calls)
+		_, ok := ExtractInterruptInfo(err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRetryPolicyStream(t *testing.T) {
+	ctx := context.Background()
+	errTransient := errors.New("transient")
+
+	t.Run("retry on first chunk error", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+			calls++
+			sr, sw := schema.Pipe[string](2)
+			if calls == 1 {
+				sw.Send("", errTransient)
+			} else {
+				sw.Send(input, nil)
+				sw.Send("_ok", nil)
+			}
+			sw.Close()
+			return sr, nil
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, Backoff: noBackoff})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		sr, err := r.Stream(ctx, "in")
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "in_ok", out)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("no retry after first chunk", func(t *testing.T) {
+		calls := 0
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", StreamableLambda(func(ctx context.Context, input string) (*schema.StreamReader[string], error) {
+			calls++
+			sr, sw := schema.Pipe[string](2)
+			sw.Send(input, nil)
+			sw.Send("", errTransient)
+			sw.Close()
+			return sr, nil
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: noBackoff})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+
+		r, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		sr, err := r.Stream(ctx, "in")
+		assert.NoError(t, err)
+		chunk, err := sr.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "in", chunk)
+		_, err = sr.Recv()
+		assert.True(t, errors.Is(err, errTransient))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stream input replayed for every attempt", func(t *testing.T) {
+		calls := 0
+		wf := NewWorkflow[string, string]()
+		wf.AddLambdaNode("1", TransformableLambda(func(ctx context.Context, input *schema.StreamReader[string]) (*schema.StreamReader[string], error) {
+			calls++
+			var s string
+			for {
+				chunk, err := input.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				s += chunk
+			}
+			input.Close()
+			if calls == 1 {
+				return nil, errTransient
+			}
+			return schema.StreamReaderFromArray([]string{s}), nil
+		}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, Backoff: noBackoff})).AddInput(START)
+		wf.End().AddInput("1")
+
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+		out, err := r.Transform(ctx, schema.StreamReaderFromArray([]string{"a", "b"}))
+		assert.NoError(t, err)
+		result, err := concatStreamReader(out)
+		assert.NoError(t, err)
+		assert.Equal(t, "ab", result)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestRetryPolicyBackoffCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errTransient := errors.New("transient")
+	calls := 0
+	g := NewGraph[string, string]()
+	assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+		calls++
+		cancel()
+		return "", errTransient
+	}), WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: func(context.Context, int) time.Duration {
+		return time.Hour
+	}})))
+	assert.NoError(t, g.AddEdge(START, "1"))
+	assert.NoError(t, g.AddEdge("1", END))
+
+	r, err := g.Compile(context.Background())
+	assert.NoError(t, err)
+	_, err = r.Invoke(ctx, "in")
+	assert.True(t, errors.Is(err, errTransient))
+	assert.Equal(t, 1, calls)
+}
@@ -17,9 +17,14 @@
 package compose
 
 import (
+	"io"
 	"reflect"
+	"runtime/debug"
+	"sync/atomic"
 
+	"github.com/cloudwego/eino/internal"
 	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/internal/safe"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -32,6 +37,8 @@ type streamReader interface {
 	close()
 	toAnyStreamReader() *schema.StreamReader[any]
 	mergeWithNames([]streamReader, []string) streamReader
+	withDrainHook(func()) streamReader
+	withEndHook(wrapErr func(error) error, hook func()) streamReader
 }
 
 type streamReaderPacker[T any] struct {
@@ -102,6 +109,85 @@ func (srp streamReaderPacker[T]) withKey(key string) streamReader {
 	return packStreamReader(ret)
 }
 
+// withDrainHook returns a stream of the same chunks, which are received from the original stream eagerly,
+// so that hook is called once the original stream ends, or the returned one is closed, regardless of the pace of its consumer.
+func (srp streamReaderPacker[T]) withDrainHook(hook func()) streamReader {
+	type item struct {
+		chunk T
+		err   error
+	}
+	buf := internal.NewUnboundedChan[item]()
+	var closed int32
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				buf.Send(item{err: safe.NewPanicErr(p, debug.Stack())})
+			}
+			srp.sr.Close()
+			buf.Close()
+			hook()
+		}()
+		for atomic.LoadInt32(&closed) == 0 {
+			chunk, err := srp.sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			buf.Send(item{chunk: chunk, err: err})
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	out, sw := schema.Pipe[T](0)
+	go func() {
+		defer sw.Close()
+		for {
+			it, ok := buf.Receive()
+			if !ok {
+				return
+			}
+			if c := sw.Send(it.chunk, it.err); c {
+				atomic.StoreInt32(&closed, 1)
+				return
+			}
+		}
+	}()
+	return packStreamReader(out)
+}
+
+// withEndHook returns a stream of the same chunks, with the errors wrapped by wrapErr,
+// and calls hook once the original stream ends, or once the returned one is found closed when forwarding the next chunk.
+// Unlike withDrainHook, the chunks are received from the original stream at the pace of the consumer.
+func (srp streamReaderPacker[T]) withEndHook(wrapErr func(error) error, hook func()) streamReader {
+	out, sw := schema.Pipe[T](0)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				var t T
+				sw.Send(t, safe.NewPanicErr(p, debug.Stack()))
+			}
+			srp.sr.Close()
+			sw.Close()
+			hook()
+		}()
+		for {
+			chunk, err := srp.sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				err = wrapErr(err)
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return packStreamReader(out)
+}
+
 func (srp streamReaderPacker[T]) toAnyStreamReader() *schema.StreamReader[any] {
 	return schema.StreamReaderWithConvert(srp.sr, func(t T) (any, error) {
 		return t, nil
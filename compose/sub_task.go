@@ -0,0 +1,194 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/schema"
+)
+
+// RunSubTask invokes the runnable, typically a compiled graph decided at runtime, as a child step of the running node,
+// so that sub-tasks can be scheduled dynamically by a Lambda node, e.g. a planner running the steps it plans.
+// The output is returned to the node, see RunSubTaskTo to send it to a successor of the node instead.
+// The sub-task runs at the path of the node appended by key, as if it were a subgraph node of the node:
+//   - callbacks of the node are inherited, and the nodes of the sub-task are reported under the path
+//   - an interrupt of the sub-task interrupts the node, and the checkpoint of the sub-task is saved in the checkpoint of the graph,
+//     from which the sub-task resumes when the node reruns and runs the sub-task of the same key again
+//
+// key should be unique among the sub-tasks of a run of the node.
+// Like other interrupted nodes, the node reruns without its input, so it should save what it needs as the interrupt state.
+// To interrupt on several sub-tasks, pass their errors to CompositeInterrupt, e.g.
+//
+//	planner := compose.InvokableLambda(func(ctx context.Context, plan *Plan) (map[string]any, error) {
+//		if _, hasState, state := compose.GetInterruptState[*Plan](ctx); hasState {
+//			plan = state
+//		}
+//		results := make(map[string]any, len(plan.Steps))
+//		var errs []error
+//		for _, step := range plan.Steps {
+//			out, err := compose.RunSubTask(ctx, step.ID, runnables[step.Kind], step.Input)
+//			if _, ok := compose.IsInterruptRerunError(err); ok {
+//				errs = append(errs, err)
+//				continue
+//			}
+//			if err != nil {
+//				return nil, err
+//			}
+//			results[step.ID] = out
+//		}
+//		if len(errs) > 0 {
+//			return nil, compose.CompositeInterrupt(ctx, nil, plan, errs...)
+//		}
+//		return results, nil
+//	})
+func RunSubTask[I, O any](ctx context.Context, key string, r Runnable[I, O], input I, opts ...Option) (output O, err error) {
+	ctx, err = subTaskContext(ctx, key)
+	if err != nil {
+		return output, err
+	}
+	output, err = r.Invoke(ctx, input, opts...)
+	return output, toSubTaskError(err)
+}
+
+// RunSubTaskTo is RunSubTask that sends the output of the sub-task to the successor of the node,
+// in place of the node's own output on the edge to the successor, once the node completes.
+// The successor must be a node connected from the node by an edge without field mappings, and accept the output as its input.
+// Sending to the same successor again replaces the output sent before,
+// and the outputs are discarded if the node fails or interrupts.
+// As the output of the node is checked against the input types of its successors when compiling,
+// the node usually outputs any, e.g.
+//
+//	planner := compose.InvokableLambda(func(ctx context.Context, plan *Plan) (any, error) {
+//		for _, step := range plan.Steps {
+//			if err := compose.RunSubTaskTo(ctx, step.ID, step.Successor, runnables[step.Kind], step.Input); err != nil {
+//				return nil, err
+//			}
+//		}
+//		return plan, nil
+//	})
+//
+// A cached node replays only its own output on a cache hit, so a node sending outputs to its successors shouldn't be cached.
+func RunSubTaskTo[I, O any](ctx context.Context, key, successor string, r Runnable[I, O], input I, opts ...Option) error {
+	routes, _ := ctx.Value(subTaskRoutesKey{}).(*subTaskRoutes)
+	if routes == nil {
+		return errors.New("sub task can only be run inside a node of a graph")
+	}
+	inputType, ok := routes.successors[successor]
+	if !ok {
+		return fmt.Errorf("sub task output can't be sent to node[%s], which isn't a successor of node[%s] connected by an edge without field mappings",
+			successor, routes.node)
+	}
+	if typ := generic.TypeOf[O](); checkAssignable(typ, inputType) == assignableTypeMustNot {
+		return fmt.Errorf("sub task output type[%v] mismatches the input type[%v] of node[%s]", typ, inputType, successor)
+	}
+
+	output, err := RunSubTask(ctx, key, r, input, opts...)
+	if err != nil {
+		return err
+	}
+	if typ := reflect.TypeOf(output); typ != nil && !typ.AssignableTo(inputType) {
+		return fmt.Errorf("sub task output type[%v] mismatches the input type[%v] of node[%s]", typ, inputType, successor)
+	}
+
+	routes.mu.Lock()
+	defer routes.mu.Unlock()
+	routes.outputs[successor] = output
+	return nil
+}
+
+// StreamSubTask is RunSubTask that streams the output of the sub-task.
+func StreamSubTask[I, O any](ctx context.Context, key string, r Runnable[I, O], input I, opts ...Option) (*schema.StreamReader[O], error) {
+	ctx, err := subTaskContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	output, err := r.Stream(ctx, input, opts...)
+	return output, toSubTaskError(err)
+}
+
+type subTaskKey struct{}
+
+type subTaskRoutesKey struct{}
+
+// subTaskRoutes collects the outputs of the sub-tasks sent to the successors of the running node, see RunSubTaskTo.
+type subTaskRoutes struct {
+	node       string
+	successors map[string]reflect.Type // the successors the outputs can be sent to, and their input types
+
+	mu      sync.Mutex
+	outputs map[string]any
+}
+
+// withSubTaskRoutes makes the outputs of the sub-tasks of the task sendable to its successors,
+// which also hides those of the enclosing node from the runnables run by the task.
+func withSubTaskRoutes(ctx context.Context, t *task) (context.Context, *subTaskRoutes) {
+	if len(t.call.subTaskSuccessors) == 0 {
+		return context.WithValue(ctx, subTaskRoutesKey{}, (*subTaskRoutes)(nil)), nil
+	}
+	routes := &subTaskRoutes{
+		node:       t.nodeKey,
+		successors: t.call.subTaskSuccessors,
+		outputs:    make(map[string]any),
+	}
+	return context.WithValue(ctx, subTaskRoutesKey{}, routes), routes
+}
+
+func subTaskContext(ctx context.Context, key string) (context.Context, error) {
+	if _, ok := getNodePath(ctx); !ok {
+		return nil, errors.New("sub task can only be run inside a node of a graph")
+	}
+	if key == "" {
+		return nil, errors.New("sub task key is empty")
+	}
+
+	ctx = AppendAddressSegment(ctx, AddressSegmentNode, key)
+
+	// resume from the checkpoint saved in the interrupt state, or run from the start
+	var cp *checkpoint
+	if wasInterrupted, hasState, state := GetInterruptState[*checkpoint](ctx); wasInterrupted && hasState {
+		cp = state
+	}
+	ctx = context.WithValue(ctx, checkPointKey{}, cp)
+
+	return context.WithValue(ctx, subTaskKey{}, true), nil
+}
+
+// enterSubTask reports whether the graph to run in ctx is a sub-task, which runs as a subgraph rather than a root graph,
+// and consumes the mark so that it doesn't apply to the runnables run inside the sub-task.
+func enterSubTask(ctx context.Context) (context.Context, bool) {
+	if isSubTask, _ := ctx.Value(subTaskKey{}).(bool); !isSubTask {
+		return ctx, false
+	}
+	return context.WithValue(ctx, subTaskKey{}, false), true
+}
+
+// toSubTaskError converts the interrupt of the sub-task to the interrupt signal carrying its checkpoint as the state,
+// which is saved by the graph along with the other interrupt states.
+func toSubTaskError(err error) error {
+	info := isSubGraphInterrupt(err)
+	if info == nil {
+		return err
+	}
+	info.signal.InterruptState.State = info.CheckPoint
+	return info.signal
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSubTask(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var paths []string
+	prepCount := 0
+	sub := NewGraph[string, string]()
+	_ = sub.AddLambdaNode("prep", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		p, _ := getNodePath(ctx)
+		paths = append(paths, strings.Join(p.GetPath(), "/"))
+		prepCount++
+		return in + "_prep", nil
+	}))
+	_ = sub.AddLambdaNode("ask", InvokableLambda(func(ctx context.Context, in string) (string, error) {
+		if wasInterrupted, _, state := GetInterruptState[string](ctx); wasInterrupted {
+			return state + "_approved", nil
+		}
+		if !strings.HasPrefix(in, "ask") {
+			return in, nil
+		}
+		return "", StatefulInterrupt(ctx, "need approval", in)
+	}))
+	_ = sub.AddEdge(START, "prep")
+	_ = sub.AddEdge("prep", "ask")
+	_ = sub.AddEdge("ask", END)
+	subRunnable, err := sub.Compile(ctx)
+	require.NoError(t, err)
+
+	g := NewGraph[[]string, map[string]any]()
+	_ = g.AddLambdaNode("planner", InvokableLambda(func(ctx context.Context, steps []string) (map[string]any, error) {
+		if _, hasState, state := GetInterruptState[[]string](ctx); hasState {
+			steps = state
+		}
+		results := make(map[string]any, len(steps))
+		var errs []error
+		for _, step := range steps {
+			out, err := RunSubTask(ctx, step, subRunnable, step)
+			if _, ok := IsInterruptRerunError(err); ok {
+				errs = append(errs, err)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			results[step] = out
+		}
+		if len(errs) > 0 {
+			return nil, CompositeInterrupt(ctx, nil, steps, errs...)
+		}
+		return results, nil
+	}))
+	_ = g.AddEdge(START, "planner")
+	_ = g.AddEdge("planner", END)
+	r, err := g.Compile(ctx, WithCheckPointStore(newInMemoryStore()))
+	require.NoError(t, err)
+
+	t.Run("run", func(t *testing.T) {
+		paths, prepCount = nil, 0
+		out, err := r.Invoke(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "a_prep", "b": "b_prep"}, out)
+		assert.Equal(t, []string{"planner/a/prep", "planner/b/prep"}, paths)
+	})
+
+	t.Run("interrupt and resume", func(t *testing.T) {
+		paths, prepCount = nil, 0
+		_, err := r.Invoke(ctx, []string{"a", "ask1", "ask2"}, WithCheckPointID("sub_task"))
+		info, ok := ExtractInterruptInfo(err)
+		require.True(t, ok)
+		var ids []string
+		for _, ic := range info.InterruptContexts {
+			assert.Equal(t, "need approval", ic.Info)
+			ids = append(ids, ic.ID)
+			var nodes []string
+			for _, seg := range ic.Address {
+				nodes = append(nodes, seg.ID)
+			}
+			assert.Contains(t, []string{"planner/ask1/ask", "planner/ask2/ask"}, strings.Join(nodes[1:], "/"))
+		}
+		assert.Len(t, ids, 2)
+		assert.Equal(t, 3, prepCount)
+
+		out, err := r.Invoke(Resume(ctx, ids...), nil, WithCheckPointID("sub_task"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "a_prep", "ask1": "ask1_prep_approved", "ask2": "ask2_prep_approved"}, out)
+		// the interrupted sub-tasks resume from their checkpoints, only sub-task a runs from the start again
+		assert.Equal(t, 4, prepCount)
+	})
+
+	t.Run("send outputs to successors", func(t *testing.T) {
+		g := NewGraph[[]string, map[string]any]()
+		_ = g.AddLambdaNode("planner", InvokableLambda(func(ctx context.Context, steps []string) (any, error) {
+			for _, step := range steps {
+				if err := RunSubTaskTo(ctx, step, "use_"+step, subRunnable, step); err != nil {
+					return nil, err
+				}
+			}
+			return "planned", nil
+		}))
+		for _, key := range []string{"a", "b"} {
+			_ = g.AddLambdaNode("use_"+key, InvokableLambda(func(ctx context.Context, in string) (string, error) {
+				return "used_" + in, nil
+			}), WithOutputKey(key))
+			_ = g.AddEdge("planner", "use_"+key)
+			_ = g.AddEdge("use_"+key, END)
+		}
+		_ = g.AddEdge(START, "planner")
+		r, err := g.Compile(ctx)
+		require.NoError(t, err)
+
+		out, err := r.Invoke(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "used_a_prep", "b": "used_b_prep"}, out)
+
+		// the successor not sent to receives the output of the node
+		out, err = r.Invoke(ctx, []string{"a"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "used_a_prep", "b": "used_planned"}, out)
+
+		sr, err := r.Stream(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+		out, err = concatStreamReader(sr)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": "used_a_prep", "b": "used_b_prep"}, out)
+
+		_, err = r.Invoke(ctx, []string{"c"})
+		assert.ErrorContains(t, err, "isn't a successor of node[planner]")
+	})
+
+	t.Run("send outputs to successors of mismatched type", func(t *testing.T) {
+		g := NewGraph[string, int]()
+		_ = g.AddLambdaNode("planner", InvokableLambda(func(ctx context.Context, in string) (any, error) {
+			return 1, RunSubTaskTo(ctx, "step", "count", subRunnable, in)
+		}))
+		_ = g.AddLambdaNode("count", InvokableLambda(func(ctx context.Context, in int) (int, error) {
+			return in, nil
+		}))
+		_ = g.AddEdge(START, "planner")
+		_ = g.AddEdge("planner", "count")
+		_ = g.AddEdge("count", END)
+		r, err := g.Compile(ctx)
+		require.NoError(t, err)
+
+		_, err = r.Invoke(ctx, "a")
+		assert.ErrorContains(t, err, "mismatches the input type[int] of node[count]")
+	})
+
+	t.Run("outside a graph", func(t *testing.T) {
+		_, err := RunSubTask(ctx, "a", subRunnable, "a")
+		assert.Error(t, err)
+		assert.Error(t, RunSubTaskTo(ctx, "a", "b", subRunnable, "a"))
+	})
+}
@@ -0,0 +1,528 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/internal/serialization"
+	"github.com/cloudwego/eino/schema"
+)
+
+func init() {
+	schema.RegisterName[*Trace]("_eino_trace")
+	schema.RegisterName[*TraceRecord]("_eino_trace_record")
+}
+
+// Trace is the execution history of graph nodes recorded by TraceRecorder.
+type Trace struct {
+	// Records are ordered by the start time of node executions.
+	Records []*TraceRecord
+}
+
+// TraceRecord is a single execution of a graph node.
+// Input and Output are the payloads received by callback handlers,
+// which are the input and output of the node, or the typed callback payloads if the component reports callbacks by itself,
+// e.g. *model.CallbackInput and *model.CallbackOutput.
+// To be serialized, their concrete types must be registered by schema.RegisterName.
+type TraceRecord struct {
+	// NodePath is the path of the node from the root graph, see NodePath.
+	NodePath []string
+	// Step is the step of the graph run in which the node is executed, counted from 0.
+	Step int
+	// Attempt is the retry attempt of the execution, see GetRetryAttempt.
+	Attempt int
+
+	Name      string
+	Component components.Component
+	Type      string
+
+	Input  any
+	Output any
+	// InputChunks and OutputChunks hold the chunks if the input or output is a stream, in which case Input or Output is nil.
+	InputChunks    []any
+	OutputChunks   []any
+	IsStreamInput  bool
+	IsStreamOutput bool
+
+	// Error is the message of the error returned by the node, empty if succeeded.
+	Error string
+
+	StartTime time.Time
+	EndTime   time.Time
+
+	runInfo *callbacks.RunInfo
+}
+
+// TraceStore persists serialized traces.
+// Its method set is the same as CheckPointStore, so implementations of CheckPointStore can be used as TraceStore.
+type TraceStore interface {
+	Get(ctx context.Context, traceID string) ([]byte, bool, error)
+	Set(ctx context.Context, traceID string, trace []byte) error
+}
+
+// TraceRecorder is a callbacks.Handler recording the input, output, error, step and timing of every graph node,
+// including the chunks of streamed input and output.
+// Components called inside a node, e.g. a chat model called in a Lambda, are not recorded separately.
+// e.g.
+//
+//	recorder := compose.NewTraceRecorder()
+//	out, err := runnable.Invoke(ctx, in, compose.WithCallbacks(recorder))
+//	err = recorder.Save(ctx, store, "trace_id")
+type TraceRecorder struct {
+	mu      sync.Mutex
+	records []*TraceRecord
+	// pending stream readers
+	wg sync.WaitGroup
+}
+
+// NewTraceRecorder creates a TraceRecorder.
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{}
+}
+
+type traceRecordKey struct{}
+type nodeRunInfoKey struct{}
+type nodeStepKey struct{}
+
+// Trace returns the records collected so far.
+// It waits until all recorded streams are finished, so it should be called after the output stream of the run is consumed.
+func (r *TraceRecorder) Trace() *Trace {
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]*TraceRecord, 0, len(r.records))
+	for _, rec := range r.records {
+		cp := *rec
+		cp.runInfo = nil
+		records = append(records, &cp)
+	}
+	return &Trace{Records: records}
+}
+
+// Save serializes the trace collected so far and sets it into the store.
+func (r *TraceRecorder) Save(ctx context.Context, store TraceStore, traceID string) error {
+	data, err := (&serialization.InternalSerializer{}).Marshal(r.Trace())
+	if err != nil {
+		return fmt.Errorf("marshal trace fail: %w", err)
+	}
+	return store.Set(ctx, traceID, data)
+}
+
+// LoadTrace gets the trace saved by TraceRecorder.Save from the store.
+func LoadTrace(ctx context.Context, store TraceStore, traceID string) (*Trace, error) {
+	data, ok, err := store.Get(ctx, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("get trace from store fail: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("trace[%s] not found", traceID)
+	}
+
+	t := &Trace{}
+	if err = (&serialization.InternalSerializer{}).Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("unmarshal trace fail: %w", err)
+	}
+	return t, nil
+}
+
+// start creates the record if ctx belongs to the execution of a graph node.
+func (r *TraceRecorder) start(ctx context.Context, info *callbacks.RunInfo) (context.Context, *TraceRecord) {
+	if nodeInfo, _ := ctx.Value(nodeRunInfoKey{}).(*callbacks.RunInfo); nodeInfo == nil || nodeInfo != info {
+		return ctx, nil
+	}
+	path, ok := getNodePath(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	rec := &TraceRecord{
+		NodePath:  path.GetPath(),
+		Name:      info.Name,
+		Component: info.Component,
+		Type:      info.Type,
+		StartTime: time.Now(),
+		runInfo:   info,
+	}
+	rec.Step, _ = ctx.Value(nodeStepKey{}).(int)
+	rec.Attempt, _ = GetRetryAttempt(ctx)
+
+	r.mu.Lock()
+	r.records = append(r.records, rec)
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, traceRecordKey{}, rec), rec
+}
+
+func (r *TraceRecorder) recordOf(ctx context.Context, info *callbacks.RunInfo) *TraceRecord {
+	rec, _ := ctx.Value(traceRecordKey{}).(*TraceRecord)
+	if rec == nil || rec.runInfo != info {
+		return nil
+	}
+	return rec
+}
+
+// OnStart implements callbacks.Handler.
+func (r *TraceRecorder) OnStart(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+	ctx, rec := r.start(ctx, info)
+	if rec != nil {
+		r.mu.Lock()
+		rec.Input = input
+		r.mu.Unlock()
+	}
+	return ctx
+}
+
+// OnEnd implements callbacks.Handler.
+func (r *TraceRecorder) OnEnd(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+	if rec := r.recordOf(ctx, info); rec != nil {
+		r.mu.Lock()
+		rec.Output = output
+		rec.EndTime = time.Now()
+		r.mu.Unlock()
+	}
+	return ctx
+}
+
+// OnError implements callbacks.Handler.
+func (r *TraceRecorder) OnError(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+	if rec := r.recordOf(ctx, info); rec != nil {
+		r.mu.Lock()
+		rec.Error = err.Error()
+		rec.EndTime = time.Now()
+		r.mu.Unlock()
+	}
+	return ctx
+}
+
+// OnStartWithStreamInput implements callbacks.Handler.
+func (r *TraceRecorder) OnStartWithStreamInput(ctx context.Context, info *callbacks.RunInfo,
+	input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+
+	ctx, rec := r.start(ctx, info)
+	if rec == nil {
+		input.Close()
+		return ctx
+	}
+
+	r.mu.Lock()
+	rec.IsStreamInput = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		chunks, _ := drainTraceStream(input)
+		r.mu.Lock()
+		rec.InputChunks = chunks
+		r.mu.Unlock()
+	}()
+
+	return ctx
+}
+
+// OnEndWithStreamOutput implements callbacks.Handler.
+func (r *TraceRecorder) OnEndWithStreamOutput(ctx context.Context, info *callbacks.RunInfo,
+	output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+
+	rec := r.recordOf(ctx, info)
+	if rec == nil {
+		output.Close()
+		return ctx
+	}
+
+	r.mu.Lock()
+	rec.IsStreamOutput = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		chunks, err := drainTraceStream(output)
+		r.mu.Lock()
+		rec.OutputChunks = chunks
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		rec.EndTime = time.Now()
+		r.mu.Unlock()
+	}()
+
+	return ctx
+}
+
+func drainTraceStream[T any](sr *schema.StreamReader[T]) ([]any, error) {
+	defer sr.Close()
+
+	var chunks []any
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+// WithReplay makes the graph run return the outputs recorded in the trace, instead of executing the selected nodes.
+// Nodes are selected by their paths from the root graph, e.g. NewNodePath("sub_graph", "chat_model").
+// If no path is specified, all chat model nodes are selected.
+// The n-th execution of a selected node returns the output of its n-th recorded execution,
+// and callbacks are triggered with the recorded input and output.
+// Recorded errors are returned as they were, except those followed by a retry of the node.
+// Only works on the root graph, which must be compiled with WithReplaySupport, the selected nodes can be in subgraphs.
+func WithReplay(trace *Trace, nodePaths ...*NodePath) Option {
+	return Option{
+		replay: &replayConfig{trace: trace, nodePaths: nodePaths},
+	}
+}
+
+type replayConfig struct {
+	trace     *Trace
+	nodePaths []*NodePath
+}
+
+type replayKey struct{}
+
+// replayer tracks the records consumed by each node within a run.
+type replayer struct {
+	config *replayConfig
+
+	mu      sync.Mutex
+	records map[string][]*TraceRecord
+}
+
+func withReplayer(ctx context.Context, opts []Option) context.Context {
+	if ctx.Value(replayKey{}) != nil {
+		return ctx
+	}
+
+	var config *replayConfig
+	for _, opt := range opts {
+		if opt.replay != nil {
+			config = opt.replay
+		}
+	}
+	if config == nil || config.trace == nil {
+		return ctx
+	}
+
+	rp := &replayer{config: config, records: map[string][]*TraceRecord{}}
+	for _, rec := range config.trace.Records {
+		key := nodePathKey(rec.NodePath)
+		rp.records[key] = append(rp.records[key], rec)
+	}
+	return context.WithValue(ctx, replayKey{}, rp)
+}
+
+func nodePathKey(path []string) string {
+	return strings.Join(path, "\x1F")
+}
+
+func (rp *replayer) selected(path *NodePath, meta *executorMeta) bool {
+	if len(rp.config.nodePaths) == 0 {
+		return meta != nil && meta.component == components.ComponentOfChatModel
+	}
+	for _, p := range rp.config.nodePaths {
+		if nodePathKey(p.GetPath()) == nodePathKey(path.GetPath()) {
+			return true
+		}
+	}
+	return false
+}
+
+// next pops the record of the next execution of the node, skipping the failed attempts that have been retried.
+func (rp *replayer) next(path *NodePath) (*TraceRecord, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	key := nodePathKey(path.GetPath())
+	records := rp.records[key]
+	for len(records) > 1 && records[0].Error != "" && records[1].Attempt > records[0].Attempt {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no recorded execution left to replay for node %v", path)
+	}
+	rp.records[key] = records[1:]
+	return records[0], nil
+}
+
+func getReplayRecord(ctx context.Context, meta *executorMeta) (*TraceRecord, bool, error) {
+	rp, ok := ctx.Value(replayKey{}).(*replayer)
+	if !ok {
+		return nil, false, nil
+	}
+	path, ok := getNodePath(ctx)
+	if !ok || !rp.selected(path, meta) {
+		return nil, false, nil
+	}
+	rec, err := rp.next(path)
+	return rec, true, err
+}
+
+// replayableComposableRunnable wraps the node's runnable so that it returns the recorded output when the node is replayed.
+// It wraps outside of the retry, so that a replayed execution is never retried.
+func replayableComposableRunnable(r *composableRunnable) *composableRunnable {
+	wrapper := *r
+
+	i := r.i
+	wrapper.i = func(ctx context.Context, input any, opts ...any) (any, error) {
+		rec, ok, err := getReplayRecord(ctx, r.meta)
+		if !ok {
+			return i(ctx, input, opts...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = replayInput(ctx, rec)
+		if rec.Error != "" {
+			return nil, replayError(ctx, rec)
+		}
+
+		if !rec.IsStreamOutput {
+			output, err := r.genericHelper.outputConverter.invoke(outputFromCallbackOutput(rec.Output))
+			if err != nil {
+				return nil, fmt.Errorf("replay node %v fail: %w", rec.NodePath, err)
+			}
+			callbacks.OnEnd(ctx, rec.Output)
+			return output, nil
+		}
+
+		sr, err := replayOutputChunks(rec, r.genericHelper)
+		if err != nil {
+			return nil, err
+		}
+		output, err := r.genericHelper.outputStreamConvertPair.concatStream(sr)
+		if err != nil {
+			return nil, err
+		}
+		_, cbSR := callbacks.OnEndWithStreamOutput(ctx, schema.StreamReaderFromArray(rec.OutputChunks))
+		cbSR.Close()
+		return output, nil
+	}
+
+	t := r.t
+	wrapper.t = func(ctx context.Context, input streamReader, opts ...any) (streamReader, error) {
+		rec, ok, err := getReplayRecord(ctx, r.meta)
+		if !ok {
+			return t(ctx, input, opts...)
+		}
+		input.close()
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = replayInput(ctx, rec)
+		if rec.Error != "" {
+			return nil, replayError(ctx, rec)
+		}
+
+		output, err := replayOutputChunks(rec, r.genericHelper)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := rec.OutputChunks
+		if !rec.IsStreamOutput {
+			outputs = []any{rec.Output}
+		}
+		_, cbSR := callbacks.OnEndWithStreamOutput(ctx, schema.StreamReaderFromArray(outputs))
+		cbSR.Close()
+		return output, nil
+	}
+
+	return &wrapper
+}
+
+func replayInput(ctx context.Context, rec *TraceRecord) context.Context {
+	if rec.IsStreamInput {
+		var sr *schema.StreamReader[any]
+		ctx, sr = callbacks.OnStartWithStreamInput(ctx, schema.StreamReaderFromArray(rec.InputChunks))
+		sr.Close()
+		return ctx
+	}
+	return callbacks.OnStart(ctx, rec.Input)
+}
+
+func replayError(ctx context.Context, rec *TraceRecord) error {
+	err := errors.New(rec.Error)
+	callbacks.OnError(ctx, err)
+	return err
+}
+
+// replayOutputChunks converts the recorded output to a stream of the node's output type.
+func replayOutputChunks(rec *TraceRecord, helper *genericHelper) (streamReader, error) {
+	var chunks []any
+	if rec.IsStreamOutput {
+		chunks = make([]any, 0, len(rec.OutputChunks))
+		for _, chunk := range rec.OutputChunks {
+			chunks = append(chunks, outputFromCallbackOutput(chunk))
+		}
+	} else {
+		chunks = []any{outputFromCallbackOutput(rec.Output)}
+	}
+
+	for _, chunk := range chunks {
+		if _, err := helper.outputConverter.invoke(chunk); err != nil {
+			return nil, fmt.Errorf("replay node %v fail: %w", rec.NodePath, err)
+		}
+	}
+
+	return helper.outputConverter.transform(packStreamReader(schema.StreamReaderFromArray(chunks))), nil
+}
+
+// outputFromCallbackOutput restores the output of the component from the callback payload reported by the component itself.
+func outputFromCallbackOutput(output any) any {
+	switch o := output.(type) {
+	case *model.CallbackOutput:
+		return o.Message
+	case *prompt.CallbackOutput:
+		return o.Result
+	case *retriever.CallbackOutput:
+		return o.Docs
+	case *embedding.CallbackOutput:
+		return o.Embeddings
+	case *indexer.CallbackOutput:
+		return o.IDs
+	case *document.LoaderCallbackOutput:
+		return o.Docs
+	case *document.TransformerCallbackOutput:
+		return o.Output
+	default:
+		return output
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+type traceTestChatModel struct {
+	calls int
+	reply func(calls int) []string
+}
+
+func (m *traceTestChatModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.calls++
+	msgs := make([]*schema.Message, 0)
+	for _, s := range m.reply(m.calls) {
+		msgs = append(msgs, schema.AssistantMessage(s, nil))
+	}
+	return schema.ConcatMessages(msgs)
+}
+
+func (m *traceTestChatModel) Stream(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.calls++
+	msgs := make([]*schema.Message, 0)
+	for _, s := range m.reply(m.calls) {
+		msgs = append(msgs, schema.AssistantMessage(s, nil))
+	}
+	return schema.StreamReaderFromArray(msgs), nil
+}
+
+func newTraceTestGraph(t *testing.T, cm model.BaseChatModel) Runnable[string, *schema.Message] {
+	sub := NewGraph[[]*schema.Message, *schema.Message]()
+	assert.NoError(t, sub.AddChatModelNode("model", cm))
+	assert.NoError(t, sub.AddEdge(START, "model"))
+	assert.NoError(t, sub.AddEdge("model", END))
+
+	g := NewGraph[string, *schema.Message]()
+	assert.NoError(t, g.AddLambdaNode("prompt", InvokableLambda(func(ctx context.Context, input string) ([]*schema.Message, error) {
+		return []*schema.Message{schema.UserMessage(input)}, nil
+	})))
+	assert.NoError(t, g.AddGraphNode("sub", sub))
+	assert.NoError(t, g.AddEdge(START, "prompt"))
+	assert.NoError(t, g.AddEdge("prompt", "sub"))
+	assert.NoError(t, g.AddEdge("sub", END))
+
+	r, err := g.Compile(context.Background(), WithReplaySupport())
+	assert.NoError(t, err)
+	return r
+}
+
+func TestTraceRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invoke", func(t *testing.T) {
+		cm := &traceTestChatModel{reply: func(int) []string { return []string{"recorded"} }}
+		recorder := NewTraceRecorder()
+		out, err := newTraceTestGraph(t, cm).Invoke(ctx, "hi", WithCallbacks(recorder))
+		assert.NoError(t, err)
+		assert.Equal(t, "recorded", out.Content)
+
+		trace := recorder.Trace()
+		assert.Len(t, trace.Records, 3)
+		var paths [][]string
+		var steps []int
+		for _, rec := range trace.Records {
+			paths = append(paths, rec.NodePath)
+			steps = append(steps, rec.Step)
+		}
+		assert.Equal(t, [][]string{{"prompt"}, {"sub"}, {"sub", "model"}}, paths)
+		assert.Equal(t, []int{0, 1, 0}, steps)
+		modelRec := trace.Records[2]
+		assert.Equal(t, components.ComponentOfChatModel, modelRec.Component)
+		assert.Equal(t, []*schema.Message{schema.UserMessage("hi")}, modelRec.Input)
+		assert.Equal(t, "recorded", modelRec.Output.(*schema.Message).Content)
+		assert.False(t, modelRec.EndTime.Before(modelRec.StartTime))
+
+		store := newInMemoryStore()
+		assert.NoError(t, recorder.Save(ctx, store, "trace"))
+		loaded, err := LoadTrace(ctx, store, "trace")
+		assert.NoError(t, err)
+		assert.Equal(t, "recorded", loaded.Records[2].Output.(*schema.Message).Content)
+
+		// the model is replayed from the loaded trace, and is not called
+		replayCM := &traceTestChatModel{reply: func(int) []string { return []string{"live"} }}
+		var cbOutput *schema.Message
+		cb := callbacks.NewHandlerBuilder().OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if info.Component == components.ComponentOfChatModel {
+				cbOutput = model.ConvCallbackOutput(output).Message
+			}
+			return ctx
+		}).Build()
+		out, err = newTraceTestGraph(t, replayCM).Invoke(ctx, "hello", WithReplay(loaded), WithCallbacks(cb))
+		assert.NoError(t, err)
+		assert.Equal(t, "recorded", out.Content)
+		assert.Equal(t, 0, replayCM.calls)
+		assert.Equal(t, "recorded", cbOutput.Content)
+
+		// executions beyond the trace fail
+		rn := newTraceTestGraph(t, replayCM)
+		_, err = rn.Invoke(ctx, "hello", WithReplay(&Trace{}))
+		assert.ErrorContains(t, err, "no recorded execution left to replay")
+
+		// the graph must be compiled with WithReplaySupport
+		g := NewGraph[string, string]()
+		assert.NoError(t, g.AddLambdaNode("1", InvokableLambda(func(ctx context.Context, input string) (string, error) {
+			return input, nil
+		})))
+		assert.NoError(t, g.AddEdge(START, "1"))
+		assert.NoError(t, g.AddEdge("1", END))
+		unsupported, err := g.Compile(ctx)
+		assert.NoError(t, err)
+		_, err = unsupported.Invoke(ctx, "hello", WithReplay(loaded))
+		assert.ErrorContains(t, err, "WithReplaySupport")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		cm := &traceTestChatModel{reply: func(int) []string { return []string{"a", "b", "c"} }}
+		recorder := NewTraceRecorder()
+		sr, err := newTraceTestGraph(t, cm).Stream(ctx, "hi", WithCallbacks(recorder))
+		assert.NoError(t, err)
+		out, err := concatStreamReader(sr)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", out.Content)
+
+		trace := recorder.Trace()
+		modelRec := trace.Records[2]
+		assert.True(t, modelRec.IsStreamOutput)
+		assert.Len(t, modelRec.OutputChunks, 3)
+
+		replayCM := &traceTestChatModel{reply: func(int) []string { return []string{"live"} }}
+		sr, err = newTraceTestGraph(t, replayCM).Stream(ctx, "hi", WithReplay(trace, NewNodePath("sub", "model")))
+		assert.NoError(t, err)
+		var chunks []string
+		for {
+			chunk, rErr := sr.Recv()
+			if rErr != nil {
+				break
+			}
+			chunks = append(chunks, chunk.Content)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, chunks)
+		assert.Equal(t, 0, replayCM.calls)
+
+		// replay a streamed record in invoke mode
+		r := newTraceTestGraph(t, replayCM)
+		msg, err := r.Invoke(ctx, "hi", WithReplay(trace))
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", msg.Content)
+	})
+
+	t.Run("replay error", func(t *testing.T) {
+		errModel := errors.New("model fail")
+		trace := &Trace{Records: []*TraceRecord{{NodePath: []string{"sub", "model"}, Error: errModel.Error()}}}
+		replayCM := &traceTestChatModel{reply: func(int) []string { return []string{"live"} }}
+		_, err := newTraceTestGraph(t, replayCM).Invoke(ctx, "hi", WithReplay(trace))
+		assert.ErrorContains(t, err, "model fail")
+		assert.Equal(t, 0, replayCM.calls)
+	})
+	t.Run("save and load chat template", func(t *testing.T) {
+		g := NewGraph[map[string]any, []*schema.Message]()
+		assert.NoError(t, g.AddChatTemplateNode("template", prompt.FromMessages(schema.FString,
+			schema.SystemMessage("you are {role}"),
+			schema.MessagesPlaceholder("history", true),
+			schema.UserMessage("{query}"),
+		)))
+		assert.NoError(t, g.AddEdge(START, "template"))
+		assert.NoError(t, g.AddEdge("template", END))
+		r, err := g.Compile(ctx, WithReplaySupport())
+		assert.NoError(t, err)
+
+		recorder := NewTraceRecorder()
+		_, err = r.Invoke(ctx, map[string]any{
+			"role":    "helper",
+			"history": []*schema.Message{schema.UserMessage("hi"), schema.AssistantMessage("hello", nil)},
+			"query":   "what is eino?",
+		}, WithCallbacks(recorder))
+		assert.NoError(t, err)
+
+		store := newInMemoryStore()
+		assert.NoError(t, recorder.Save(ctx, store, "trace"))
+		loaded, err := LoadTrace(ctx, store, "trace")
+		assert.NoError(t, err)
+		assert.Len(t, loaded.Records, 1)
+		assert.Equal(t, recorder.Trace().Records[0].Input, loaded.Records[0].Input)
+		assert.Equal(t, recorder.Trace().Records[0].Output, loaded.Records[0].Output)
+
+		// the loaded placeholder still formats the messages of its key
+		in := loaded.Records[0].Input.(*prompt.CallbackInput)
+		msgs, err := in.Templates[1].Format(ctx, in.Variables, schema.FString)
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Message{schema.UserMessage("hi"), schema.AssistantMessage("hello", nil)}, msgs)
+
+		out, err := r.Invoke(ctx, map[string]any{"role": "x", "query": "y"}, WithReplay(loaded, NewNodePath("template")))
+		assert.NoError(t, err)
+		assert.Len(t, out, 4)
+		assert.Equal(t, "what is eino?", out[3].Content)
+	})
+}
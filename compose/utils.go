@@ -199,6 +199,9 @@ func initNodeCallbacks(ctx context.Context, key string, info *nodeInfo, meta *ex
 		}
 	}
 
+	// mark the RunInfo of the node, so that TraceRecorder can tell the node from the components called inside it
+	ctx = context.WithValue(ctx, nodeRunInfoKey{}, ri)
+
 	if len(cbs) == 0 {
 		return icb.ReuseHandlers(ctx, ri)
 	}
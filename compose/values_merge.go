@@ -33,10 +33,16 @@ func RegisterValuesMergeFunc[T any](fn func([]T) (T, error)) {
 type mergeOptions struct {
 	streamMergeWithSourceEOF bool
 	names                    []string
+	reducer                  *FanInReducer
+	streamInterleave         bool
 }
 
 // the caller should ensure len(vs) > 1
 func mergeValues(vs []any, opts *mergeOptions) (any, error) {
+	if opts != nil && opts.reducer != nil {
+		return opts.reducer.merge(vs, opts.streamInterleave)
+	}
+
 	v0 := reflect.ValueOf(vs[0])
 	t0 := v0.Type()
 
@@ -47,6 +47,8 @@ type Workflow[I, O any] struct {
 	workflowNodes    map[string]*WorkflowNode
 	workflowBranches []*WorkflowBranch
 	dependencies     map[string]map[string]dependencyType
+
+	built bool
 }
 
 type dependencyType int
@@ -420,9 +422,29 @@ func (wf *Workflow[I, O]) AddEnd(fromNodeKey string, inputs ...*FieldMapping) *W
 }
 
 func (wf *Workflow[I, O]) compile(ctx context.Context, options *graphCompileOptions) (*composableRunnable, error) {
+	if err := wf.build(); err != nil {
+		return nil, err
+	}
+
+	// TODO: check indirect edges are legal
+
+	return wf.g.compile(ctx, options)
+}
+
+// build adds the branches, inputs and static values declared by the workflow to the underlying graph, only once.
+func (wf *Workflow[I, O]) build() (err error) {
 	if wf.g.buildError != nil {
-		return nil, wf.g.buildError
+		return wf.g.buildError
 	}
+	if wf.built {
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			wf.g.buildError = err
+		}
+	}()
+	wf.built = true
 
 	for _, wb := range wf.workflowBranches {
 		for endNode := range wb.endNodes {
@@ -442,7 +464,7 @@ func (wf *Workflow[I, O]) compile(ctx context.Context, options *graphCompileOpti
 	for _, n := range wf.workflowNodes {
 		for _, addInput := range n.addInputs {
 			if err := addInput(); err != nil {
-				return nil, err
+				return err
 			}
 		}
 		n.addInputs = nil
@@ -458,7 +480,7 @@ func (wf *Workflow[I, O]) compile(ctx context.Context, options *graphCompileOpti
 			}
 
 			if err := n.checkAndAddMappedPath(paths); err != nil {
-				return nil, err
+				return err
 			}
 
 			pair := handlerPair{
@@ -488,9 +510,7 @@ func (wf *Workflow[I, O]) compile(ctx context.Context, options *graphCompileOpti
 		}
 	}
 
-	// TODO: check indirect edges are legal
-
-	return wf.g.compile(ctx, options)
+	return nil
 }
 
 func (wf *Workflow[I, O]) initNode(key string) *WorkflowNode {
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1497,6 +1498,84 @@ func TestCustomExtractor(t *testing.T) {
 	})
 }
 
+func TestFieldMappingTransforms(t *testing.T) {
+	ctx := context.Background()
+
+	type params struct {
+		Limit int `json:"limit"`
+	}
+	type in struct {
+		Count  string
+		Args   string
+		Meta   map[string]any
+		Filter *params
+	}
+	type out struct {
+		Count  int
+		Params *params
+		Lang   string
+		Limit  int
+	}
+
+	t.Run("default, converter and predicate", func(t *testing.T) {
+		wf := NewWorkflow[in, out]()
+		wf.End().AddInput(START,
+			MapFields("Count", "Count", WithConverter(strconv.Atoi), WithPredicate(func(s string) bool { return s != "" })),
+			MapFields("Args", "Params", WithJSONUnmarshal[*params]()),
+			MapFieldPaths(FieldPath{"Meta", "lang"}, FieldPath{"Lang"}, WithDefault("en")),
+			MapFieldPaths(FieldPath{"Filter", "Limit"}, FieldPath{"Limit"}, WithDefault(10)))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+
+		result, err := r.Invoke(ctx, in{Count: "3", Args: `{"limit":5}`, Meta: map[string]any{"lang": "fr"}, Filter: &params{Limit: 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, out{Count: 3, Params: &params{Limit: 5}, Lang: "fr", Limit: 1}, result)
+
+		result, err = r.Invoke(ctx, in{Args: `{}`, Meta: map[string]any{}})
+		assert.NoError(t, err)
+		assert.Equal(t, out{Params: &params{}, Lang: "en", Limit: 10}, result)
+
+		_, err = r.Invoke(ctx, in{Count: "x", Args: `{}`})
+		assert.ErrorContains(t, err, "converter of field mapping")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		wf := NewWorkflow[map[string]any, map[string]any]()
+		wf.End().AddInput(START,
+			MapFields("a", "a", WithConverter(func(i int) (int, error) { return i * 2, nil })),
+			MapFields("b", "b", WithDefault("default")),
+			MapFields("c", "c", WithDefault("default")))
+		r, err := wf.Compile(ctx)
+		assert.NoError(t, err)
+
+		sr, sw := schema.Pipe[map[string]any](2)
+		sw.Send(map[string]any{"a": 1}, nil)
+		sw.Send(map[string]any{"c": "c"}, nil)
+		sw.Close()
+		outputS, err := r.Transform(ctx, sr)
+		assert.NoError(t, err)
+		result, err := concatStreamReader(outputS)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": 2, "b": "default", "c": "c"}, result)
+	})
+
+	t.Run("compile check", func(t *testing.T) {
+		for name, mapping := range map[string]*FieldMapping{
+			"converter source":  MapFields("Count", "Count", WithConverter(func(i int) (int, error) { return i, nil })),
+			"converter target":  MapFields("Count", "Count", WithConverter(func(s string) (string, error) { return s, nil })),
+			"predicate source":  MapFields("Count", "Lang", WithPredicate(func(i int) bool { return true })),
+			"default type":      MapFields("Args", "Lang", WithDefault(1)),
+			"default nil":       MapFields("Args", "Lang", WithDefault(nil)),
+			"missing converter": MapFields("Count", "Count"),
+		} {
+			wf := NewWorkflow[in, out]()
+			wf.End().AddInput(START, mapping)
+			_, err := wf.Compile(ctx)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
 func TestAddDependency(t *testing.T) {
 	ctx := context.Background()
 
@@ -33,6 +33,12 @@ func InitCallbacks(ctx context.Context, info *RunInfo, handlers ...Handler) cont
 	return ctxWithManager(ctx, nil)
 }
 
+// WithoutHandlers removes all the callback handlers from ctx, including the global ones,
+// which are not initialized again by EnsureRunInfo or ReuseHandlers either.
+func WithoutHandlers(ctx context.Context) context.Context {
+	return ctxWithManager(ctx, &manager{})
+}
+
 func EnsureRunInfo(ctx context.Context, typ string, comp components.Component) context.Context {
 	cbm, ok := managerFromCtx(ctx)
 	if !ok {
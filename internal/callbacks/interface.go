@@ -29,6 +29,9 @@ type RunInfo struct {
 	Name      string
 	Type      string
 	Component components.Component
+	// CacheHit is true if the output of the graph node is served from its cache without running it.
+	// Passed from compose.WithCachePolicy().
+	CacheHit bool
 }
 
 type CallbackInput any
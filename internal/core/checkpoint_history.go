@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// The versions and the history of checkpoints are stored under reserved prefixes, which never clash with the IDs of users.
+const (
+	checkPointVersionPrefix = "__eino_checkpoint_version__/"
+	checkPointHistoryPrefix = "__eino_checkpoint_history__/"
+)
+
+// CheckPointVersion describes a historical version of a checkpoint.
+type CheckPointVersion struct {
+	// Version starts from 1 and increases with every version written to the checkpoint ID.
+	Version int
+	// CheckPointID is the ID the version is stored at, which can be used to resume from the version.
+	CheckPointID string
+	// Step is the super step the version is taken before, -1 if unknown, e.g. for an agent interrupt.
+	Step int
+	// NextNodes are the nodes to run when resuming from the version.
+	NextNodes []string `json:",omitempty"`
+	// Interrupted is true if the version is written because the run was interrupted.
+	Interrupted bool `json:",omitempty"`
+	// ForkedFrom is the checkpoint ID the run writing the version was resumed from, if it's a different one.
+	ForkedFrom string `json:",omitempty"`
+	CreatedAt  time.Time
+}
+
+// CheckPointVersionID returns the ID the version of the checkpoint is stored at.
+func CheckPointVersionID(checkPointID string, version int) string {
+	return checkPointVersionPrefix + strconv.Itoa(version) + "/" + checkPointID
+}
+
+// IsCheckPointVersionID reports whether the ID is of a historical version, which is read-only.
+func IsCheckPointVersionID(id string) bool {
+	return strings.HasPrefix(id, checkPointVersionPrefix)
+}
+
+// GetCheckPointHistory returns the versions of the checkpoint, ordered by version.
+func GetCheckPointHistory(ctx context.Context, store CheckPointStore, checkPointID string) ([]*CheckPointVersion, error) {
+	data, existed, err := store.Get(ctx, checkPointHistoryPrefix+checkPointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint history: %w", err)
+	}
+	if !existed {
+		return nil, nil
+	}
+	var history []*CheckPointVersion
+	if err = sonic.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint history: %w", err)
+	}
+	return history, nil
+}
+
+// AppendCheckPointVersion stores the checkpoint as a new version of checkPointID and records it in the history.
+// The Version, CheckPointID and CreatedAt of v are filled in.
+func AppendCheckPointVersion(ctx context.Context, store CheckPointStore, checkPointID string, checkPoint []byte, v *CheckPointVersion) error {
+	history, err := GetCheckPointHistory(ctx, store, checkPointID)
+	if err != nil {
+		return err
+	}
+
+	v.Version = len(history) + 1
+	v.CheckPointID = CheckPointVersionID(checkPointID, v.Version)
+	v.CreatedAt = time.Now()
+	if err = store.Set(ctx, v.CheckPointID, checkPoint); err != nil {
+		return fmt.Errorf("failed to set checkpoint version: %w", err)
+	}
+
+	data, err := sonic.Marshal(append(history, v))
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint history: %w", err)
+	}
+	if err = store.Set(ctx, checkPointHistoryPrefix+checkPointID, data); err != nil {
+		return fmt.Errorf("failed to set checkpoint history: %w", err)
+	}
+	return nil
+}
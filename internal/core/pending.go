@@ -0,0 +1,207 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// InterruptPoint is the persisted user-facing information of an interrupt point, which isn't needed to resume,
+// but to inspect the interrupt points of a checkpoint.
+type InterruptPoint struct {
+	// Info is nil if it can't be serialized.
+	Info        any
+	IsRootCause bool
+	ParentID    string
+}
+
+// PendingInterrupts describes what an interrupted run stored in a checkpoint is waiting on.
+type PendingInterrupts struct {
+	CheckPointID string
+	// InterruptContexts are the root causes of the interruption, with their Parent chains.
+	// Info of a context is nil if it couldn't be serialized into the checkpoint.
+	InterruptContexts []*InterruptCtx
+	// States are the saved states of the interrupt points, by interrupt ID.
+	States map[string]any
+	// ResumeData are the resume data stored by an edit, by interrupt ID.
+	ResumeData map[string]any
+}
+
+// InterruptEdit describes the changes to the pending interrupts of a checkpoint.
+type InterruptEdit struct {
+	// States replace the saved states of the interrupt points, by interrupt ID.
+	States map[string]any
+	// ResumeData are stored as the resume targets and their data, by interrupt ID,
+	// which take effect on the next resume as if they were passed along with it.
+	// The resume data passed along with the resume take precedence over them.
+	ResumeData map[string]any
+}
+
+// SignalToInterruptPoints flattens the user-facing information of an InterruptSignal tree,
+// infoFilter returns whether the info can be persisted.
+func SignalToInterruptPoints(is *InterruptSignal, infoFilter func(info any) bool) map[string]*InterruptPoint {
+	points := make(map[string]*InterruptPoint)
+	if is == nil {
+		return points
+	}
+
+	var traverse func(signal *InterruptSignal, parentID string)
+	traverse = func(signal *InterruptSignal, parentID string) {
+		p := &InterruptPoint{IsRootCause: signal.IsRootCause, ParentID: parentID}
+		if signal.Info != nil && (infoFilter == nil || infoFilter(signal.Info)) {
+			p.Info = signal.Info
+		}
+		points[signal.ID] = p
+		for _, sub := range signal.Subs {
+			traverse(sub, signal.ID)
+		}
+	}
+	traverse(is, "")
+	return points
+}
+
+// ToPendingInterrupts rebuilds the pending interrupts from the persisted maps of a checkpoint.
+// For checkpoints written without interrupt points, the parents are inferred from the addresses.
+func ToPendingInterrupts(checkPointID string, id2Addr map[string]Address, id2State map[string]InterruptState,
+	points map[string]*InterruptPoint, resumeData map[string]any, allowedSegmentTypes []AddressSegmentType) *PendingInterrupts {
+	ret := &PendingInterrupts{
+		CheckPointID: checkPointID,
+		States:       make(map[string]any),
+		ResumeData:   make(map[string]any),
+	}
+	for id, s := range id2State {
+		if s.State != nil {
+			ret.States[id] = s.State
+		}
+	}
+	for id, data := range resumeData {
+		ret.ResumeData[id] = data
+	}
+	if len(id2Addr) == 0 {
+		return ret
+	}
+
+	ids := make([]string, 0, len(id2Addr))
+	for id := range id2Addr {
+		ids = append(ids, id)
+	}
+	// parents before children, and stable for the same checkpoint
+	sort.Slice(ids, func(i, j int) bool {
+		if len(id2Addr[ids[i]]) != len(id2Addr[ids[j]]) {
+			return len(id2Addr[ids[i]]) < len(id2Addr[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+
+	signals := make(map[string]*InterruptSignal, len(ids))
+	for _, id := range ids {
+		signals[id] = &InterruptSignal{ID: id, Address: id2Addr[id]}
+	}
+	var roots []*InterruptSignal
+	for _, id := range ids {
+		s := signals[id]
+		var parent *InterruptSignal
+		if p, ok := points[id]; ok {
+			s.Info, s.IsRootCause = p.Info, p.IsRootCause
+			parent = signals[p.ParentID]
+		} else {
+			parent = inferParentSignal(s, ids, signals)
+		}
+		if parent == nil {
+			roots = append(roots, s)
+		} else {
+			parent.Subs = append(parent.Subs, s)
+		}
+	}
+	if len(points) == 0 {
+		for _, s := range signals {
+			s.IsRootCause = len(s.Subs) == 0
+		}
+	}
+
+	for _, root := range roots {
+		ret.InterruptContexts = append(ret.InterruptContexts, ToInterruptContexts(root, allowedSegmentTypes)...)
+	}
+	return ret
+}
+
+// inferParentSignal returns the signal whose address is the longest strict prefix of the address of s.
+func inferParentSignal(s *InterruptSignal, ids []string, signals map[string]*InterruptSignal) *InterruptSignal {
+	var parent *InterruptSignal
+	for _, id := range ids {
+		c := signals[id]
+		if len(c.Address) >= len(s.Address) {
+			break
+		}
+		if c.Address.Equals(s.Address[:len(c.Address)]) {
+			parent = c
+		}
+	}
+	return parent
+}
+
+// ApplyInterruptEdit applies the edit to the persisted maps of a checkpoint, and returns the new resume data.
+func ApplyInterruptEdit(edit *InterruptEdit, id2Addr map[string]Address, id2State map[string]InterruptState,
+	resumeData map[string]any) (map[string]any, error) {
+	if edit == nil {
+		return resumeData, nil
+	}
+	for id, state := range edit.States {
+		if _, ok := id2Addr[id]; !ok {
+			return nil, fmt.Errorf("interrupt[%s] not found", id)
+		}
+		s := id2State[id]
+		s.State = state
+		id2State[id] = s
+	}
+	for id, data := range edit.ResumeData {
+		if _, ok := id2Addr[id]; !ok {
+			return nil, fmt.Errorf("interrupt[%s] not found", id)
+		}
+		if resumeData == nil {
+			resumeData = make(map[string]any)
+		}
+		resumeData[id] = data
+	}
+	return resumeData, nil
+}
+
+// PopulateResumeData adds the resume data of the interrupt IDs that have no resume data in ctx.
+// It should be called before PopulateInterruptState.
+func PopulateResumeData(ctx context.Context, resumeData map[string]any) context.Context {
+	if len(resumeData) == 0 {
+		return ctx
+	}
+	rInfo, ok := getResumeInfo(ctx)
+	if !ok {
+		return BatchResumeWithData(ctx, resumeData)
+	}
+
+	rInfo.mu.Lock()
+	defer rInfo.mu.Unlock()
+	if rInfo.id2ResumeData == nil {
+		rInfo.id2ResumeData = make(map[string]any)
+	}
+	for id, data := range resumeData {
+		if _, ok := rInfo.id2ResumeData[id]; !ok {
+			rInfo.id2ResumeData[id] = data
+		}
+	}
+	return ctx
+}
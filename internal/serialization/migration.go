@@ -0,0 +1,185 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serialization
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type migration struct {
+	key     string
+	to      reflect.Type
+	upgrade func(any) (any, error)
+}
+
+// migrations are keyed by the type of the old version
+var migrations = map[reflect.Type]*migration{}
+var migrationKeys = map[string]bool{}
+
+// VersionedName returns the registered name of the version of a type, version 1 is the name itself.
+func VersionedName(name string, version int) string {
+	if version <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s@v%d", name, version)
+}
+
+// RegisterMigration registers the upgrade from the type from, which is the version fromVersion of the type name, to the type to.
+func RegisterMigration(name string, fromVersion int, from, to reflect.Type, upgrade func(any) (any, error)) error {
+	key := VersionedName(name, fromVersion)
+	if migrationKeys[key] {
+		return fmt.Errorf("migration of [%s] already registered", key)
+	}
+	if _, ok := migrations[from]; ok {
+		return fmt.Errorf("migration from type[%s] already registered", from)
+	}
+	migrationKeys[key] = true
+	migrations[from] = &migration{key: key, to: to, upgrade: upgrade}
+	return nil
+}
+
+// Migrate upgrades the values of old versions held by interfaces inside v, which should be a pointer,
+// until they are of a type which isn't migrated from.
+func Migrate(v any) error {
+	if len(migrations) == 0 || v == nil {
+		return nil
+	}
+	_, _, err := migrateValue(reflect.ValueOf(v), map[uintptr]bool{})
+	return err
+}
+
+// migrateValue migrates the values inside rv, and returns the new value of rv if it isn't updated in place.
+func migrateValue(rv reflect.Value, visited map[uintptr]bool) (reflect.Value, bool, error) {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() || visited[rv.Pointer()] {
+			return rv, false, nil
+		}
+		visited[rv.Pointer()] = true
+		elem := rv.Elem()
+		nv, changed, err := migrateValue(elem, visited)
+		if err != nil {
+			return rv, false, err
+		}
+		if changed {
+			elem.Set(nv)
+		}
+		return rv, false, nil
+	case reflect.Interface:
+		if rv.IsNil() {
+			return rv, false, nil
+		}
+		elem, changed, err := migrateValue(rv.Elem(), visited)
+		if err != nil {
+			return rv, false, err
+		}
+		for {
+			m, ok := migrations[elem.Type()]
+			if !ok {
+				break
+			}
+			upgraded, err := m.upgrade(elem.Interface())
+			if err != nil {
+				return rv, false, fmt.Errorf("migrate [%s] fail: %w", m.key, err)
+			}
+			elem = reflect.ValueOf(upgraded)
+			if !elem.IsValid() {
+				elem = reflect.Zero(m.to)
+			}
+			changed = true
+		}
+		if !changed {
+			return rv, false, nil
+		}
+		if !elem.Type().AssignableTo(rv.Type()) {
+			return rv, false, fmt.Errorf("migrated type[%s] is not assignable to %s", elem.Type(), rv.Type())
+		}
+		ret := reflect.New(rv.Type()).Elem()
+		ret.Set(elem)
+		return ret, true, nil
+	case reflect.Struct:
+		target := rv
+		copied := false
+		if !rv.CanAddr() {
+			target = reflect.New(rv.Type()).Elem()
+			target.Set(rv)
+			copied = true
+		}
+		changed := false
+		for i := 0; i < target.NumField(); i++ {
+			f := target.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			nv, fChanged, err := migrateValue(f, visited)
+			if err != nil {
+				return rv, false, err
+			}
+			if fChanged {
+				f.Set(nv)
+				changed = true
+			}
+		}
+		return target, copied && changed, nil
+	case reflect.Map:
+		if rv.IsNil() || visited[rv.Pointer()] || isBasicKind(rv.Type().Elem().Kind()) {
+			return rv, false, nil
+		}
+		visited[rv.Pointer()] = true
+		for _, k := range rv.MapKeys() {
+			nv, changed, err := migrateValue(rv.MapIndex(k), visited)
+			if err != nil {
+				return rv, false, err
+			}
+			if changed {
+				rv.SetMapIndex(k, nv)
+			}
+		}
+		return rv, false, nil
+	case reflect.Slice, reflect.Array:
+		if isBasicKind(rv.Type().Elem().Kind()) {
+			return rv, false, nil
+		}
+		target := rv
+		copied := false
+		if rv.Kind() == reflect.Array && !rv.CanAddr() {
+			target = reflect.New(rv.Type()).Elem()
+			target.Set(rv)
+			copied = true
+		}
+		changed := false
+		for i := 0; i < target.Len(); i++ {
+			e := target.Index(i)
+			nv, eChanged, err := migrateValue(e, visited)
+			if err != nil {
+				return rv, false, err
+			}
+			if eChanged {
+				e.Set(nv)
+				changed = true
+			}
+		}
+		return target, copied && changed, nil
+	default:
+		return rv, false, nil
+	}
+}
+
+func isBasicKind(k reflect.Kind) bool {
+	return k >= reflect.Bool && k <= reflect.Complex128 || k == reflect.String
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serialization
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type migrationV1 struct {
+	Count string
+}
+
+type migrationV2 struct {
+	Count int
+}
+
+type migrationV3 struct {
+	Count int
+	Note  string
+}
+
+type migrationBroken struct{}
+
+type migrationHolder struct {
+	State  any
+	Values map[string]any
+	List   []any
+	Nested map[string]migrationNested
+	Self   *migrationHolder
+	hidden any
+}
+
+type migrationNested struct {
+	Inner any
+}
+
+func init() {
+	_ = RegisterMigration("migration_test", 1, reflect.TypeOf(&migrationV1{}), reflect.TypeOf(&migrationV2{}),
+		func(v any) (any, error) {
+			n, err := strconv.Atoi(v.(*migrationV1).Count)
+			return &migrationV2{Count: n}, err
+		})
+	_ = RegisterMigration("migration_test", 2, reflect.TypeOf(&migrationV2{}), reflect.TypeOf(migrationV3{}),
+		func(v any) (any, error) {
+			return migrationV3{Count: v.(*migrationV2).Count, Note: "migrated"}, nil
+		})
+	_ = RegisterMigration("migration_broken", 1, reflect.TypeOf(migrationBroken{}), reflect.TypeOf(migrationBroken{}),
+		func(v any) (any, error) {
+			return nil, errors.New("broken")
+		})
+}
+
+func TestMigrate(t *testing.T) {
+	expected := migrationV3{Count: 1, Note: "migrated"}
+
+	h := &migrationHolder{
+		State:  &migrationV1{Count: "1"},
+		Values: map[string]any{"a": &migrationV1{Count: "1"}, "b": "unchanged"},
+		List:   []any{&migrationV2{Count: 1}},
+		Nested: map[string]migrationNested{"n": {Inner: &migrationV1{Count: "1"}}},
+		hidden: &migrationV1{Count: "1"},
+	}
+	h.Self = h
+	assert.NoError(t, Migrate(h))
+	assert.Equal(t, expected, h.State)
+	assert.Equal(t, expected, h.Values["a"])
+	assert.Equal(t, "unchanged", h.Values["b"])
+	assert.Equal(t, expected, h.List[0])
+	assert.Equal(t, expected, h.Nested["n"].Inner)
+	assert.Equal(t, &migrationV1{Count: "1"}, h.hidden)
+
+	err := Migrate(&migrationHolder{State: &migrationV1{Count: "x"}})
+	assert.ErrorContains(t, err, "migrate [migration_test] fail")
+	err = Migrate(&migrationHolder{State: migrationBroken{}})
+	assert.ErrorContains(t, err, "broken")
+
+	assert.Error(t, RegisterMigration("migration_test", 1, reflect.TypeOf(0), reflect.TypeOf(0), nil))
+	assert.Equal(t, "migration_test@v2", VersionedName("migration_test", 2))
+}
@@ -75,6 +75,18 @@ func (i *InternalSerializer) Marshal(v any) ([]byte, error) {
 	return sonic.Marshal(is)
 }
 
+// MarshalCanonical is the same as Marshal, except that the keys of maps are sorted,
+// so that equal values are always encoded to the same bytes, e.g. to derive cache keys from them.
+func (i *InternalSerializer) MarshalCanonical(v any) ([]byte, error) {
+	is, err := internalMarshal(v, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// encoding/json sorts the keys of maps, which sonic doesn't by default
+	return json.Marshal(is)
+}
+
 func (i *InternalSerializer) Unmarshal(data []byte, v any) error {
 	val, err := unmarshal(data, reflect.TypeOf(v))
 	if err != nil {
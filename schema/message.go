@@ -18,6 +18,7 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -699,6 +700,25 @@ type messagesPlaceholder struct {
 	optional bool
 }
 
+type messagesPlaceholderJSON struct {
+	Key      string `json:"key"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// MarshalJSON makes the placeholder serializable, e.g. in the callback input of a chat template.
+func (p messagesPlaceholder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(messagesPlaceholderJSON{Key: p.key, Optional: p.optional})
+}
+
+func (p *messagesPlaceholder) UnmarshalJSON(data []byte) error {
+	var v messagesPlaceholderJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.key, p.optional = v.Key, v.Optional
+	return nil
+}
+
 // MessagesPlaceholder can render a placeholder to a list of messages in params.
 // e.g.
 //
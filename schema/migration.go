@@ -0,0 +1,67 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/eino/internal/generic"
+	"github.com/cloudwego/eino/internal/serialization"
+)
+
+// RegisterNameWithVersion registers a type with a specific name and version for serialization.
+// Version 1 is the same as RegisterName(name), so a type registered by RegisterName is version 1 of the name.
+// When a registered type changes in a way old checkpoints can't be decoded into,
+// e.g. a field changes its type, register the new type with the next version,
+// and keep the old type to register a migration from it by RegisterMigration.
+//
+// This function panics if registration fails.
+func RegisterNameWithVersion[T any](name string, version int) {
+	RegisterName[T](serialization.VersionedName(name, version))
+}
+
+// RegisterMigration registers From as the version fromVersion of the type name, along with the function upgrading it to To.
+// When a graph or ADK checkpoint is loaded, values of From held by interfaces, e.g. the state of GenLocalState,
+// an interrupt state or a value of map[string]any, are upgraded before the checkpoint is restored.
+// Upgrades are chained, e.g. a value of version 1 is upgraded to version 2, and then to version 3 if there's a migration from version 2.
+//
+// From is the type as it's held by the interface, e.g. a pointer for the state of GenLocalState, and To should be assignable to the interface.
+// From is registered by this function, so it shouldn't be registered by RegisterName.
+// A registered name can also be renamed by a migration from the old name, with From being a distinct type, e.g.
+//
+//	type legacyState MyState
+//
+//	schema.RegisterName[*MyState]("my_state")
+//	schema.RegisterMigration[*legacyState, *MyState]("my_old_state", 1, func(s *legacyState) (*MyState, error) {
+//		return (*MyState)(s), nil
+//	})
+//
+// It is recommended to call this in an `init()` function, this function panics if registration fails.
+func RegisterMigration[From, To any](name string, fromVersion int, upgrade func(From) (To, error)) {
+	if upgrade == nil {
+		panic(fmt.Sprintf("migration of [%s] has no upgrade function", serialization.VersionedName(name, fromVersion)))
+	}
+	RegisterName[From](serialization.VersionedName(name, fromVersion))
+
+	err := serialization.RegisterMigration(name, fromVersion, generic.TypeOf[From](), generic.TypeOf[To](),
+		func(v any) (any, error) {
+			return upgrade(v.(From))
+		})
+	if err != nil {
+		panic(err)
+	}
+}
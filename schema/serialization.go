@@ -52,6 +52,7 @@ func init() {
 	RegisterName[MessagePartCommon]("_eino_message_part_common")
 	RegisterName[ImageURLDetail]("_eino_image_url_detail")
 	RegisterName[PromptTokenDetails]("_eino_prompt_token_details")
+	RegisterName[messagesPlaceholder]("_eino_messages_placeholder")
 }
 
 // RegisterName registers a type with a specific name for serialization. This is